@@ -31,11 +31,12 @@ func main() {
 	}
 	defer gcsClient.Close()
 
-	esClient := esclient.NewClient("http://localhost:9200")
+	esClient := lib.NewESClient(vp, esclient.WithRetry(5))
+	defer esClient.Close()
 
 	// usecase
 	ingestionUseCase := usecase.NewIngestionUseCase(vp, logger, gcsClient, getItemIngestionTopic(pbClient))
-	itemUseCase := usecase.NewItemUpsertUseCase(logger, esClient)
+	itemUseCase := usecase.NewItemUpsertUseCase(logger, esClient, lib.NewPubSubDeadLetterSink(getItemDeadLetterTopic(pbClient)))
 
 	gcsNotifConsumer := messaging.NewGCSNotifConsumer(logger, ingestionUseCase)
 	gcsNotifSubscriber := pbClient.Subscription("bucket-notification")
@@ -60,3 +61,7 @@ func main() {
 func getItemIngestionTopic(client *pubsub.Client) *pubsub.Topic {
 	return client.Topic("item-and-offer")
 }
+
+func getItemDeadLetterTopic(client *pubsub.Client) *pubsub.Topic {
+	return client.Topic("items-dead-letter")
+}
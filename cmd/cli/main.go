@@ -5,6 +5,8 @@ import (
 	"flag"
 	"fmt"
 	config "github/shaolim/kakashi/config"
+	"github/shaolim/kakashi/internal/feed"
+	"github/shaolim/kakashi/internal/lib"
 	"github/shaolim/kakashi/internal/usecase"
 	"github/shaolim/kakashi/pkg/esclient"
 	"os"
@@ -22,6 +24,7 @@ const (
 	Indexing        Command = "indexing"
 	MatchDocs       Command = "match-docs"
 	UploadfileToGCS Command = "upload-file-to-gcs"
+	GetDoc          Command = "get-doc"
 )
 
 func main() {
@@ -33,10 +36,13 @@ func main() {
 	os.Setenv(`PUBSUB_EMULATOR_HOST`, viper.GetString(`PUBSUB_EMULATOR_HOST`))
 	os.Setenv("GCP_PROJECT_ID", viper.GetString("GCP_PROJECT_ID"))
 
-	command := flag.String("command", "", "Command eg. create-index, indexing, match-docs, upload-file-to-gcs")
+	command := flag.String("command", "", "Command eg. create-index, indexing, match-docs, upload-file-to-gcs, get-doc")
 	filename := flag.String("file", "", "path of csv file")
 	languageCode := flag.String("lang", "ja", "Language code")
 	bucketName := flag.String("bucket", "test-bucket", "Bucket name")
+	id := flag.String("id", "", "Document id (sku)")
+	deadLetter := flag.String("dead-letter", "dead-letter.jsonl", "path of the jsonl file receiving the items that failed to be indexed")
+	rejected := flag.String("rejected", "rejected.jsonl", "path of the jsonl or csv file receiving the rows that failed validation")
 
 	flag.Parse()
 
@@ -55,7 +61,7 @@ func main() {
 			fmt.Println("filename is required to run this indexing command")
 			return
 		}
-		if err := indexing(*languageCode, *filename); err != nil {
+		if err := indexing(*languageCode, *filename, *deadLetter, *rejected); err != nil {
 			fmt.Println(err)
 		}
 	case MatchDocs:
@@ -76,32 +82,60 @@ func main() {
 		if err := uploadFileToGCS(*bucketName, *filename); err != nil {
 			fmt.Println(err)
 		}
+	case GetDoc:
+		if *id == "" {
+			fmt.Println("id is required to run this get-doc command")
+			return
+		}
+
+		if err := getDoc(*languageCode, *id); err != nil {
+			fmt.Println(err)
+		}
 	default:
 		fmt.Printf("unknown command: %s, valid commands: create-index, indexing, match-docs\n", *command)
 	}
 }
 
 func createIndex() error {
-	client := esclient.NewClient("http://localhost:9200")
+	client := lib.NewESClient(viper.GetViper())
+	defer client.Close()
 
 	createIndexUC := usecase.NewCreateIndexUseCase(client)
-	if err := createIndexUC.Execute(); err != nil {
+	if err := createIndexUC.Execute(context.Background()); err != nil {
 		return fmt.Errorf("failed to create index, error: %v", err)
 	}
 
 	return nil
 }
 
-func indexing(languageCode string, filename string) error {
-	client := esclient.NewClient("http://localhost:9200")
+func indexing(languageCode string, filename string, deadLetterFilename string, rejectedFilename string) error {
+	client := lib.NewESClient(viper.GetViper(), esclient.WithRetry(5))
+	defer client.Close()
+
+	deadLetter, err := os.OpenFile(deadLetterFilename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead letter file, error: %v", err)
+	}
+	defer deadLetter.Close()
+
+	rejectedFile, err := os.OpenFile(rejectedFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open rejected rows file, error: %v", err)
+	}
+	defer rejectedFile.Close()
+
+	var rejected feed.RejectedWriter = feed.NewJSONLRejectedWriter(rejectedFile)
+	if filepath.Ext(rejectedFilename) == ".csv" {
+		rejected = feed.NewCSVRejectedWriter(rejectedFile)
+	}
 
 	index := config.ItemIndexJa
 	if languageCode == "en" {
 		index = config.ItemIndexEn
 	}
 
-	indexingUC := usecase.NewDocsInsertUseCase(client)
-	if err := indexingUC.Execute(index, filename); err != nil {
+	indexingUC := usecase.NewDocsInsertUseCase(client, esclient.NewJSONLDeadLetterSink(deadLetter), rejected)
+	if err := indexingUC.Execute(context.Background(), index, filename); err != nil {
 		fmt.Printf("failed to indexing, error: %v\n", err)
 		return err
 	}
@@ -109,14 +143,15 @@ func indexing(languageCode string, filename string) error {
 }
 
 func matchDocs(filename string, languageCode string) error {
-	client := esclient.NewClient("http://localhost:9200")
+	client := lib.NewESClient(viper.GetViper())
+	defer client.Close()
 	index := config.ItemIndexJa
 	if languageCode == "en" {
 		index = config.ItemIndexEn
 	}
 
 	matchDocs := usecase.NewSampleDocsUseCase(client)
-	if err := matchDocs.Execute(index, filename); err != nil {
+	if err := matchDocs.Execute(context.Background(), index, filename); err != nil {
 		fmt.Printf("failed to match docs, error: %v\n", err)
 		return err
 	}
@@ -124,7 +159,8 @@ func matchDocs(filename string, languageCode string) error {
 }
 
 func uploadFileToGCS(bucketName, filename string) error {
-	client := esclient.NewClient("http://localhost:9200")
+	client := lib.NewESClient(viper.GetViper())
+	defer client.Close()
 	gcsClient, err := storage.NewClient(context.Background())
 	if err != nil {
 		return err
@@ -144,3 +180,27 @@ func uploadFileToGCS(bucketName, filename string) error {
 	}
 	return nil
 }
+
+func getDoc(languageCode string, id string) error {
+	client := lib.NewESClient(viper.GetViper())
+	defer client.Close()
+
+	index := config.ItemIndexJa
+	if languageCode == "en" {
+		index = config.ItemIndexEn
+	}
+
+	res, err := client.GetDocument(context.Background(), index, id)
+	if err != nil {
+		if esclient.IsNotFound(err) {
+			fmt.Printf("document %s not found in %s\n", id, index)
+			return nil
+		}
+		return err
+	}
+
+	fmt.Printf("index: %s, id: %s, version: %d, seq_no: %d, primary_term: %d\n",
+		res.Result.Index, res.Result.Id, res.Result.Version, res.Result.SeqNo, res.Result.PrimaryTerm)
+	fmt.Println(string(res.Result.Source))
+	return nil
+}
@@ -26,7 +26,7 @@ func NewGCSNotifConsumer(logger *slog.Logger, ingestionUsecase *usecase.Ingestio
 func (c *GCSNotifConsumer) Consume(ctx context.Context, msg *pubsub.Message) {
 	var attr model.GscAttribute
 	if err := json.Unmarshal(msg.Data, &attr); err != nil {
-		c.logger.Error("failed to unmarshal, err: %v\n", err)
+		c.logger.Error("failed to unmarshal", slog.Any("error", err))
 		// TODO: add to metrics
 		msg.Ack()
 		return
@@ -0,0 +1,170 @@
+package feed
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github/shaolim/kakashi/internal/model"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ItemReader reads the items of a csv feed one row at a time. A row that
+// cannot be decoded or does not pass model.Item.Validate is returned as a
+// RejectedRow, and the reader moves on to the next row.
+type ItemReader struct {
+	csv      *csv.Reader
+	header   []string
+	columns  []int // the index of the Item field of each column, -1 when unknown
+	idColumn int
+}
+
+var itemType = reflect.TypeOf(model.Item{})
+
+// NewItemReader reads the header of the feed. Columns are matched with the
+// csv tags of model.Item, unknown columns are ignored.
+func NewItemReader(r io.Reader) (*ItemReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the csv header: %w", err)
+	}
+	header = append([]string(nil), header...)
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	fields := make(map[string]int, itemType.NumField())
+	for i := 0; i < itemType.NumField(); i++ {
+		fields[itemType.Field(i).Tag.Get("csv")] = i
+	}
+	columns := make([]int, len(header))
+	for i, name := range header {
+		columns[i] = -1
+		if field, ok := fields[name]; ok {
+			columns[i] = field
+		}
+	}
+
+	return &ItemReader{csv: reader, header: header, columns: columns, idColumn: slices.Index(header, "ID")}, nil
+}
+
+// Header returns the columns of the feed.
+func (r *ItemReader) Header() []string {
+	return r.header
+}
+
+// Read returns the next item, or the next row when it is rejected. It
+// returns io.EOF at the end of the feed.
+func (r *ItemReader) Read() (*model.Item, *RejectedRow, error) {
+	record, err := r.csv.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &RejectedRow{Line: parseErr.StartLine, Reasons: []string{parseErr.Err.Error()}, header: r.header}, nil
+		}
+		return nil, nil, err
+	}
+	line, _ := r.csv.FieldPos(0)
+
+	rejected := func(reasons ...string) *RejectedRow {
+		row := &RejectedRow{Line: line, Reasons: reasons, Record: record, header: r.header}
+		if r.idColumn >= 0 && r.idColumn < len(record) {
+			row.Id = record[r.idColumn]
+		}
+		return row
+	}
+
+	if len(record) != len(r.header) {
+		return nil, rejected(fmt.Sprintf("has %d columns, the header has %d", len(record), len(r.header))), nil
+	}
+
+	item := &model.Item{}
+	v := reflect.ValueOf(item).Elem()
+	var reasons []string
+	for i, value := range record {
+		if r.columns[i] < 0 {
+			continue
+		}
+		if err := setField(v.Field(r.columns[i]), value); err != nil {
+			reasons = append(reasons, r.header[i]+": "+err.Error())
+		}
+	}
+	if len(reasons) > 0 {
+		return nil, rejected(reasons...), nil
+	}
+
+	if err := item.Validate(); err != nil {
+		var errs model.ValidationErrors
+		if errors.As(err, &errs) {
+			for _, fieldErr := range errs {
+				reasons = append(reasons, fieldErr.Error())
+			}
+		} else {
+			reasons = append(reasons, err.Error())
+		}
+		return nil, rejected(reasons...), nil
+	}
+
+	return item, nil, nil
+}
+
+func setField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Float64:
+		if value == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// ReadAll sends the items of the feed to out and writes the rejected rows to
+// rejected, which may be nil, then closes out. It returns the number of
+// rejected rows, and stops at the first error reading the feed or writing a
+// rejected row. rejected is flushed whatever happens, so that the rows
+// rejected before an error are still reported.
+func (r *ItemReader) ReadAll(out chan<- *model.Item, rejected RejectedWriter) (count int, err error) {
+	defer close(out)
+	if rejected != nil {
+		defer func() {
+			if flushErr := rejected.Flush(); flushErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to flush the rejected rows: %w", flushErr))
+			}
+		}()
+	}
+
+	for {
+		item, row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		if row != nil {
+			count++
+			if rejected != nil {
+				if err := rejected.Write(row); err != nil {
+					return count, fmt.Errorf("failed to write rejected row %d: %w", row.Line, err)
+				}
+			}
+			continue
+		}
+		out <- item
+	}
+}
@@ -0,0 +1,123 @@
+package feed_test
+
+import (
+	"bytes"
+	"errors"
+	"github/shaolim/kakashi/internal/feed"
+	"github/shaolim/kakashi/internal/model"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testFeed = `"Language Code","ID","Title","Link","Price","Currency","Availability date","Condition","Age group","Gender","Ratings","IsTargetForDelete"
+"en","101","Classic T-Shirt","https://example.com/101","19.99","USD","2024-12-01","new","adult","unisex","4.5","0"
+"en","","Running Shoes","https://example.com/102","79.99","USD","","","","","",""
+"en","103","Jacket","example.com/103","1,299.00","USD","tomorrow","broken","","","",""
+"en","104","Cap","https://example.com/104","9.99","USD","","","","","five",""
+"en","105","Socks"
+"xx","106","","","","","","","","","","1"
+"ja","107","シャツ","https://example.com/107","1980","JPY","2024-12-01T10:00:00+09:00","Used","Kids","Male","","0"
+`
+
+func TestItemReader(t *testing.T) {
+	reader, err := feed.NewItemReader(strings.NewReader(testFeed))
+	assert.NoError(t, err)
+
+	out := make(chan *model.Item, 10)
+	var rejected bytes.Buffer
+	count, err := reader.ReadAll(out, feed.NewJSONLRejectedWriter(&rejected))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count)
+
+	var ids []string
+	for item := range out {
+		ids = append(ids, item.Id)
+	}
+	assert.Equal(t, []string{"101", "107"}, ids)
+
+	expected := []string{
+		`{"line":3,"reasons":["ID: is required"],"row":{"Age group":"","Availability date":"","Condition":"","Currency":"USD","Gender":"","ID":"","IsTargetForDelete":"","Language Code":"en","Link":"https://example.com/102","Price":"79.99","Ratings":"","Title":"Running Shoes"}}`,
+		`{"line":4,"id":"103","reasons":["Link: is not an http url","Availability date: is not an ISO 8601 date","Condition: must be one of new, refurbished, used"],"row":{"Age group":"","Availability date":"tomorrow","Condition":"broken","Currency":"USD","Gender":"","ID":"103","IsTargetForDelete":"","Language Code":"en","Link":"example.com/103","Price":"1,299.00","Ratings":"","Title":"Jacket"}}`,
+		`{"line":5,"id":"104","reasons":["Ratings: \"five\" is not a number"],"row":{"Age group":"","Availability date":"","Condition":"","Currency":"USD","Gender":"","ID":"104","IsTargetForDelete":"","Language Code":"en","Link":"https://example.com/104","Price":"9.99","Ratings":"five","Title":"Cap"}}`,
+		`{"line":6,"id":"105","reasons":["has 3 columns, the header has 12"],"row":{"ID":"105","Language Code":"en","Title":"Socks"}}`,
+		`{"line":7,"id":"106","reasons":["Language Code: is not an ISO 639-1 language code"],"row":{"Age group":"","Availability date":"","Condition":"","Currency":"","Gender":"","ID":"106","IsTargetForDelete":"1","Language Code":"xx","Link":"","Price":"","Ratings":"","Title":""}}`,
+	}
+	assert.Equal(t, strings.Join(expected, "\n")+"\n", rejected.String())
+}
+
+func TestCSVRejectedWriter(t *testing.T) {
+	reader, err := feed.NewItemReader(strings.NewReader(testFeed))
+	assert.NoError(t, err)
+
+	out := make(chan *model.Item, 10)
+	var rejected bytes.Buffer
+	_, err = reader.ReadAll(out, feed.NewCSVRejectedWriter(&rejected))
+	assert.NoError(t, err)
+
+	lines := strings.Split(rejected.String(), "\n")
+	assert.Equal(t, `line,reasons,Language Code,ID,Title,Link,Price,Currency,Availability date,Condition,Age group,Gender,Ratings,IsTargetForDelete`, lines[0])
+	assert.Equal(t, `3,ID: is required,en,,Running Shoes,https://example.com/102,79.99,USD,,,,,,`, lines[1])
+	assert.Equal(t, `6,"has 3 columns, the header has 12",en,105,Socks`, lines[4])
+}
+
+// recordingRejectedWriter counts the rejected rows, fails to write the rows
+// past failAfter when it is set, and always fails to flush.
+type recordingRejectedWriter struct {
+	failAfter int
+	rows      int
+	flushed   bool
+}
+
+func (w *recordingRejectedWriter) Write(row *feed.RejectedRow) error {
+	if w.failAfter > 0 && w.rows >= w.failAfter {
+		return errors.New("quota exceeded")
+	}
+	w.rows++
+	return nil
+}
+
+func (w *recordingRejectedWriter) Flush() error {
+	w.flushed = true
+	return errors.New("upload failed")
+}
+
+// failingReader fails once the feed is read.
+type failingReader struct {
+	io.Reader
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestItemReaderFlushesOnError(t *testing.T) {
+	t.Run("read error", func(t *testing.T) {
+		reader, err := feed.NewItemReader(failingReader{strings.NewReader(testFeed)})
+		assert.NoError(t, err)
+
+		rejected := &recordingRejectedWriter{}
+		count, err := reader.ReadAll(make(chan *model.Item, 10), rejected)
+		assert.EqualError(t, err, "connection reset\nfailed to flush the rejected rows: upload failed")
+		assert.Equal(t, 5, count)
+		assert.Equal(t, 5, rejected.rows)
+		assert.True(t, rejected.flushed)
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		reader, err := feed.NewItemReader(strings.NewReader(testFeed))
+		assert.NoError(t, err)
+
+		rejected := &recordingRejectedWriter{failAfter: 1}
+		count, err := reader.ReadAll(make(chan *model.Item, 10), rejected)
+		assert.EqualError(t, err, "failed to write rejected row 4: quota exceeded\nfailed to flush the rejected rows: upload failed")
+		assert.Equal(t, 2, count)
+		assert.True(t, rejected.flushed)
+	})
+}
@@ -0,0 +1,106 @@
+package feed
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RejectedRow is a row of a feed that was not ingested, with the reasons.
+type RejectedRow struct {
+	Line    int
+	Id      string
+	Reasons []string
+	Record  []string // nil when the row could not be parsed
+
+	header []string
+}
+
+// Fields returns the values of the row by column.
+func (r *RejectedRow) Fields() map[string]string {
+	if r.Record == nil {
+		return nil
+	}
+	fields := make(map[string]string, len(r.Record))
+	for i, value := range r.Record {
+		if i < len(r.header) {
+			fields[r.header[i]] = value
+		} else {
+			fields[strconv.Itoa(i+1)] = value
+		}
+	}
+	return fields
+}
+
+// RejectedWriter reports the rejected rows of a feed.
+type RejectedWriter interface {
+	Write(row *RejectedRow) error
+	Flush() error
+}
+
+// JSONLRejectedWriter writes each rejected row as a line of JSON.
+type JSONLRejectedWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewJSONLRejectedWriter(w io.Writer) *JSONLRejectedWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONLRejectedWriter{enc: enc}
+}
+
+func (w *JSONLRejectedWriter) Write(row *RejectedRow) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.enc.Encode(struct {
+		Line    int               `json:"line"`
+		Id      string            `json:"id,omitempty"`
+		Reasons []string          `json:"reasons"`
+		Row     map[string]string `json:"row,omitempty"`
+	}{row.Line, row.Id, row.Reasons, row.Fields()})
+}
+
+func (w *JSONLRejectedWriter) Flush() error {
+	return nil
+}
+
+// CSVRejectedWriter writes the rejected rows as they appear in the feed,
+// after a line and a reasons column. The header of the feed is written with
+// the first row.
+type CSVRejectedWriter struct {
+	mu          sync.Mutex
+	csv         *csv.Writer
+	wroteHeader bool
+}
+
+func NewCSVRejectedWriter(w io.Writer) *CSVRejectedWriter {
+	return &CSVRejectedWriter{csv: csv.NewWriter(w)}
+}
+
+func (w *CSVRejectedWriter) Write(row *RejectedRow) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.wroteHeader {
+		if err := w.csv.Write(append([]string{"line", "reasons"}, row.header...)); err != nil {
+			return err
+		}
+		w.wroteHeader = true
+	}
+
+	record := append([]string{strconv.Itoa(row.Line), strings.Join(row.Reasons, "; ")}, row.Record...)
+	return w.csv.Write(record)
+}
+
+func (w *CSVRejectedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.csv.Flush()
+	return w.csv.Error()
+}
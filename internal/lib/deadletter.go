@@ -0,0 +1,39 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+
+	"github/shaolim/kakashi/pkg/esclient"
+)
+
+// PubSubDeadLetterSink publishes the bulk actions that could not be written,
+// with the index as an attribute so that subscriptions can filter on it.
+type PubSubDeadLetterSink struct {
+	publisher Publisher
+}
+
+func NewPubSubDeadLetterSink(publisher Publisher) *PubSubDeadLetterSink {
+	return &PubSubDeadLetterSink{
+		publisher: publisher,
+	}
+}
+
+// Write waits for the message to be published.
+func (s *PubSubDeadLetterSink) Write(ctx context.Context, letter *esclient.DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+
+	result := s.publisher.Publish(ctx, &pubsub.Message{
+		Data: data,
+		Attributes: map[string]string{
+			"index": letter.Index,
+		},
+	})
+	_, err = result.Get(ctx)
+	return err
+}
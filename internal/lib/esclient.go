@@ -0,0 +1,41 @@
+package lib
+
+import (
+	"strings"
+
+	"github/shaolim/kakashi/pkg/esclient"
+
+	"github.com/spf13/viper"
+)
+
+const defaultElasticsearchUrl = "http://localhost:9200"
+
+// NewESClient creates a client for the comma separated ELASTICSEARCH_URLS nodes,
+// or for ELASTICSEARCH_CLOUD_ID when it is set.
+func NewESClient(vp *viper.Viper, options ...esclient.ClientOption) esclient.Client {
+	var urls []string
+	for _, u := range strings.Split(vp.GetString("ELASTICSEARCH_URLS"), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		urls = []string{defaultElasticsearchUrl}
+	}
+
+	if cloudID := vp.GetString("ELASTICSEARCH_CLOUD_ID"); cloudID != "" {
+		options = append(options, esclient.WithCloudID(cloudID))
+	} else {
+		options = append(options, esclient.WithNodes(urls[1:]...))
+	}
+
+	if apiKey := vp.GetString("ELASTICSEARCH_API_KEY"); apiKey != "" {
+		options = append(options, esclient.WithEncodedApiKey(apiKey))
+	}
+
+	if fingerprint := vp.GetString("ELASTICSEARCH_CA_FINGERPRINT"); fingerprint != "" {
+		options = append(options, esclient.WithCertificateFingerprint(fingerprint))
+	}
+
+	return esclient.NewClient(urls[0], options...)
+}
@@ -0,0 +1,43 @@
+package lib
+
+import (
+	"context"
+
+	"cloud.google.com/go/storage"
+
+	"github/shaolim/kakashi/internal/feed"
+)
+
+// GCSRejectedWriter writes the rejected rows of a feed as JSONL to a GCS
+// object. The object is only created with the first rejected row, so that a
+// clean feed leaves no report behind.
+type GCSRejectedWriter struct {
+	ctx    context.Context
+	object *storage.ObjectHandle
+	w      *storage.Writer
+	rows   *feed.JSONLRejectedWriter
+}
+
+func NewGCSRejectedWriter(ctx context.Context, object *storage.ObjectHandle) *GCSRejectedWriter {
+	return &GCSRejectedWriter{
+		ctx:    ctx,
+		object: object,
+	}
+}
+
+func (w *GCSRejectedWriter) Write(row *feed.RejectedRow) error {
+	if w.w == nil {
+		w.w = w.object.NewWriter(w.ctx)
+		w.w.ContentType = "application/x-ndjson"
+		w.rows = feed.NewJSONLRejectedWriter(w.w)
+	}
+	return w.rows.Write(row)
+}
+
+// Flush uploads the report, the writer cannot be used afterwards.
+func (w *GCSRejectedWriter) Flush() error {
+	if w.w == nil {
+		return nil
+	}
+	return w.w.Close()
+}
@@ -1,8 +1,11 @@
 package model
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"net/url"
-	"strconv"
 	"strings"
 	"time"
 
@@ -10,24 +13,24 @@ import (
 )
 
 type Item struct {
-	LanguageCode          string  `csv:"Language Code"`
-	Id                    string  `csv:"ID"`
-	Title                 string  `csv:"Title"`
-	Link                  string  `csv:"Link"`
+	LanguageCode          string  `csv:"Language Code" validate:"key,language"`
+	Id                    string  `csv:"ID" validate:"key"`
+	Title                 string  `csv:"Title" validate:"required"`
+	Link                  string  `csv:"Link" validate:"required,url"`
 	Price                 string  `csv:"Price"`
 	CurrencyCode          string  `csv:"Currency"`
-	ImageLink             string  `csv:"Image link"`
+	ImageLink             string  `csv:"Image link" validate:"url"`
 	Description           string  `csv:"Description"`
 	AdditionalImageLink   string  `csv:"Additional image link"`
 	GoogleProductCategory string  `csv:"Google product category"`
-	AvailableFrom         string  `csv:"Availability date"`
+	AvailableFrom         string  `csv:"Availability date" validate:"date"`
 	ProductType           string  `csv:"Product type"`
 	ProductCode           string  `csv:"Product Code"`
 	ProductCodeType       string  `csv:"Product Code type"`
-	Condition             string  `csv:"Condition"`
-	AgeGroup              string  `csv:"Age group"`
+	Condition             string  `csv:"Condition" validate:"enum=new|refurbished|used"`
+	AgeGroup              string  `csv:"Age group" validate:"enum=newborn|infant|toddler|kids|adult"`
 	Color                 string  `csv:"Color"`
-	Gender                string  `csv:"Gender"`
+	Gender                string  `csv:"Gender" validate:"enum=male|female|unisex"`
 	Pattern               string  `csv:"Pattern"`
 	SizeValue             string  `csv:"Size"`
 	SizeType              string  `csv:"Size type"`
@@ -52,12 +55,28 @@ type ItemDoc struct {
 	IsDeleted            bool                   `json:"isDeleted"`
 	Record               *RecordWithDelete      `json:"record"`
 	AdditionalProperties map[string]interface{} `json:"additionalProperties"`
+	ContentHash          string                 `json:"contentHash"`
+}
+
+// Hash returns the sha256 of the content of the item. The id and the record
+// times are left out, so the same item of two feeds has the same hash.
+func (d ItemDoc) Hash() string {
+	d.ID = primitive.NilObjectID
+	d.Record = nil
+	d.ContentHash = ""
+	p, err := json.Marshal(d)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(p)
+	return hex.EncodeToString(sum[:])
 }
 
 type Price struct {
 	CurrencyCode string `json:"currencyCode"`
 	PriceMajor   uint32 `json:"priceMajor"`
-	PriceMinor   uint32 `json:"priceMinor"`
+	PriceMinor   uint32 `json:"priceMinor"` // in the minor unit of the currency, e.g. 50 for "12.5" USD
+	Amount       int64  `json:"amount"`     // the price times PriceAmountScale, for sorting and range queries
 }
 
 type RecordWithDelete struct {
@@ -66,18 +85,14 @@ type RecordWithDelete struct {
 	Deleted *time.Time
 }
 
-func ConvertItemToItemDoc(item Item) ItemDoc {
-	var (
-		priceMajor uint64
-		priceMinor uint64
-	)
-
-	splitPrice := strings.Split(item.Price, ".")
-	if len(splitPrice) > 1 {
-		priceMajor, _ = strconv.ParseUint(splitPrice[0], 10, 32)
-		priceMinor, _ = strconv.ParseUint(splitPrice[1], 10, 32)
-	} else if len(splitPrice) > 0 {
-		priceMajor, _ = strconv.ParseUint(splitPrice[0], 10, 32)
+// ConvertItemToItemDoc fails when the item does not pass Validate.
+func ConvertItemToItemDoc(item Item) (ItemDoc, error) {
+	if err := item.Validate(); err != nil {
+		return ItemDoc{}, fmt.Errorf("item %s: %w", item.Id, err)
+	}
+	price, _ := ParsePrice(item.Price, item.CurrencyCode)
+	if price == nil {
+		price = &Price{CurrencyCode: item.CurrencyCode}
 	}
 
 	images := []string{}
@@ -94,21 +109,18 @@ func ConvertItemToItemDoc(item Item) ItemDoc {
 		}
 	}
 
-	return ItemDoc{
+	now := time.Now()
+	doc := ItemDoc{
 		LanguageCode: item.LanguageCode,
 		ID:           primitive.NewObjectID(),
 		Sku:          item.Id,
 		Title:        item.Title,
 		Link:         item.Link,
-		Price: &Price{
-			CurrencyCode: item.CurrencyCode,
-			PriceMajor:   uint32(priceMajor),
-			PriceMinor:   uint32(priceMinor),
-		},
-		Images:      images,
-		Description: item.Description,
-		IsDeleted:   item.IsDeleted(),
-		Record:      &RecordWithDelete{Created: time.Now(), Updated: time.Now()},
+		Price:        price,
+		Images:       images,
+		Description:  item.Description,
+		IsDeleted:    item.IsDeleted(),
+		Record:       &RecordWithDelete{Created: now, Updated: now},
 		AdditionalProperties: map[string]interface{}{
 			"GoogleProductCategory": item.GoogleProductCategory,
 			"AvailableFrom":         item.AvailableFrom,
@@ -126,9 +138,12 @@ func ConvertItemToItemDoc(item Item) ItemDoc {
 			"ProductCode":           item.ProductCode,
 			"Title":                 item.Title,
 			"Description":           item.Description,
-			"CurrencyCode":          item.CurrencyCode,
-			"PriceMajor":            uint32(priceMajor),
-			"PriceMinor":            uint32(priceMinor),
+			"CurrencyCode":          price.CurrencyCode,
+			"PriceMajor":            price.PriceMajor,
+			"PriceMinor":            price.PriceMinor,
 		},
 	}
+	doc.ContentHash = doc.Hash()
+
+	return doc, nil
 }
@@ -0,0 +1,35 @@
+package model_test
+
+import (
+	"github/shaolim/kakashi/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestItemDocHash(t *testing.T) {
+	doc := model.ItemDoc{
+		LanguageCode: "ja",
+		Sku:          "SKU-1",
+		Title:        "シャツ",
+		Price:        &model.Price{CurrencyCode: "JPY", PriceMajor: 1980, Amount: 19800000},
+	}
+	hash := doc.Hash()
+	assert.Len(t, hash, 64)
+
+	stored := doc
+	stored.ID = primitive.NewObjectID()
+	stored.Record = &model.RecordWithDelete{Created: time.Now(), Updated: time.Now()}
+	stored.ContentHash = hash
+	assert.Equal(t, hash, stored.Hash())
+
+	changed := doc
+	changed.Title = "ブラウス"
+	assert.NotEqual(t, hash, changed.Hash())
+
+	deleted := doc
+	deleted.IsDeleted = true
+	assert.NotEqual(t, hash, deleted.Hash())
+}
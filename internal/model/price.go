@@ -0,0 +1,161 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var ErrInvalidPrice = errors.New("invalid price")
+
+// PriceAmountScale is the scale of Price.Amount, 10 to the largest minor unit
+// exponent of ISO 4217 (4 for CLF and UYW), so that the amounts of all
+// currencies are exact.
+const PriceAmountScale = 10000
+
+// currencyExponents are the ISO 4217 minor unit exponents other than 2.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// CurrencyExponent returns the number of digits of the minor unit of the
+// currency, e.g. 2 for USD and 0 for JPY.
+func CurrencyExponent(currencyCode string) int {
+	if exponent, ok := currencyExponents[currencyCode]; ok {
+		return exponent
+	}
+	return 2
+}
+
+func invalidPrice(value, format string, args ...interface{}) error {
+	return fmt.Errorf("%w %q: %s", ErrInvalidPrice, value, fmt.Sprintf(format, args...))
+}
+
+// ParsePrice parses a decimal price such as "12.5", "1,299.00" or
+// "1299.00 USD" into the major and minor units of its currency. The currency
+// may be given with the value, in which case it must match currencyCode when
+// both are set. Digits past the minor unit of the currency must be zeros.
+func ParsePrice(value, currencyCode string) (*Price, error) {
+	number, code := splitPriceCurrency(strings.TrimSpace(value))
+	currencyCode = strings.ToUpper(strings.TrimSpace(currencyCode))
+	switch {
+	case code == "":
+		code = currencyCode
+	case currencyCode != "" && code != currencyCode:
+		return nil, invalidPrice(value, "currency %s does not match %s", code, currencyCode)
+	}
+	if code == "" {
+		return nil, invalidPrice(value, "missing currency")
+	}
+	if !isCurrencyCode(code) {
+		return nil, invalidPrice(value, "invalid currency %q", code)
+	}
+	if number == "" {
+		return nil, invalidPrice(value, "missing amount")
+	}
+
+	integer, fraction, _ := strings.Cut(number, ".")
+	integer, err := removeThousandsSeparators(integer)
+	if err != nil {
+		return nil, invalidPrice(value, "%s", err)
+	}
+	if integer == "" && fraction == "" || !isDigits(integer) || !isDigits(fraction) {
+		return nil, invalidPrice(value, "not a decimal number")
+	}
+
+	exponent := CurrencyExponent(code)
+	if len(fraction) > exponent {
+		if strings.Trim(fraction[exponent:], "0") != "" {
+			return nil, invalidPrice(value, "%s has %d decimals", code, exponent)
+		}
+		fraction = fraction[:exponent]
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	major, err := parseUint32(integer)
+	if err != nil {
+		return nil, invalidPrice(value, "%s", err)
+	}
+	minor, _ := parseUint32(fraction)
+
+	scale := int64(1)
+	for i := 0; i < exponent; i++ {
+		scale *= 10
+	}
+	return &Price{
+		CurrencyCode: code,
+		PriceMajor:   major,
+		PriceMinor:   minor,
+		Amount:       int64(major)*PriceAmountScale + int64(minor)*PriceAmountScale/scale,
+	}, nil
+}
+
+// splitPriceCurrency splits a currency code written before or after the
+// amount, e.g. "1299.00 USD" or "USD 1299.00".
+func splitPriceCurrency(value string) (number, code string) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return value, ""
+	}
+	if isCurrencyCode(strings.ToUpper(fields[1])) {
+		return fields[0], strings.ToUpper(fields[1])
+	}
+	if isCurrencyCode(strings.ToUpper(fields[0])) {
+		return fields[1], strings.ToUpper(fields[0])
+	}
+	return value, ""
+}
+
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// removeThousandsSeparators removes the commas of "1,299", which must group
+// the digits by three.
+func removeThousandsSeparators(integer string) (string, error) {
+	if !strings.Contains(integer, ",") {
+		return integer, nil
+	}
+	groups := strings.Split(integer, ",")
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return "", errors.New("misplaced thousands separator")
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return "", errors.New("misplaced thousands separator")
+		}
+	}
+	return strings.Join(groups, ""), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func parseUint32(digits string) (uint32, error) {
+	var n uint64
+	for _, r := range digits {
+		n = n*10 + uint64(r-'0')
+		if n > math.MaxUint32 {
+			return 0, errors.New("amount is too large")
+		}
+	}
+	return uint32(n), nil
+}
@@ -0,0 +1,57 @@
+package model_test
+
+import (
+	"github/shaolim/kakashi/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		name         string
+		value        string
+		currencyCode string
+		expected     *model.Price
+	}{
+		{name: "one decimal", value: "12.5", currencyCode: "USD",
+			expected: &model.Price{CurrencyCode: "USD", PriceMajor: 12, PriceMinor: 50, Amount: 125000}},
+		{name: "thousands separators", value: "1,299.00", currencyCode: "USD",
+			expected: &model.Price{CurrencyCode: "USD", PriceMajor: 1299, PriceMinor: 0, Amount: 12990000}},
+		{name: "currency suffix", value: "1299.99 USD",
+			expected: &model.Price{CurrencyCode: "USD", PriceMajor: 1299, PriceMinor: 99, Amount: 12999900}},
+		{name: "currency prefix", value: "usd 0.05", currencyCode: "USD",
+			expected: &model.Price{CurrencyCode: "USD", PriceMajor: 0, PriceMinor: 5, Amount: 500}},
+		{name: "no minor unit", value: "1,980", currencyCode: "JPY",
+			expected: &model.Price{CurrencyCode: "JPY", PriceMajor: 1980, PriceMinor: 0, Amount: 19800000}},
+		{name: "trailing zeros past the minor unit", value: "1980.00", currencyCode: "JPY",
+			expected: &model.Price{CurrencyCode: "JPY", PriceMajor: 1980, PriceMinor: 0, Amount: 19800000}},
+		{name: "three decimals", value: "1.5", currencyCode: "KWD",
+			expected: &model.Price{CurrencyCode: "KWD", PriceMajor: 1, PriceMinor: 500, Amount: 15000}},
+		{name: "four decimals", value: "39,386.1234", currencyCode: "CLF",
+			expected: &model.Price{CurrencyCode: "CLF", PriceMajor: 39386, PriceMinor: 1234, Amount: 393861234}},
+		{name: "empty", value: "", currencyCode: "USD"},
+		{name: "missing currency", value: "12.50"},
+		{name: "currency mismatch", value: "12.50 EUR", currencyCode: "USD"},
+		{name: "too many decimals", value: "12.505", currencyCode: "USD"},
+		{name: "too many decimals of a four decimals currency", value: "1.00005", currencyCode: "UYW"},
+		{name: "decimals of a currency without minor unit", value: "1980.5", currencyCode: "JPY"},
+		{name: "negative", value: "-12.50", currencyCode: "USD"},
+		{name: "misplaced thousands separator", value: "12,99.00", currencyCode: "USD"},
+		{name: "not a number", value: "free", currencyCode: "USD"},
+		{name: "too large", value: "5000000000", currencyCode: "USD"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := model.ParsePrice(tc.value, tc.currencyCode)
+			if tc.expected == nil {
+				assert.ErrorIs(t, err, model.ErrInvalidPrice)
+				assert.Nil(t, actual)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package model
+
+import (
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// The rules of a string field are declared in its validate tag, separated by
+// commas:
+//
+//	key          the field is required, even on deleted rows
+//	required     the field is required, unless the row is deleted
+//	url          an absolute http or https url
+//	enum=a|b     one of the values, case insensitive
+//	date         an ISO 8601 date, with or without the time
+//	language     an ISO 639-1 language code
+//
+// Empty values only break the key and required rules.
+var itemRules = map[string]func(value, arg string) string{
+	"url":      validateUrl,
+	"enum":     validateEnum,
+	"date":     validateDate,
+	"language": validateLanguage,
+}
+
+// FieldError is a rule broken by a field, named after its csv column.
+type FieldError struct {
+	Field  string
+	Rule   string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Reason
+}
+
+type ValidationErrors []*FieldError
+
+func (e ValidationErrors) Error() string {
+	reasons := make([]string, 0, len(e))
+	for _, err := range e {
+		reasons = append(reasons, err.Error())
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// Validate checks the fields of the item against the rules of their validate
+// tag, and the price against its currency. A deleted item only needs its keys.
+func (i Item) Validate() error {
+	var errs ValidationErrors
+
+	v := reflect.ValueOf(i)
+	for n := 0; n < v.NumField(); n++ {
+		field := v.Type().Field(n)
+		tag := field.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		name := field.Tag.Get("csv")
+		value := strings.TrimSpace(v.Field(n).String())
+
+		for _, rule := range strings.Split(tag, ",") {
+			rule, arg, _ := strings.Cut(rule, "=")
+			var reason string
+			switch {
+			case rule == "key" || rule == "required":
+				if value == "" && (rule == "key" || !i.IsDeleted()) {
+					reason = "is required"
+				}
+			case value == "":
+			default:
+				check, ok := itemRules[rule]
+				if !ok {
+					panic("model: unknown validate rule " + rule)
+				}
+				reason = check(value, arg)
+			}
+			if reason != "" {
+				errs = append(errs, &FieldError{Field: name, Rule: rule, Reason: reason})
+				break
+			}
+		}
+	}
+
+	if !i.IsDeleted() {
+		if _, err := ParsePrice(i.Price, i.CurrencyCode); err != nil {
+			errs = append(errs, &FieldError{Field: "Price", Rule: "price", Reason: err.Error()})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateUrl(value, _ string) string {
+	u, err := url.ParseRequestURI(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "is not an http url"
+	}
+	return ""
+}
+
+func validateEnum(value, arg string) string {
+	values := strings.Split(arg, "|")
+	for _, v := range values {
+		if strings.EqualFold(value, v) {
+			return ""
+		}
+	}
+	return "must be one of " + strings.Join(values, ", ")
+}
+
+var dateLayouts = []string{
+	time.DateOnly,
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04-0700",
+	"2006-01-02T15:04:05-0700",
+}
+
+func validateDate(value, _ string) string {
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return ""
+		}
+	}
+	return "is not an ISO 8601 date"
+}
+
+// languageCodes are the ISO 639-1 codes.
+var languageCodes = strings.Fields(`
+	aa ab ae af ak am an ar as av ay az ba be bg bi bm bn bo br bs ca ce ch co cr cs cu cv cy
+	da de dv dz ee el en eo es et eu fa ff fi fj fo fr fy ga gd gl gn gu gv ha he hi ho hr ht
+	hu hy hz ia id ie ig ii ik io is it iu ja jv ka kg ki kj kk kl km kn ko kr ks ku kv kw ky
+	la lb lg li ln lo lt lu lv mg mh mi mk ml mn mr ms mt my na nb nd ne ng nl nn no nr nv ny
+	oc oj om or os pa pi pl ps pt qu rm rn ro ru rw sa sc sd se sg si sk sl sm sn so sq sr ss
+	st su sv sw ta te tg th ti tk tl tn to tr ts tt tw ty ug uk ur uz ve vi vo wa wo xh yi yo
+	za zh zu`)
+
+func validateLanguage(value, _ string) string {
+	if slices.Contains(languageCodes, value) {
+		return ""
+	}
+	return "is not an ISO 639-1 language code"
+}
@@ -0,0 +1,75 @@
+package model_test
+
+import (
+	"github/shaolim/kakashi/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemValidate(t *testing.T) {
+	valid := model.Item{
+		LanguageCode:  "en",
+		Id:            "101",
+		Title:         "Classic T-Shirt",
+		Link:          "https://example.com/101",
+		Price:         "19.99",
+		CurrencyCode:  "USD",
+		ImageLink:     "https://example.com/101.jpg",
+		AvailableFrom: "2024-12-01",
+		Condition:     "New",
+		AgeGroup:      "adult",
+		Gender:        "unisex",
+	}
+
+	tests := []struct {
+		name     string
+		item     func(item model.Item) model.Item
+		expected []string
+	}{
+		{name: "valid", item: func(item model.Item) model.Item { return item }},
+		{name: "deleted item with its keys", item: func(item model.Item) model.Item {
+			return model.Item{LanguageCode: "en", Id: "101", IsTargetForDelete: "1"}
+		}},
+		{name: "deleted item without id", item: func(item model.Item) model.Item {
+			return model.Item{LanguageCode: "en", IsTargetForDelete: "1"}
+		}, expected: []string{"ID: is required"}},
+		{name: "missing title and price", item: func(item model.Item) model.Item {
+			item.Title = " "
+			item.Price = ""
+			return item
+		}, expected: []string{"Title: is required", `Price: invalid price "": missing amount`}},
+		{name: "formats", item: func(item model.Item) model.Item {
+			item.LanguageCode = "english"
+			item.ImageLink = "ftp://example.com/101.jpg"
+			item.AvailableFrom = "12/01/2024"
+			item.AgeGroup = "senior"
+			item.Gender = "other"
+			return item
+		}, expected: []string{
+			"Language Code: is not an ISO 639-1 language code",
+			"Image link: is not an http url",
+			"Availability date: is not an ISO 8601 date",
+			"Age group: must be one of newborn, infant, toddler, kids, adult",
+			"Gender: must be one of male, female, unisex",
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.item(valid).Validate()
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var errs model.ValidationErrors
+			if assert.ErrorAs(t, err, &errs) {
+				var reasons []string
+				for _, fieldErr := range errs {
+					reasons = append(reasons, fieldErr.Error())
+				}
+				assert.Equal(t, tc.expected, reasons)
+			}
+		})
+	}
+}
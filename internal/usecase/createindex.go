@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"fmt"
 	"io"
@@ -26,14 +27,14 @@ func NewCreateIndexUseCase(esClient esclient.Client) *CreateIndexUseCase {
 	}
 }
 
-func (c *CreateIndexUseCase) Execute() error {
+func (c *CreateIndexUseCase) Execute(ctx context.Context) error {
 	itemIndexEnSettings, err := c.loadJsonFile(itemIndexEn)
 	if err != nil {
 		fmt.Printf("failed to load json file: %s, error: %v\n", itemIndexEn, err)
 		return err
 	}
 
-	err = c.createIndexIfNotExists(itemIndexEn, bytes.NewReader(itemIndexEnSettings))
+	err = c.createIndexIfNotExists(ctx, itemIndexEn, bytes.NewReader(itemIndexEnSettings))
 	if err != nil {
 		fmt.Printf("failed to create index: %s, error: %v\n", itemIndexEn, err)
 		return err
@@ -45,7 +46,7 @@ func (c *CreateIndexUseCase) Execute() error {
 		return err
 	}
 
-	err = c.createIndexIfNotExists(itemIndexJa, bytes.NewReader(itemIndexJaSettings))
+	err = c.createIndexIfNotExists(ctx, itemIndexJa, bytes.NewReader(itemIndexJaSettings))
 	if err != nil {
 		fmt.Printf("failed to crate index: %s, error: %+v\n", itemIndexJa, err)
 		return err
@@ -54,17 +55,18 @@ func (c *CreateIndexUseCase) Execute() error {
 	return nil
 }
 
-func (c *CreateIndexUseCase) createIndexIfNotExists(indexName string, body io.Reader) error {
-	indexRes, err := c.esClient.GetIndeces([]string{indexName}, esclient.GetIndecesWithHttpHeadOnly())
-	if err != nil {
+func (c *CreateIndexUseCase) createIndexIfNotExists(ctx context.Context, indexName string, body io.Reader) error {
+	_, err := c.esClient.GetIndeces(ctx, []string{indexName}, esclient.GetIndecesWithHttpHeadOnly())
+	if err == nil {
+		return nil
+	}
+	if !esclient.IsNotFound(err) {
 		return err
 	}
 
-	if indexRes.StatusCode == 404 {
-		_, err = c.esClient.CreateIndex(indexName, body)
-		if err != nil {
-			return err
-		}
+	_, err = c.esClient.CreateIndex(ctx, indexName, body)
+	if err != nil && !esclient.IsResourceAlreadyExists(err) {
+		return err
 	}
 
 	return nil
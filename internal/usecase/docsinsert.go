@@ -1,98 +1,118 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
-	"github.com/gocarina/gocsv"
-
+	"github/shaolim/kakashi/internal/feed"
 	"github/shaolim/kakashi/internal/model"
 	"github/shaolim/kakashi/pkg/esclient"
 )
 
+const docsInsertBatchSize = 500
+
 type DocsInsertUseCase struct {
-	esClient esclient.Client
+	esClient   esclient.Client
+	deadLetter esclient.DeadLetterSink
+	rejected   feed.RejectedWriter
 }
 
-func NewDocsInsertUseCase(esClient esclient.Client) *DocsInsertUseCase {
+func NewDocsInsertUseCase(esClient esclient.Client, deadLetter esclient.DeadLetterSink, rejected feed.RejectedWriter) *DocsInsertUseCase {
 	return &DocsInsertUseCase{
-		esClient: esClient,
+		esClient:   esClient,
+		deadLetter: deadLetter,
+		rejected:   rejected,
 	}
 }
 
-func (u *DocsInsertUseCase) Execute(indexname string, filename string) error {
+func (u *DocsInsertUseCase) Execute(ctx context.Context, indexname string, filename string) error {
 	file, err := os.OpenFile(filename, os.O_RDONLY, 0)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
+	reader, err := feed.NewItemReader(file)
+	if err != nil {
+		return err
+	}
+
 	queue := make(chan *model.Item, 1000)
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		u.processItem(indexname, queue)
-	}()
-
-	go func() {
-		if err := gocsv.UnmarshalToChan(file, queue); err != nil {
-			fmt.Printf("failed to UnmarshalToChan: %+v\n", err)
-			return
-		}
+		u.processItem(ctx, indexname, queue)
 	}()
 
+	rejected, err := reader.ReadAll(queue, u.rejected)
 	wg.Wait()
 
-	return nil
+	if rejected > 0 {
+		fmt.Printf("rejected rows: %d\n", rejected)
+	}
+	return err
 }
 
-func (u *DocsInsertUseCase) processItem(indexname string, in <-chan *model.Item) {
-	batches := make([]*model.Item, 0, 100)
-	for item := range in {
-		batches = append(batches, item)
-		if len(batches) >= 100 {
-			req := u.convItemToBulkRequest(batches)
-			res, err := u.esClient.Bulk(indexname, req)
+func (u *DocsInsertUseCase) processItem(ctx context.Context, indexname string, in <-chan *model.Item) {
+	options := []esclient.BulkProcessorOption{
+		esclient.BulkProcessorWithWorkers(4),
+		esclient.BulkProcessorWithBulkActions(500),
+		esclient.BulkProcessorWithFlushInterval(5 * time.Second),
+		esclient.BulkProcessorWithRetry(5),
+		esclient.BulkProcessorWithOnFailure(func(_ esclient.BulkableRequest, item *esclient.BulkResponseItem, err error) {
 			if err != nil {
-				fmt.Println(err)
+				fmt.Printf("failed to bulk: %v\n", err)
+				return
 			}
+			fmt.Printf("failed to index %s: %d %+v\n", item.Id, item.Status, item.Error)
+		}),
+	}
+	if u.deadLetter != nil {
+		options = append(options, esclient.BulkProcessorWithDeadLetterSink(u.deadLetter))
+	}
+	processor := esclient.NewBulkProcessor(ctx, u.esClient, indexname, options...)
 
-			fmt.Println(res.StatusCode)
-
-			if res.IsError() {
-				fmt.Printf("failed to bulk: %+v\n", res.Result)
+	// items are compared with the stored ones in batches of one multi get
+	var skipped, invalid int
+	batch := make([]model.ItemDoc, 0, docsInsertBatchSize)
+	add := func() {
+		requests, n, err := itemChanges(ctx, u.esClient, indexname, batch)
+		if err != nil {
+			fmt.Printf("failed to get the stored items, sending all of them: %v\n", err)
+		}
+		skipped += n
+		for _, request := range requests {
+			if err := processor.Add(ctx, request); err != nil {
+				fmt.Printf("failed to add item to bulk: %v\n", err)
 			}
-
-			batches = make([]*model.Item, 0, 100)
 		}
+		batch = batch[:0]
 	}
-
-	if len(batches) > 0 {
-		req := u.convItemToBulkRequest(batches)
-		res, err := u.esClient.Bulk(indexname, req)
+	for item := range in {
+		doc, err := model.ConvertItemToItemDoc(*item)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Printf("invalid item: %v\n", err)
+			invalid++
+			continue
 		}
-
-		fmt.Println(res.StatusCode)
-
-		if res.IsError() {
-			fmt.Printf("failed to bulk: %+v\n", res.Result)
+		batch = append(batch, doc)
+		if len(batch) >= docsInsertBatchSize {
+			add()
 		}
 	}
-}
+	if len(batch) > 0 {
+		add()
+	}
 
-func (u *DocsInsertUseCase) convItemToBulkRequest(items []*model.Item) *esclient.BulkRequests {
-	bulkRequest := &esclient.BulkRequests{}
-	for _, item := range items {
-		docs := model.ConvertItemToItemDoc(*item)
-		if item.IsDeleted() {
-			bulkRequest.Add(esclient.NewBulkDeleteRequest(docs.Sku))
-		} else {
-			bulkRequest.Add(esclient.NewBulkIndexRequest().SetId(docs.Sku).SetDoc(docs))
-		}
+	if err := processor.Close(ctx); err != nil {
+		fmt.Printf("failed to close bulk processor: %v\n", err)
 	}
-	return bulkRequest
+
+	stats := processor.Stats()
+	fmt.Printf("indexed: %d, skipped: %d, invalid: %d, failed: %d, retried: %d, dead lettered: %d, bytes sent: %d\n",
+		stats.Indexed, skipped, invalid, stats.Failed, stats.Retried, stats.DeadLettered, stats.BytesSent)
 }
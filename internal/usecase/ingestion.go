@@ -3,14 +3,15 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"github/shaolim/kakashi/internal/feed"
 	"github/shaolim/kakashi/internal/lib"
 	"github/shaolim/kakashi/internal/model"
 	"log/slog"
+	"strings"
 	"sync"
 
 	"cloud.google.com/go/pubsub"
 	"cloud.google.com/go/storage"
-	"github.com/gocarina/gocsv"
 	"github.com/spf13/viper"
 )
 
@@ -35,13 +36,26 @@ func NewIngestionUseCase(
 	}
 }
 
+// RejectedRowsPrefix is the prefix of the reports of rejected rows, written
+// to the bucket of the feed. Objects under it are not ingested.
+const RejectedRowsPrefix = "rejected/"
+
 func (u *IngestionUseCase) Execute(ctx context.Context, bucketname string, filename string) error {
+	if strings.HasPrefix(filename, RejectedRowsPrefix) {
+		return nil
+	}
+
 	rc, err := u.gcsClient.Bucket(bucketname).Object(filename).NewReader(ctx)
 	if err != nil {
 		return err
 	}
 	defer rc.Close()
 
+	reader, err := feed.NewItemReader(rc)
+	if err != nil {
+		return err
+	}
+
 	queue := make(chan *model.Item, u.viper.GetInt("PARSER_QUEUE_SIZE"))
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -50,15 +64,17 @@ func (u *IngestionUseCase) Execute(ctx context.Context, bucketname string, filen
 		u.processItem(ctx, queue)
 	}()
 
-	go func() {
-		if err := gocsv.UnmarshalToChan(rc, queue); err != nil {
-			u.logger.Error("failed to UnmarshalToChan", slog.Any("error", err))
-			return
-		}
-	}()
-
+	report := RejectedRowsPrefix + filename + ".jsonl"
+	rejected, err := reader.ReadAll(queue, lib.NewGCSRejectedWriter(ctx, u.gcsClient.Bucket(bucketname).Object(report)))
 	wg.Wait()
 
+	if err != nil {
+		u.logger.Error("failed to read the feed", slog.String("file", filename), slog.Any("error", err))
+		return err
+	}
+	if rejected > 0 {
+		u.logger.Warn("rejected rows", slog.String("file", filename), slog.Int("rejected", rejected), slog.String("report", report))
+	}
 	return nil
 }
 
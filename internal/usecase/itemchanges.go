@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github/shaolim/kakashi/internal/model"
+	"github/shaolim/kakashi/pkg/esclient"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+)
+
+// itemUpsertScript replaces the stored item with params.doc unless its content
+// hash is unchanged, keeping the mongo id and the creation time of the first
+// ingestion.
+const itemUpsertScript = `if (ctx._source.contentHash == params.doc.contentHash) {
+  ctx.op = 'noop';
+} else {
+  def mongoId = ctx._source.mongoId;
+  def created = ctx._source.record?.Created;
+  ctx._source.putAll(params.doc);
+  if (mongoId != null) {
+    ctx._source.mongoId = mongoId;
+  }
+  if (created != null) {
+    ctx._source.record = ['Created': created, 'Updated': params.doc.record.Updated, 'Deleted': params.doc.record.Deleted];
+  }
+}`
+
+// itemChanges returns the bulk requests of docs for index, skipping the docs
+// whose stored content hash is unchanged and the deletes of docs that are not
+// stored. When the stored hashes cannot be fetched every doc is sent, the
+// upsert script still leaves unchanged docs alone.
+func itemChanges(ctx context.Context, client esclient.MultiGet, index string, docs []model.ItemDoc) (requests []esclient.BulkableRequest, skipped int, err error) {
+	stored, err := storedItemHashes(ctx, client, index, docs)
+	for _, doc := range docs {
+		if stored != nil {
+			hash, found := stored[doc.Sku]
+			if doc.IsDeleted && !found || !doc.IsDeleted && found && hash == doc.ContentHash {
+				skipped++
+				continue
+			}
+		}
+
+		if doc.IsDeleted {
+			requests = append(requests, esclient.NewBulkDeleteRequest(doc.Sku))
+		} else {
+			requests = append(requests, newItemUpsertRequest(doc))
+		}
+	}
+
+	return requests, skipped, err
+}
+
+func newItemUpsertRequest(doc model.ItemDoc) esclient.BulkableRequest {
+	return esclient.NewBulkUpdateRequest(doc.Sku).
+		SetRetryOnConflict(3).
+		SetScript(esquery.NewScript(itemUpsertScript).SetParam("doc", doc)).
+		SetUpsert(doc)
+}
+
+// storedItemHashes returns the content hash of the stored items by sku, an
+// empty hash for items stored before hashing.
+func storedItemHashes(ctx context.Context, client esclient.MultiGet, index string, docs []model.ItemDoc) (map[string]string, error) {
+	if len(docs) == 0 {
+		return map[string]string{}, nil
+	}
+
+	req := esclient.NewMultiGetRequest()
+	for _, doc := range docs {
+		req.AddIds(doc.Sku)
+	}
+
+	res, err := esclient.MGet[struct {
+		ContentHash string `json:"contentHash"`
+	}](ctx, client, index, req, esclient.DocumentWithSourceIncludes("contentHash"))
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string, len(docs))
+	for _, doc := range res.Result.Docs {
+		if doc.Error != nil {
+			return nil, fmt.Errorf("failed to get item %s: %s", doc.Id, doc.Error.Reason)
+		}
+		if !doc.Found {
+			continue
+		}
+		hashes[doc.Id] = ""
+		if doc.Source != nil {
+			hashes[doc.Id] = doc.Source.ContentHash
+		}
+	}
+	return hashes, nil
+}
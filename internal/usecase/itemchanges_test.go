@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"github/shaolim/kakashi/internal/model"
+	"github/shaolim/kakashi/pkg/esclient"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newMultiGetServer answers _mget with status and body, and records the
+// requested ids.
+func newMultiGetServer(t *testing.T, status int, body string) (*httptest.Server, *[]string) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/item_index_ja/_mget", r.URL.Path)
+		assert.Equal(t, "contentHash", r.URL.Query().Get("_source_includes"))
+
+		var req struct {
+			Docs []struct {
+				Id string `json:"_id"`
+			} `json:"docs"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid _mget body: %v", err)
+		}
+		for _, doc := range req.Docs {
+			ids = append(ids, doc.Id)
+		}
+
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &ids
+}
+
+// bulkActions returns the action and the id of each request.
+func bulkActions(t *testing.T, requests []esclient.BulkableRequest) []string {
+	var actions []string
+	for _, request := range requests {
+		body, err := request.String()
+		assert.NoError(t, err)
+		line, _, _ := strings.Cut(body, "\n")
+		var action map[string]struct {
+			Id string `json:"_id"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(line), &action))
+		for name, meta := range action {
+			actions = append(actions, name+" "+meta.Id)
+		}
+	}
+	return actions
+}
+
+func TestItemChanges(t *testing.T) {
+	server, ids := newMultiGetServer(t, http.StatusOK, `{
+		"docs": [
+			{"_index": "item_index_ja", "_id": "unchanged", "found": true, "_source": {"contentHash": "hash-1"}},
+			{"_index": "item_index_ja", "_id": "changed", "found": true, "_source": {"contentHash": "old"}},
+			{"_index": "item_index_ja", "_id": "unhashed", "found": true, "_source": {}},
+			{"_index": "item_index_ja", "_id": "new", "found": false},
+			{"_index": "item_index_ja", "_id": "deleted", "found": true, "_source": {"contentHash": "hash-5"}},
+			{"_index": "item_index_ja", "_id": "never-stored", "found": false}
+		]
+	}`)
+
+	docs := []model.ItemDoc{
+		{Sku: "unchanged", ContentHash: "hash-1"},
+		{Sku: "changed", ContentHash: "hash-2"},
+		{Sku: "unhashed", ContentHash: "hash-3"},
+		{Sku: "new", ContentHash: "hash-4"},
+		{Sku: "deleted", IsDeleted: true, ContentHash: "hash-5"},
+		{Sku: "never-stored", IsDeleted: true, ContentHash: "hash-6"},
+	}
+
+	requests, skipped, err := itemChanges(context.Background(), esclient.NewClient(server.URL), "item_index_ja", docs)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"unchanged", "changed", "unhashed", "new", "deleted", "never-stored"}, *ids)
+	assert.Equal(t, 2, skipped)
+	assert.Equal(t, []string{"update changed", "update unhashed", "update new", "delete deleted"}, bulkActions(t, requests))
+}
+
+func TestItemChangesWithoutStoredHashes(t *testing.T) {
+	server, _ := newMultiGetServer(t, http.StatusInternalServerError,
+		`{"error":{"type":"exception","reason":"boom"},"status":500}`)
+
+	docs := []model.ItemDoc{
+		{Sku: "unchanged", ContentHash: "hash-1"},
+		{Sku: "never-stored", IsDeleted: true, ContentHash: "hash-2"},
+	}
+
+	requests, skipped, err := itemChanges(context.Background(), esclient.NewClient(server.URL), "item_index_ja", docs)
+	assert.Error(t, err)
+	assert.Equal(t, 0, skipped)
+	assert.Equal(t, []string{"update unchanged", "delete never-stored"}, bulkActions(t, requests))
+}
+
+func TestItemChangesWithDocErrors(t *testing.T) {
+	server, _ := newMultiGetServer(t, http.StatusOK, `{
+		"docs": [
+			{"_index": "item_index_ja", "_id": "unchanged", "found": true, "_source": {"contentHash": "hash-1"}},
+			{"_index": "item_index_ja", "_id": "broken",
+				"error": {"type": "no_shard_available_action_exception", "reason": "no shard available"}}
+		]
+	}`)
+
+	docs := []model.ItemDoc{
+		{Sku: "unchanged", ContentHash: "hash-1"},
+		{Sku: "broken", ContentHash: "hash-2"},
+	}
+
+	requests, skipped, err := itemChanges(context.Background(), esclient.NewClient(server.URL), "item_index_ja", docs)
+	assert.EqualError(t, err, "failed to get item broken: no shard available")
+	assert.Equal(t, 0, skipped)
+	assert.Equal(t, []string{"update unchanged", "update broken"}, bulkActions(t, requests))
+}
+
+func TestItemUpsertRequest(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	doc := model.ItemDoc{
+		LanguageCode: "ja",
+		ID:           primitive.NewObjectID(),
+		Sku:          "SKU-1",
+		Title:        "シャツ",
+		Record:       &model.RecordWithDelete{Created: created, Updated: created},
+		ContentHash:  "hash-1",
+	}
+
+	body, err := newItemUpsertRequest(doc).String()
+	assert.NoError(t, err)
+	action, source, _ := strings.Cut(body, "\n")
+	assert.JSONEq(t, `{"update":{"_id":"SKU-1","retry_on_conflict":3}}`, action)
+
+	var update struct {
+		Script struct {
+			Source string `json:"source"`
+			Params struct {
+				Doc json.RawMessage `json:"doc"`
+			} `json:"params"`
+		} `json:"script"`
+		Upsert json.RawMessage `json:"upsert"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(source), &update))
+
+	expected, err := json.Marshal(doc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(update.Upsert))
+	assert.JSONEq(t, string(expected), string(update.Script.Params.Doc))
+
+	script := update.Script.Source
+	assert.Contains(t, script, "ctx._source.contentHash == params.doc.contentHash")
+	assert.Contains(t, script, "ctx.op = 'noop'")
+	assert.Contains(t, script, "ctx._source.putAll(params.doc)")
+	assert.Contains(t, script, "ctx._source.mongoId = mongoId")
+	assert.Contains(t, script, "'Created': created")
+	assert.Less(t, strings.Index(script, "def mongoId = ctx._source.mongoId"), strings.Index(script, "putAll"))
+	assert.Less(t, strings.Index(script, "def created = ctx._source.record?.Created"), strings.Index(script, "putAll"))
+}
@@ -2,77 +2,131 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github/shaolim/kakashi/config"
 	"github/shaolim/kakashi/internal/model"
 	"github/shaolim/kakashi/pkg/esclient"
 	"log/slog"
+	"sync"
 )
 
 type ItemUpsertUseCase struct {
-	logger   *slog.Logger
-	esClient esclient.Client
+	logger     *slog.Logger
+	esClient   esclient.Client
+	deadLetter esclient.DeadLetterSink
 }
 
-func NewItemUpsertUseCase(logger *slog.Logger, esClient esclient.Client) *ItemUpsertUseCase {
+func NewItemUpsertUseCase(logger *slog.Logger, esClient esclient.Client, deadLetter esclient.DeadLetterSink) *ItemUpsertUseCase {
 	return &ItemUpsertUseCase{
-		logger:   logger,
-		esClient: esClient,
+		logger:     logger,
+		esClient:   esClient,
+		deadLetter: deadLetter,
 	}
 }
 
+var itemIndexes = map[string]string{
+	"en": config.ItemIndexEn,
+	"ja": config.ItemIndexJa,
+}
+
 func (u *ItemUpsertUseCase) Execute(ctx context.Context, items []*model.Item) error {
-	req := u.convItemToBulkRequest(items)
-	enBulkRequest := req["en"]
-	if enBulkRequest.Length() > 0 {
-		res, err := u.esClient.Bulk(config.ItemIndexEn, enBulkRequest)
-		if err != nil {
-			u.logger.Error("failed to bulk insert", slog.String("index", config.ItemIndexEn), slog.Any("error", err))
-			return err
+	req := u.convItemToBulkRequest(ctx, items)
+
+	// a failed bulk request is reported for each of its items, keep it once per index
+	var mu sync.Mutex
+	bulkErrs := make(map[string]error)
+	onFailure := func(index string) func(esclient.BulkableRequest, *esclient.BulkResponseItem, error) {
+		return func(_ esclient.BulkableRequest, item *esclient.BulkResponseItem, err error) {
+			if err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				if bulkErrs[index] == nil {
+					u.logger.Error("failed to bulk insert", slog.String("index", index), slog.Any("error", err))
+					bulkErrs[index] = err
+				}
+				return
+			}
+			u.logger.Error("failed to upsert item", slog.String("index", index), slog.String("id", item.Id), slog.Int("status", item.Status), slog.Any("error", item.Error))
 		}
+	}
 
-		u.logger.Info("status code", slog.String("index", config.ItemIndexEn), slog.Int("status_code", res.StatusCode))
+	processors := make(map[string]*esclient.BulkProcessor, len(itemIndexes))
+	for languageCode, index := range itemIndexes {
+		processors[languageCode] = u.newBulkProcessor(ctx, index, onFailure(index))
 	}
 
-	jaBulkRequest := req["ja"]
-	if jaBulkRequest.Length() > 0 {
-		res, err := u.esClient.Bulk(config.ItemIndexJa, jaBulkRequest)
-		if err != nil {
-			u.logger.Error("failed to bulk insert", slog.String("index", config.ItemIndexJa), slog.Any("error", err))
-			return err
+	var errs []error
+	for languageCode, processor := range processors {
+		for _, request := range req[languageCode] {
+			if err := processor.Add(ctx, request); err != nil {
+				errs = append(errs, err)
+				break
+			}
 		}
+	}
 
-		u.logger.Info("status code", slog.String("index", config.ItemIndexJa), slog.Int("status_code", res.StatusCode))
-
+	for languageCode, processor := range processors {
+		if err := processor.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		stats := processor.Stats()
+		if stats.Flushed > 0 {
+			u.logger.Info("bulk upsert", slog.String("language_code", languageCode),
+				slog.Int64("indexed", stats.Indexed), slog.Int64("failed", stats.Failed),
+				slog.Int64("retried", stats.Retried), slog.Int64("dead_lettered", stats.DeadLettered))
+		}
+		// a bulk response with errors:true is a failure even though its status is 200
+		if stats.Failed > 0 {
+			errs = append(errs, fmt.Errorf("failed to upsert %d items in %s", stats.Failed, languageCode))
+		}
 	}
 
-	return nil
+	for _, err := range bulkErrs {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
 }
 
-func (u *ItemUpsertUseCase) convItemToBulkRequest(items []*model.Item) map[string]*esclient.BulkRequests {
-	enBulkRequest := &esclient.BulkRequests{}
-	jaBulkRequest := &esclient.BulkRequests{}
+func (u *ItemUpsertUseCase) newBulkProcessor(ctx context.Context, index string, onFailure func(esclient.BulkableRequest, *esclient.BulkResponseItem, error)) *esclient.BulkProcessor {
+	options := []esclient.BulkProcessorOption{
+		esclient.BulkProcessorWithRetry(5),
+		esclient.BulkProcessorWithOnFailure(onFailure),
+	}
+	if u.deadLetter != nil {
+		options = append(options, esclient.BulkProcessorWithDeadLetterSink(u.deadLetter))
+	}
+	return esclient.NewBulkProcessor(ctx, u.esClient, index, options...)
+}
 
-	result := make(map[string]*esclient.BulkRequests)
+// convItemToBulkRequest returns the bulk requests of the items that changed
+// since the last ingestion, by language code.
+func (u *ItemUpsertUseCase) convItemToBulkRequest(ctx context.Context, items []*model.Item) map[string][]esclient.BulkableRequest {
+	byLanguage := make(map[string][]model.ItemDoc)
 	for _, item := range items {
-		docs := model.ConvertItemToItemDoc(*item)
-
-		if item.LanguageCode == "en" {
-			if item.IsDeleted() {
-				enBulkRequest.Add(esclient.NewBulkDeleteRequest(docs.Sku))
-			} else {
-				enBulkRequest.Add(esclient.NewBulkIndexRequest().SetId(docs.Sku).SetDoc(docs))
-			}
-		} else if item.LanguageCode == "ja" {
-			if item.IsDeleted() {
-				jaBulkRequest.Add(esclient.NewBulkDeleteRequest(docs.Sku))
-			} else {
-				jaBulkRequest.Add(esclient.NewBulkIndexRequest().SetId(docs.Sku).SetDoc(docs))
-			}
+		if _, ok := itemIndexes[item.LanguageCode]; !ok {
+			continue
 		}
+		doc, err := model.ConvertItemToItemDoc(*item)
+		if err != nil {
+			u.logger.Error("invalid item", slog.String("id", item.Id), slog.Any("error", err))
+			continue
+		}
+		byLanguage[item.LanguageCode] = append(byLanguage[item.LanguageCode], doc)
 	}
 
-	result["en"] = enBulkRequest
-	result["ja"] = jaBulkRequest
+	result := make(map[string][]esclient.BulkableRequest)
+	for languageCode, docs := range byLanguage {
+		index := itemIndexes[languageCode]
+		requests, skipped, err := itemChanges(ctx, u.esClient, index, docs)
+		if err != nil {
+			u.logger.Warn("failed to get the stored items, sending all of them", slog.String("index", index), slog.Any("error", err))
+		}
+		if skipped > 0 {
+			u.logger.Info("skipped unchanged items", slog.String("index", index), slog.Int("skipped", skipped))
+		}
+		result[languageCode] = requests
+	}
 
 	return result
 }
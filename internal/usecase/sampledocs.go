@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"os"
@@ -9,7 +10,6 @@ import (
 
 	"github/shaolim/kakashi/internal/model"
 	"github/shaolim/kakashi/pkg/esclient"
-	"github/shaolim/kakashi/pkg/esclient/esquery"
 	"github/shaolim/kakashi/utils/sampler"
 )
 
@@ -23,7 +23,7 @@ func NewSampleDocsUseCase(esClient esclient.Client) *SampleDocs {
 	}
 }
 
-func (s *SampleDocs) Execute(index string, filename string) error {
+func (s *SampleDocs) Execute(ctx context.Context, index string, filename string) error {
 	file, err := os.OpenFile(filename, os.O_RDONLY, 0)
 	if err != nil {
 		return err
@@ -46,19 +46,64 @@ func (s *SampleDocs) Execute(index string, filename string) error {
 	}
 
 	sample := rs.GetSample()
-	fmt.Printf("total rows: %d, sample size: %d\n", totalRows, len(sample))
 
-	termQueries := make([]esquery.QueryType, 0, len(sample))
+	ids := make([]string, 0, len(sample))
+	expected := make(map[string]model.ItemDoc, len(sample))
 	for _, item := range sample {
-		termQueries = append(termQueries, esquery.Term("_id", item.Id))
+		doc, err := model.ConvertItemToItemDoc(*item)
+		if err != nil {
+			fmt.Printf("invalid item: %v\n", err)
+			continue
+		}
+		ids = append(ids, item.Id)
+		expected[item.Id] = doc
 	}
 
-	res, err := s.esclient.Count(index, esquery.Bool().SetShould(termQueries...))
+	fmt.Printf("total rows: %d, sample size: %d, invalid: %d\n", totalRows, len(sample), len(sample)-len(ids))
+	if len(ids) == 0 {
+		return nil
+	}
+
+	req := esclient.NewMultiGetRequest().AddIds(ids...)
+	res, err := esclient.MGet[model.ItemDoc](ctx, s.esclient, index, req,
+		esclient.DocumentWithSourceIncludes("sku", "title", "link", "price", "description", "isDeleted"))
 	if err != nil {
-		fmt.Printf("failed to count: %+v\n", err)
+		fmt.Printf("failed to mget: %+v\n", err)
 		return err
 	}
-	fmt.Printf("count: %s\n", res.String())
+
+	missing := res.Result.Missing()
+	var stale []string
+	for _, doc := range res.Result.Found() {
+		if isStale(expected[doc.Id], doc.Source) {
+			stale = append(stale, doc.Id)
+		}
+	}
+
+	fmt.Printf("found: %d, missing: %d, stale: %d\n", len(ids)-len(missing), len(missing), len(stale))
+	if len(missing) > 0 {
+		fmt.Printf("missing skus: %v\n", missing)
+	}
+	if len(stale) > 0 {
+		fmt.Printf("stale skus: %v\n", stale)
+	}
 
 	return nil
 }
+
+// isStale reports whether the indexed document differs from the csv row.
+func isStale(expected model.ItemDoc, actual *model.ItemDoc) bool {
+	if actual == nil {
+		return true
+	}
+	if expected.Title != actual.Title ||
+		expected.Link != actual.Link ||
+		expected.Description != actual.Description ||
+		expected.IsDeleted != actual.IsDeleted {
+		return true
+	}
+	if (expected.Price == nil) != (actual.Price == nil) {
+		return true
+	}
+	return expected.Price != nil && *expected.Price != *actual.Price
+}
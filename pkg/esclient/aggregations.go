@@ -0,0 +1,251 @@
+package esclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrAggregationNotFound is returned by the typed accessors of Aggregations
+// when the search has no aggregation of that name.
+var ErrAggregationNotFound = errors.New("esclient: aggregation not found")
+
+// Aggregations holds the raw results of the aggregations of a search by name.
+// The typed accessors return an error wrapping ErrAggregationNotFound when the
+// aggregation is missing, and an error when it cannot be decoded into the
+// requested result.
+type Aggregations map[string]json.RawMessage
+
+func (r *SearchResult) Aggs() Aggregations {
+	if r == nil {
+		return nil
+	}
+	return r.Aggregations
+}
+
+func decodeAggregation[T any](a Aggregations, name string) (*T, error) {
+	raw, found := a[name]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrAggregationNotFound, name)
+	}
+	var result T
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("esclient: failed to decode aggregation %s: %w", name, err)
+	}
+	return &result, nil
+}
+
+// subAggregations returns the fields of a bucket that are not part of the
+// bucket itself, which are its sub aggregations.
+func subAggregations(data []byte, bucketFields ...string) (Aggregations, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, field := range bucketFields {
+		delete(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return Aggregations(fields), nil
+}
+
+type TermsAggResult struct {
+	DocCountErrorUpperBound int64          `json:"doc_count_error_upper_bound"`
+	SumOtherDocCount        int64          `json:"sum_other_doc_count"`
+	Buckets                 []*TermsBucket `json:"buckets"`
+}
+
+type TermsBucket struct {
+	Key                     interface{}  `json:"key"`
+	KeyAsString             string       `json:"key_as_string,omitempty"`
+	DocCount                int64        `json:"doc_count"`
+	DocCountErrorUpperBound int64        `json:"doc_count_error_upper_bound,omitempty"`
+	Aggregations            Aggregations `json:"-"`
+}
+
+// KeyString returns the bucket key formatted as a string.
+func (b *TermsBucket) KeyString() string {
+	if b.KeyAsString != "" {
+		return b.KeyAsString
+	}
+	return fmt.Sprint(b.Key)
+}
+
+func (b *TermsBucket) UnmarshalJSON(data []byte) error {
+	type bucket TermsBucket
+	if err := json.Unmarshal(data, (*bucket)(b)); err != nil {
+		return err
+	}
+	aggs, err := subAggregations(data, "key", "key_as_string", "doc_count", "doc_count_error_upper_bound")
+	b.Aggregations = aggs
+	return err
+}
+
+func (a Aggregations) Terms(name string) (*TermsAggResult, error) {
+	return decodeAggregation[TermsAggResult](a, name)
+}
+
+type RangeAggResult struct {
+	Buckets []*RangeBucket `json:"buckets"`
+}
+
+type RangeBucket struct {
+	Key          string       `json:"key"`
+	From         *float64     `json:"from,omitempty"`
+	FromAsString string       `json:"from_as_string,omitempty"`
+	To           *float64     `json:"to,omitempty"`
+	ToAsString   string       `json:"to_as_string,omitempty"`
+	DocCount     int64        `json:"doc_count"`
+	Aggregations Aggregations `json:"-"`
+}
+
+func (b *RangeBucket) UnmarshalJSON(data []byte) error {
+	type bucket RangeBucket
+	if err := json.Unmarshal(data, (*bucket)(b)); err != nil {
+		return err
+	}
+	aggs, err := subAggregations(data, "key", "from", "from_as_string", "to", "to_as_string", "doc_count")
+	b.Aggregations = aggs
+	return err
+}
+
+// Range decodes range aggregations that are not keyed.
+func (a Aggregations) Range(name string) (*RangeAggResult, error) {
+	return decodeAggregation[RangeAggResult](a, name)
+}
+
+type HistogramAggResult struct {
+	Buckets []*HistogramBucket `json:"buckets"`
+}
+
+type HistogramBucket struct {
+	Key          float64      `json:"key"`
+	KeyAsString  string       `json:"key_as_string,omitempty"`
+	DocCount     int64        `json:"doc_count"`
+	Aggregations Aggregations `json:"-"`
+}
+
+func (b *HistogramBucket) UnmarshalJSON(data []byte) error {
+	type bucket HistogramBucket
+	if err := json.Unmarshal(data, (*bucket)(b)); err != nil {
+		return err
+	}
+	aggs, err := subAggregations(data, "key", "key_as_string", "doc_count")
+	b.Aggregations = aggs
+	return err
+}
+
+func (a Aggregations) Histogram(name string) (*HistogramAggResult, error) {
+	return decodeAggregation[HistogramAggResult](a, name)
+}
+
+// DateHistogram returns the buckets keyed by epoch milliseconds.
+func (a Aggregations) DateHistogram(name string) (*HistogramAggResult, error) {
+	return decodeAggregation[HistogramAggResult](a, name)
+}
+
+// SingleBucketAggResult is the result of the filter and nested aggregations.
+type SingleBucketAggResult struct {
+	DocCount     int64        `json:"doc_count"`
+	Aggregations Aggregations `json:"-"`
+}
+
+func (b *SingleBucketAggResult) UnmarshalJSON(data []byte) error {
+	type bucket SingleBucketAggResult
+	if err := json.Unmarshal(data, (*bucket)(b)); err != nil {
+		return err
+	}
+	aggs, err := subAggregations(data, "doc_count", "meta")
+	b.Aggregations = aggs
+	return err
+}
+
+func (a Aggregations) Filter(name string) (*SingleBucketAggResult, error) {
+	return decodeAggregation[SingleBucketAggResult](a, name)
+}
+
+func (a Aggregations) Nested(name string) (*SingleBucketAggResult, error) {
+	return decodeAggregation[SingleBucketAggResult](a, name)
+}
+
+type FiltersAggResult struct {
+	Buckets map[string]*SingleBucketAggResult `json:"buckets"`
+}
+
+func (a Aggregations) Filters(name string) (*FiltersAggResult, error) {
+	return decodeAggregation[FiltersAggResult](a, name)
+}
+
+// ValueAggResult is the result of single value metrics. Value is nil when no
+// document has the field.
+type ValueAggResult struct {
+	Value         *float64 `json:"value"`
+	ValueAsString string   `json:"value_as_string,omitempty"`
+}
+
+func (a Aggregations) Avg(name string) (*ValueAggResult, error) {
+	return decodeAggregation[ValueAggResult](a, name)
+}
+
+func (a Aggregations) Min(name string) (*ValueAggResult, error) {
+	return decodeAggregation[ValueAggResult](a, name)
+}
+
+func (a Aggregations) Max(name string) (*ValueAggResult, error) {
+	return decodeAggregation[ValueAggResult](a, name)
+}
+
+func (a Aggregations) Sum(name string) (*ValueAggResult, error) {
+	return decodeAggregation[ValueAggResult](a, name)
+}
+
+func (a Aggregations) Cardinality(name string) (*ValueAggResult, error) {
+	return decodeAggregation[ValueAggResult](a, name)
+}
+
+type StatsAggResult struct {
+	Count int64    `json:"count"`
+	Min   *float64 `json:"min"`
+	Max   *float64 `json:"max"`
+	Avg   *float64 `json:"avg"`
+	Sum   float64  `json:"sum"`
+}
+
+func (a Aggregations) Stats(name string) (*StatsAggResult, error) {
+	return decodeAggregation[StatsAggResult](a, name)
+}
+
+type TopHitsAggResult struct {
+	Hits *SearchHits `json:"hits"`
+}
+
+func (a Aggregations) TopHits(name string) (*TopHitsAggResult, error) {
+	return decodeAggregation[TopHitsAggResult](a, name)
+}
+
+type CompositeAggResult struct {
+	AfterKey map[string]interface{} `json:"after_key,omitempty"`
+	Buckets  []*CompositeBucket     `json:"buckets"`
+}
+
+type CompositeBucket struct {
+	Key          map[string]interface{} `json:"key"`
+	DocCount     int64                  `json:"doc_count"`
+	Aggregations Aggregations           `json:"-"`
+}
+
+func (b *CompositeBucket) UnmarshalJSON(data []byte) error {
+	type bucket CompositeBucket
+	if err := json.Unmarshal(data, (*bucket)(b)); err != nil {
+		return err
+	}
+	aggs, err := subAggregations(data, "key", "doc_count")
+	b.Aggregations = aggs
+	return err
+}
+
+func (a Aggregations) Composite(name string) (*CompositeAggResult, error) {
+	return decodeAggregation[CompositeAggResult](a, name)
+}
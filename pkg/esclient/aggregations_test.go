@@ -0,0 +1,132 @@
+package esclient_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github/shaolim/kakashi/pkg/esclient"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregationResults(t *testing.T) {
+	body := `{
+		"hits": {"total": {"value": 10, "relation": "eq"}, "hits": []},
+		"aggregations": {
+			"colors": {
+				"doc_count_error_upper_bound": 0,
+				"sum_other_doc_count": 2,
+				"buckets": [
+					{"key": "ブルー", "doc_count": 5, "avg_price": {"value": 1250.5}},
+					{"key": "レッド", "doc_count": 3, "avg_price": {"value": null}}
+				]
+			},
+			"prices": {
+				"buckets": [
+					{"key": "*-100.0", "to": 100, "doc_count": 4},
+					{"key": "100.0-*", "from": 100, "doc_count": 6}
+				]
+			},
+			"monthly": {
+				"buckets": [
+					{"key_as_string": "2024-01", "key": 1704067200000, "doc_count": 7}
+				]
+			},
+			"japanese": {
+				"doc_count": 8,
+				"conditions": {"buckets": [{"key": "new", "doc_count": 8}]}
+			},
+			"conditions": {
+				"buckets": {
+					"new": {"doc_count": 6},
+					"other": {"doc_count": 4}
+				}
+			},
+			"skus": {"value": 10},
+			"price_stats": {"count": 10, "min": 10, "max": 5000, "avg": 800, "sum": 8000},
+			"latest": {
+				"hits": {
+					"total": {"value": 10, "relation": "eq"},
+					"hits": [{"_index": "item_index_ja", "_id": "sku-1", "_source": {"sku": "sku-1"}}]
+				}
+			},
+			"pages": {
+				"after_key": {"color": "レッド"},
+				"buckets": [{"key": {"color": "レッド"}, "doc_count": 3}]
+			}
+		}
+	}`
+
+	var result esclient.SearchResult
+	assert.NoError(t, json.Unmarshal([]byte(body), &result))
+	aggs := result.Aggs()
+
+	colors, err := aggs.Terms("colors")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), colors.SumOtherDocCount)
+	assert.Len(t, colors.Buckets, 2)
+	assert.Equal(t, "ブルー", colors.Buckets[0].KeyString())
+	assert.Equal(t, int64(5), colors.Buckets[0].DocCount)
+	avgPrice, err := colors.Buckets[0].Aggregations.Avg("avg_price")
+	assert.NoError(t, err)
+	assert.Equal(t, 1250.5, *avgPrice.Value)
+	avgPrice, err = colors.Buckets[1].Aggregations.Avg("avg_price")
+	assert.NoError(t, err)
+	assert.Nil(t, avgPrice.Value)
+
+	prices, err := aggs.Range("prices")
+	assert.NoError(t, err)
+	assert.Len(t, prices.Buckets, 2)
+	assert.Nil(t, prices.Buckets[0].From)
+	assert.Equal(t, 100.0, *prices.Buckets[0].To)
+
+	monthly, err := aggs.DateHistogram("monthly")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01", monthly.Buckets[0].KeyAsString)
+	assert.Equal(t, float64(1704067200000), monthly.Buckets[0].Key)
+
+	japanese, err := aggs.Filter("japanese")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), japanese.DocCount)
+	conditions, err := japanese.Aggregations.Terms("conditions")
+	assert.NoError(t, err)
+	assert.Equal(t, "new", conditions.Buckets[0].KeyString())
+
+	filters, err := aggs.Filters("conditions")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), filters.Buckets["other"].DocCount)
+	skus, err := aggs.Cardinality("skus")
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, *skus.Value)
+
+	stats, err := aggs.Stats("price_stats")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), stats.Count)
+	assert.Equal(t, 5000.0, *stats.Max)
+
+	latest, err := aggs.TopHits("latest")
+	assert.NoError(t, err)
+	assert.Equal(t, "sku-1", latest.Hits.Hits[0].Id)
+
+	pages, err := aggs.Composite("pages")
+	assert.NoError(t, err)
+	assert.Equal(t, "レッド", pages.AfterKey["color"])
+	assert.Equal(t, int64(3), pages.Buckets[0].DocCount)
+
+	missing, err := aggs.Terms("missing")
+	assert.True(t, errors.Is(err, esclient.ErrAggregationNotFound))
+	assert.EqualError(t, err, "esclient: aggregation not found: missing")
+	assert.Nil(t, missing)
+
+	var empty esclient.Aggregations
+	_, err = empty.Avg("avg_price")
+	assert.True(t, errors.Is(err, esclient.ErrAggregationNotFound))
+}
+
+func TestAggregationsDecodeError(t *testing.T) {
+	aggs := esclient.Aggregations{"colors": json.RawMessage(`{"buckets":{"blue":{"doc_count":5}}}`)}
+
+	colors, err := aggs.Terms("colors")
+	assert.ErrorContains(t, err, "esclient: failed to decode aggregation colors")
+	assert.Nil(t, colors)
+}
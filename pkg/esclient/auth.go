@@ -0,0 +1,111 @@
+package esclient
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Authenticator applies credentials to every request sent by the client.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface.
+type AuthenticatorFunc func(req *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+type basicAuth struct {
+	username string
+	password string
+}
+
+func (a *basicAuth) Authenticate(req *http.Request) error {
+	if a.username != "" && a.password != "" {
+		req.SetBasicAuth(a.username, a.password)
+	}
+	return nil
+}
+
+type headerAuth struct {
+	scheme string
+	token  string
+}
+
+func (a *headerAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", a.scheme+" "+a.token)
+	return nil
+}
+
+func WithAuthenticator(auth Authenticator) ClientOption {
+	return func(c *client) {
+		c.auth = auth
+	}
+}
+
+func WithBasicAuth(username, password string) ClientOption {
+	return WithAuthenticator(&basicAuth{username: username, password: password})
+}
+
+// WithApiKey authenticates with the id and the key returned by the create API key API.
+func WithApiKey(id, key string) ClientOption {
+	return WithEncodedApiKey(base64.StdEncoding.EncodeToString([]byte(id + ":" + key)))
+}
+
+// WithEncodedApiKey authenticates with the base64 "encoded" value returned by the create API key API.
+func WithEncodedApiKey(encoded string) ClientOption {
+	return WithAuthenticator(&headerAuth{scheme: "ApiKey", token: encoded})
+}
+
+// WithBearerToken authenticates with an OAuth2 access token from the get token API.
+func WithBearerToken(token string) ClientOption {
+	return WithAuthenticator(&headerAuth{scheme: "Bearer", token: token})
+}
+
+// WithServiceToken authenticates as a service account, e.g. elastic/fleet-server.
+func WithServiceToken(token string) ClientOption {
+	return WithAuthenticator(&headerAuth{scheme: "Bearer", token: token})
+}
+
+// WithCloudID connects to an Elastic Cloud deployment, replacing the base url
+// given to NewClient.
+func WithCloudID(cloudID string) ClientOption {
+	return func(c *client) {
+		u, err := DecodeCloudID(cloudID)
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.urls = []string{u}
+	}
+}
+
+// DecodeCloudID returns the elasticsearch url of an Elastic Cloud ID, which
+// has the form "name:base64(host$elasticsearch-uuid$kibana-uuid)".
+func DecodeCloudID(cloudID string) (string, error) {
+	encoded := cloudID
+	if i := strings.LastIndex(cloudID, ":"); i >= 0 {
+		encoded = cloudID[i+1:]
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("esclient: invalid cloud id: %w", err)
+	}
+
+	parts := strings.Split(string(data), "$")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", errors.New("esclient: invalid cloud id: missing host or elasticsearch uuid")
+	}
+
+	host, port, found := strings.Cut(parts[0], ":")
+	if found && port != "443" {
+		return fmt.Sprintf("https://%s.%s:%s", parts[1], host, port), nil
+	}
+	return fmt.Sprintf("https://%s.%s", parts[1], host), nil
+}
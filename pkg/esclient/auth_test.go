@@ -0,0 +1,227 @@
+package esclient_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"github/shaolim/kakashi/pkg/esclient"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientAuthentication(t *testing.T) {
+	tests := []struct {
+		name     string
+		option   esclient.ClientOption
+		expected string
+	}{
+		{name: "basic", option: esclient.WithBasicAuth("elastic", "changeme"),
+			expected: "Basic " + base64.StdEncoding.EncodeToString([]byte("elastic:changeme"))},
+		{name: "api key", option: esclient.WithApiKey("VuaCfGcBCdbkQm-e5aOx", "ui2lp2axTNmsyakw9tvNnw"),
+			expected: "ApiKey VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw=="},
+		{name: "encoded api key", option: esclient.WithEncodedApiKey("c2VjcmV0"), expected: "ApiKey c2VjcmV0"},
+		{name: "bearer token", option: esclient.WithBearerToken("dGhpcyBpcyBub3Q"), expected: "Bearer dGhpcyBpcyBub3Q"},
+		{name: "service token", option: esclient.WithServiceToken("AAEAAWVsYXN0aWM"), expected: "Bearer AAEAAWVsYXN0aWM"},
+		{name: "custom", option: esclient.WithAuthenticator(esclient.AuthenticatorFunc(func(req *http.Request) error {
+			req.Header.Set("Authorization", "Custom token")
+			return nil
+		})), expected: "Custom token"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actual string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actual = r.Header.Get("Authorization")
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			client := esclient.NewClient(server.URL, test.option)
+			defer client.Close()
+
+			_, err := client.Ping(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestDecodeCloudID(t *testing.T) {
+	tests := []struct {
+		cloudID  string
+		expected string
+		isError  bool
+	}{
+		{
+			cloudID:  "my-deployment:" + base64.StdEncoding.EncodeToString([]byte("us-east-1.aws.found.io$abc123$def456")),
+			expected: "https://abc123.us-east-1.aws.found.io",
+		},
+		{
+			cloudID:  "my-deployment:" + base64.StdEncoding.EncodeToString([]byte("us-east-1.aws.found.io:9243$abc123$def456")),
+			expected: "https://abc123.us-east-1.aws.found.io:9243",
+		},
+		{cloudID: "my-deployment:not base64", isError: true},
+		{cloudID: "my-deployment:" + base64.StdEncoding.EncodeToString([]byte("us-east-1.aws.found.io")), isError: true},
+	}
+
+	for _, test := range tests {
+		actual, err := esclient.DecodeCloudID(test.cloudID)
+		if test.isError {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, actual)
+	}
+}
+
+func TestClientTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	sum := sha256.Sum256(server.Certificate().Raw)
+
+	tests := []struct {
+		name    string
+		options []esclient.ClientOption
+		isError bool
+	}{
+		{name: "untrusted", isError: true},
+		{name: "ca cert", options: []esclient.ClientOption{esclient.WithCACert(caCert)}},
+		{name: "fingerprint", options: []esclient.ClientOption{esclient.WithCertificateFingerprint(hex.EncodeToString(sum[:]))}},
+		{name: "wrong fingerprint", options: []esclient.ClientOption{esclient.WithCertificateFingerprint(hex.EncodeToString(make([]byte, 32)))}, isError: true},
+		{name: "invalid ca cert", options: []esclient.ClientOption{esclient.WithCACert([]byte("garbage"))}, isError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := esclient.NewClient(server.URL, test.options...)
+			defer client.Close()
+
+			_, err := client.Ping(context.Background())
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClientTLSFingerprintChain(t *testing.T) {
+	ca, caKey := newTestCertificate(t, "pinned ca", nil, nil, nil)
+	otherCa, _ := newTestCertificate(t, "other ca", nil, nil, nil)
+	leaf, leafKey := newTestCertificate(t, "leaf", []net.IP{net.IPv4(127, 0, 0, 1)}, ca, caKey)
+	rogue, rogueKey := newTestCertificate(t, "rogue", []net.IP{net.IPv4(127, 0, 0, 1)}, nil, nil)
+	otherHost, otherHostKey := newTestCertificate(t, "other host", []net.IP{net.IPv4(10, 0, 0, 1)}, ca, caKey)
+
+	sum := sha256.Sum256(ca.Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name    string
+		chain   []*x509.Certificate
+		key     *ecdsa.PrivateKey
+		options []esclient.ClientOption
+		isError bool
+	}{
+		{name: "leaf signed by the pinned ca", chain: []*x509.Certificate{leaf, ca}, key: leafKey},
+		{name: "wrong leaf with the pinned ca", chain: []*x509.Certificate{rogue, ca}, key: rogueKey, isError: true},
+		{name: "leaf for another host", chain: []*x509.Certificate{otherHost, ca}, key: otherHostKey, isError: true},
+		{
+			name:    "pinned ca not trusted by the ca cert",
+			chain:   []*x509.Certificate{leaf, ca},
+			key:     leafKey,
+			options: []esclient.ClientOption{esclient.WithCACert(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: otherCa.Raw}))},
+			isError: true,
+		},
+		{
+			name:    "pinned ca trusted by the ca cert",
+			chain:   []*x509.Certificate{leaf},
+			key:     leafKey,
+			options: []esclient.ClientOption{esclient.WithCACert(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}))},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{}`))
+			}))
+			cert := tls.Certificate{PrivateKey: test.key}
+			for _, c := range test.chain {
+				cert.Certificate = append(cert.Certificate, c.Raw)
+			}
+			server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+			server.StartTLS()
+			defer server.Close()
+
+			options := append([]esclient.ClientOption{esclient.WithCertificateFingerprint(fingerprint)}, test.options...)
+			client := esclient.NewClient(server.URL, options...)
+			defer client.Close()
+
+			_, err := client.Ping(context.Background())
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// newTestCertificate creates a CA certificate when ips is nil, a server
+// certificate otherwise. It is self-signed when parent is nil.
+func newTestCertificate(t *testing.T, name string, ips []net.IP, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  ips,
+	}
+	if ips == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
@@ -1,38 +1,156 @@
 package esclient
 
 import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
 	"net/http"
-	"strings"
+	"net/url"
+	"sync"
 )
 
 type Bulk interface {
-	Bulk(index string, bulkRequest BulkableRequest) (*Response[BulkResult], error)
+	Bulk(ctx context.Context, index string, bulkRequest BulkableRequest, options ...bulkOptions) (*Response[BulkResult], error)
 }
 
-func (c *client) Bulk(index string, bulkRequest BulkableRequest) (*Response[BulkResult], error) {
-	r, err := bulkRequest.String()
-	if err != nil {
-		return nil, err
+type bulkOptions func(url.Values)
+
+func BulkWithRefresh(refresh Refresh) bulkOptions {
+	return func(q url.Values) {
+		q.Set("refresh", string(refresh))
 	}
-	req, err := http.NewRequest("POST", c.baseUrl+"/"+index+"/_bulk", strings.NewReader(r))
-	if err != nil {
-		return nil, err
+}
+
+// BulkWithWaitForActiveShards waits for the number of active shard copies,
+// e.g. "all" or "2", before running the actions.
+func BulkWithWaitForActiveShards(activeShards string) bulkOptions {
+	return func(q url.Values) {
+		q.Set("wait_for_active_shards", activeShards)
 	}
+}
 
-	req.Header.Set("Content-Type", "application/x-ndjson")
+// BulkWithPipeline is the ingest pipeline of the actions that do not set one.
+func BulkWithPipeline(pipeline string) bulkOptions {
+	return func(q url.Values) {
+		q.Set("pipeline", pipeline)
+	}
+}
+
+// BulkWithRouting is the routing of the actions that do not set one.
+func BulkWithRouting(routing string) bulkOptions {
+	return func(q url.Values) {
+		q.Set("routing", routing)
+	}
+}
+
+func BulkWithTimeout(timeout string) bulkOptions {
+	return func(q url.Values) {
+		q.Set("timeout", timeout)
+	}
+}
+
+// WithBulkGzip compresses the body of bulk requests with gzip, which trades
+// some CPU for a payload several times smaller.
+func WithBulkGzip() ClientOption {
+	return func(c *client) {
+		c.bulkGzip = true
+	}
+}
+
+var bulkBufferPool = sync.Pool{
+	New: func() interface{} {
+		return bufio.NewWriterSize(nil, 32<<10)
+	},
+}
 
-	res, err := c.do(req)
+var bulkGzipPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// Bulk streams the NDJSON of bulkRequest into the request body as it is
+// encoded. A retried request encodes it again. Without an index, every action
+// must set its own.
+func (c *client) Bulk(ctx context.Context, index string, bulkRequest BulkableRequest, options ...bulkOptions) (*Response[BulkResult], error) {
+	var mu sync.Mutex
+	var bodies []*io.PipeReader
+	newBody := func() (io.ReadCloser, error) {
+		r, w := io.Pipe()
+		mu.Lock()
+		bodies = append(bodies, r)
+		mu.Unlock()
+		go func() {
+			if err := c.writeBulkBody(w, bulkRequest); err != nil {
+				w.CloseWithError(&bodyError{err})
+				return
+			}
+			w.Close()
+		}()
+		return r, nil
+	}
+	// the writers block until their body is read or closed
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, body := range bodies {
+			body.Close()
+		}
+	}()
+
+	q := url.Values{}
+	for _, option := range options {
+		option(q)
+	}
+	uri := "/_bulk"
+	if index != "" {
+		uri = "/" + index + uri
+	}
+	if len(q) > 0 {
+		uri += "?" + q.Encode()
+	}
+
+	body, _ := newBody()
+	req, err := http.NewRequestWithContext(ctx, "POST", uri, body)
 	if err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
+	req.GetBody = newBody
 
-	response := &Response[BulkResult]{
-		StatusCode: res.StatusCode,
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if c.bulkGzip {
+		req.Header.Set("Content-Encoding", "gzip")
 	}
-	response.SetBody(res.Body)
 
-	return response, nil
+	return execute[BulkResult](c, req)
+}
+
+func (c *client) writeBulkBody(w io.Writer, bulkRequest BulkableRequest) error {
+	if c.bulkGzip {
+		gz := bulkGzipPool.Get().(*gzip.Writer)
+		defer bulkGzipPool.Put(gz)
+		gz.Reset(w)
+		defer gz.Reset(nil)
+		if err := writeBulkBuffered(gz, bulkRequest); err != nil {
+			return err
+		}
+		return gz.Close()
+	}
+	return writeBulkBuffered(w, bulkRequest)
+}
+
+// writeBulkBuffered batches the small writes of the encoders into chunks.
+func writeBulkBuffered(w io.Writer, bulkRequest BulkableRequest) error {
+	buf := bulkBufferPool.Get().(*bufio.Writer)
+	defer bulkBufferPool.Put(buf)
+	buf.Reset(w)
+	defer buf.Reset(nil)
+
+	if err := writeBulk(buf, bulkRequest); err != nil {
+		return err
+	}
+	return buf.Flush()
 }
 
 type BulkResult struct {
@@ -47,9 +165,11 @@ type BulkResponseItem struct {
 	Version     int64         `json:"_version,omitempty"`
 	Result      string        `json:"result,omitempty"`
 	Shards      *ShardsInfo   `json:"_shards,omitempty"`
+	SeqNo       int64         `json:"_seq_no,omitempty"`
 	PrimaryTerm int64         `json:"_primary_term,omitempty"`
 	Status      int           `json:"status,omitempty"`
 	Error       *ErrorDetails `json:"error,omitempty"`
+	Get         *GetResult    `json:"get,omitempty"` // only returned by updates asking for _source
 }
 
 func (r *BulkResult) Indexed() []*BulkResponseItem {
@@ -132,11 +252,3 @@ type ShardsInfo struct {
 	Failed     int `json:"failed"`
 	Skipped    int `json:"skipped"`
 }
-
-type ErrorDetails struct {
-	Type      string `json:"type"`
-	Reason    string `json:"reason"`
-	IndexUUID string `json:"index_uuid"`
-	Index     string `json:"index"`
-	Shard     int    `json:"shard"`
-}
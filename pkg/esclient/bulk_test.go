@@ -0,0 +1,237 @@
+package esclient_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github/shaolim/kakashi/pkg/esclient"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkGzip(t *testing.T) {
+	var encoding string
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		gz, err := gzip.NewReader(r.Body)
+		if assert.NoError(t, err) {
+			data, err := io.ReadAll(gz)
+			assert.NoError(t, err)
+			body = string(data)
+		}
+		w.Write([]byte(`{"took":1,"errors":false}`))
+	}))
+	defer server.Close()
+
+	client := esclient.NewClient(server.URL, esclient.WithBulkGzip())
+	defer client.Close()
+
+	req := newBenchmarkBulkRequests(3)
+	res, err := client.Bulk(context.Background(), "products", req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	expected, err := req.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "gzip", encoding)
+	assert.Equal(t, expected, body)
+}
+
+func TestBulkEncodeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{"took":1,"errors":false}`))
+	}))
+	defer server.Close()
+
+	client := esclient.NewClient(server.URL, esclient.WithRetry(3))
+	defer client.Close()
+
+	req := &esclient.BulkRequests{}
+	req.Add(esclient.NewBulkIndexRequest().SetId("1").SetDoc(map[string]interface{}{"name": func() {}}))
+	_, err := client.Bulk(context.Background(), "products", req)
+
+	var unsupported *json.UnsupportedTypeError
+	assert.ErrorAs(t, err, &unsupported)
+
+	// the node is still used for the next request
+	res, err := client.Bulk(context.Background(), "products", newBenchmarkBulkRequests(1))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestBulkUrlParams(t *testing.T) {
+	server, recorded := newRecordingServer(t, http.StatusOK, `{"took":1,"errors":false}`)
+	defer server.Close()
+
+	client := esclient.NewClient(server.URL)
+	defer client.Close()
+
+	_, err := client.Bulk(context.Background(), "products", newBenchmarkBulkRequests(1),
+		esclient.BulkWithRefresh(esclient.RefreshWaitFor),
+		esclient.BulkWithWaitForActiveShards("all"),
+		esclient.BulkWithPipeline("prices"),
+		esclient.BulkWithRouting("shop-1"),
+		esclient.BulkWithTimeout("30s"))
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", recorded.method)
+	assert.Equal(t, "/products/_bulk?pipeline=prices&refresh=wait_for&routing=shop-1&timeout=30s&wait_for_active_shards=all", recorded.uri)
+
+	_, err = client.Bulk(context.Background(), "", newBenchmarkBulkRequests(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "/_bulk", recorded.uri)
+}
+
+type benchmarkProduct struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Price       float64  `json:"price"`
+	Tags        []string `json:"tags"`
+}
+
+func newBenchmarkProduct(i int) benchmarkProduct {
+	return benchmarkProduct{
+		Name:        fmt.Sprintf("Laptop %d", i),
+		Description: "A thin and light laptop with a 14 inch display and a long battery life",
+		Price:       1299.99,
+		Tags:        []string{"electronics", "computers", "laptops"},
+	}
+}
+
+func newBenchmarkBulkRequests(n int) *esclient.BulkRequests {
+	req := &esclient.BulkRequests{}
+	for i := 0; i < n; i++ {
+		req.Add(esclient.NewBulkIndexRequest().SetId(fmt.Sprint(i)).SetDoc(newBenchmarkProduct(i)))
+	}
+	return req
+}
+
+// legacyBulkIndexRequest is a copy of the index action encoder used before the
+// bulk body was streamed, the baseline of the benchmarks.
+type legacyBulkIndexRequest struct {
+	Index         string      `json:"_index,omitempty"`
+	Id            string      `json:"_id"`
+	Routing       string      `json:"routing,omitempty"`
+	Pipeline      string      `json:"pipeline,omitempty"`
+	IfSeqNo       int64       `json:"if_seq_no,omitempty"`
+	IfPrimaryTerm int64       `json:"if_primary_term,omitempty"`
+	Doc           interface{} `json:"-"`
+}
+
+func (b *legacyBulkIndexRequest) String() (string, error) {
+	p, err := json.Marshal(b)
+	if err != nil {
+		return "", err
+	}
+	action := fmt.Sprintf("{\"index\":%v}", string(p))
+
+	doc := "{}"
+	if b.Doc != nil {
+		_docs, err := json.Marshal(b.Doc)
+		if err != nil {
+			return "", err
+		}
+		doc = string(_docs)
+	}
+
+	return fmt.Sprintf("%s\n%s\n", action, doc), nil
+}
+
+func legacyBulkString(requests []*legacyBulkIndexRequest) (string, error) {
+	var sb strings.Builder
+	for _, bulkRequest := range requests {
+		str, err := bulkRequest.String()
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(str)
+	}
+	return sb.String(), nil
+}
+
+// BenchmarkBulkRequestLegacy builds the body into a string and reads it with
+// a strings.Reader, as the body was sent before it was streamed.
+func BenchmarkBulkRequestLegacy(b *testing.B) {
+	requests := make([]*legacyBulkIndexRequest, 100)
+	for i := range requests {
+		requests[i] = &legacyBulkIndexRequest{Id: fmt.Sprint(i), Doc: newBenchmarkProduct(i)}
+	}
+	expected, err := newBenchmarkBulkRequests(len(requests)).String()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if actual, err := legacyBulkString(requests); err != nil || actual != expected {
+		b.Fatalf("the legacy encoder does not match the bulk body: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		body, err := legacyBulkString(requests)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, strings.NewReader(body)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkBulkRequestString encodes the batch into a string with the
+// streaming encoder, for the callers of String.
+func BenchmarkBulkRequestString(b *testing.B) {
+	req := newBenchmarkBulkRequests(100)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := req.String(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBulkRequestWriteBulk(b *testing.B) {
+	req := newBenchmarkBulkRequests(100)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := req.WriteBulk(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBulk(b *testing.B) {
+	for _, bc := range []struct {
+		name    string
+		options []esclient.ClientOption
+	}{
+		{name: "plain"},
+		{name: "gzip", options: []esclient.ClientOption{esclient.WithBulkGzip()}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				w.Write([]byte(`{"took":1,"errors":false}`))
+			}))
+			defer server.Close()
+
+			client := esclient.NewClient(server.URL, bc.options...)
+			defer client.Close()
+
+			req := newBenchmarkBulkRequests(100)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := client.Bulk(context.Background(), "products", req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,515 @@
+package esclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBulkProcessorWorkers = 1
+	defaultBulkProcessorActions = 1000
+	defaultBulkProcessorSize    = 5 << 20 // 5MB
+
+	// deadLetterTimeout bounds the write of a dead letter, which outlives
+	// the processor context so that aborted actions are not lost.
+	deadLetterTimeout = 30 * time.Second
+)
+
+var ErrBulkProcessorClosed = errors.New("esclient: bulk processor is closed")
+
+// BulkProcessor batches bulk requests of one index and sends them from a pool
+// of workers. Each worker sends its batch when it reaches the maximum number
+// of actions or bytes, when the flush interval elapses and on Flush and Close.
+// Add blocks while every worker is busy sending, which slows down producers
+// that are faster than the cluster.
+type BulkProcessor struct {
+	client        Bulk
+	index         string
+	workers       int
+	bulkActions   int
+	bulkSize      int
+	flushInterval time.Duration
+	bulkOptions   []bulkOptions
+	retry         *retryPolicy
+	conflicts     VersionConflictPolicy
+	deadLetter    DeadLetterSink
+	onSuccess     func(request BulkableRequest, item *BulkResponseItem)
+	onFailure     func(request BulkableRequest, item *BulkResponseItem, err error)
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	requests  chan *bulkProcessorItem
+	flushes   []chan chan struct{}
+	closing   chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+
+	deadLetterMu   sync.Mutex
+	deadLetterErrs []error
+
+	indexed      atomic.Int64
+	failed       atomic.Int64
+	retried      atomic.Int64
+	conflicted   atomic.Int64
+	deadLettered atomic.Int64
+	bytesSent    atomic.Int64
+	flushed      atomic.Int64
+}
+
+type BulkProcessorOption func(*BulkProcessor)
+
+// BulkProcessorWithWorkers sets the number of batches sent concurrently.
+func BulkProcessorWithWorkers(workers int) BulkProcessorOption {
+	return func(p *BulkProcessor) {
+		p.workers = workers
+	}
+}
+
+// BulkProcessorWithBulkActions sets the number of actions after which a batch
+// is sent, 1000 by default. A value below 1 disables the limit.
+func BulkProcessorWithBulkActions(actions int) BulkProcessorOption {
+	return func(p *BulkProcessor) {
+		p.bulkActions = actions
+	}
+}
+
+// BulkProcessorWithBulkSize sets the payload size in bytes after which a batch
+// is sent, 5MB by default. A value below 1 disables the limit.
+func BulkProcessorWithBulkSize(size int) BulkProcessorOption {
+	return func(p *BulkProcessor) {
+		p.bulkSize = size
+	}
+}
+
+// BulkProcessorWithFlushInterval sends the pending batches periodically, so
+// that requests do not wait for the batch to fill up.
+func BulkProcessorWithFlushInterval(interval time.Duration) BulkProcessorOption {
+	return func(p *BulkProcessor) {
+		p.flushInterval = interval
+	}
+}
+
+// BulkProcessorWithBulkOptions sets the url parameters of every bulk request,
+// e.g. BulkWithPipeline.
+func BulkProcessorWithBulkOptions(options ...bulkOptions) BulkProcessorOption {
+	return func(p *BulkProcessor) {
+		p.bulkOptions = append(p.bulkOptions, options...)
+	}
+}
+
+// BulkProcessorWithRetry resends a batch rejected as a whole with a 429, 502,
+// 503 or 504 status, and the actions of a batch rejected with a 429 or 503
+// status, up to maxAttempts attempts in total.
+func BulkProcessorWithRetry(maxAttempts int, options ...RetryOption) BulkProcessorOption {
+	return func(p *BulkProcessor) {
+		retry := &retryPolicy{
+			maxAttempts:    maxAttempts,
+			maxElapsed:     defaultRetryMaxElapsed,
+			initialBackoff: defaultRetryInitialBackoff,
+			maxBackoff:     defaultRetryMaxBackoff,
+		}
+		for _, option := range options {
+			option(retry)
+		}
+		p.retry = retry
+	}
+}
+
+// VersionConflictPolicy decides what happens to an action rejected with a
+// version_conflict_engine_exception, e.g. a create of an existing document or
+// an index with an outdated if_seq_no or external version.
+type VersionConflictPolicy int
+
+const (
+	// VersionConflictFail reports the action as failed.
+	VersionConflictFail VersionConflictPolicy = iota
+	// VersionConflictIgnore drops the action, as the document already holds a
+	// newer version. It is counted as a conflict only.
+	VersionConflictIgnore
+	// VersionConflictRetry resends the action with the retry policy, which
+	// suits updates that do not carry a version.
+	VersionConflictRetry
+)
+
+func BulkProcessorWithVersionConflictPolicy(policy VersionConflictPolicy) BulkProcessorOption {
+	return func(p *BulkProcessor) {
+		p.conflicts = policy
+	}
+}
+
+// BulkProcessorWithDeadLetterSink writes the actions that failed for good to
+// sink, so that they can be inspected and replayed.
+func BulkProcessorWithDeadLetterSink(sink DeadLetterSink) BulkProcessorOption {
+	return func(p *BulkProcessor) {
+		p.deadLetter = sink
+	}
+}
+
+// BulkProcessorWithOnSuccess is called with every action that succeeded.
+func BulkProcessorWithOnSuccess(onSuccess func(request BulkableRequest, item *BulkResponseItem)) BulkProcessorOption {
+	return func(p *BulkProcessor) {
+		p.onSuccess = onSuccess
+	}
+}
+
+// BulkProcessorWithOnFailure is called with every action that failed. When the
+// whole bulk request failed, err is the error of the request and item only
+// holds its status and reason.
+func BulkProcessorWithOnFailure(onFailure func(request BulkableRequest, item *BulkResponseItem, err error)) BulkProcessorOption {
+	return func(p *BulkProcessor) {
+		p.onFailure = onFailure
+	}
+}
+
+// NewBulkProcessor starts the workers sending the requests added to index.
+// Cancelling ctx aborts the requests being sent; Close must still be called.
+func NewBulkProcessor(ctx context.Context, client Bulk, index string, options ...BulkProcessorOption) *BulkProcessor {
+	p := &BulkProcessor{
+		client:      client,
+		index:       index,
+		workers:     defaultBulkProcessorWorkers,
+		bulkActions: defaultBulkProcessorActions,
+		bulkSize:    defaultBulkProcessorSize,
+		requests:    make(chan *bulkProcessorItem),
+		closing:     make(chan struct{}),
+	}
+	for _, option := range options {
+		option(p)
+	}
+	if p.workers < 1 {
+		p.workers = 1
+	}
+
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	p.flushes = make([]chan chan struct{}, p.workers)
+	for i := range p.flushes {
+		p.flushes[i] = make(chan chan struct{})
+		p.wg.Add(1)
+		go func(flushes <-chan chan struct{}) {
+			defer p.wg.Done()
+			p.work(flushes)
+		}(p.flushes[i])
+	}
+
+	return p
+}
+
+type bulkProcessorItem struct {
+	request  BulkableRequest
+	body     string
+	response *BulkResponseItem // of the last attempt
+}
+
+// bulkProcessorBatch sends the bodies encoded when the requests were added.
+type bulkProcessorBatch []*bulkProcessorItem
+
+func (b bulkProcessorBatch) String() (string, error) {
+	var sb strings.Builder
+	sb.Grow(b.size())
+	for _, item := range b {
+		sb.WriteString(item.body)
+	}
+	return sb.String(), nil
+}
+
+func (b bulkProcessorBatch) WriteBulk(w io.Writer) error {
+	for _, item := range b {
+		if _, err := io.WriteString(w, item.body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b bulkProcessorBatch) size() int {
+	size := 0
+	for _, item := range b {
+		size += len(item.body)
+	}
+	return size
+}
+
+// Add encodes request and hands it to a worker. It blocks until a worker is
+// free, ctx is done or the processor is closed.
+func (p *BulkProcessor) Add(ctx context.Context, request BulkableRequest) error {
+	body, err := request.String()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case p.requests <- &bulkProcessorItem{request: request, body: body}:
+		return nil
+	case <-p.closing:
+		return ErrBulkProcessorClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush sends the pending batches of every worker and waits for the responses.
+func (p *BulkProcessor) Flush(ctx context.Context) error {
+	done := make([]chan struct{}, 0, len(p.flushes))
+	for _, flushes := range p.flushes {
+		flushed := make(chan struct{})
+		select {
+		case flushes <- flushed:
+			done = append(done, flushed)
+		case <-p.closing:
+			return ErrBulkProcessorClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for _, flushed := range done {
+		select {
+		case <-flushed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Close stops accepting requests, sends the pending batches and waits for the
+// workers. When ctx is done first, the requests being sent are aborted and
+// Close still waits for their actions to be written to the dead-letter sink.
+// The errors of the dead-letter sink are returned once the workers stopped.
+func (p *BulkProcessor) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		close(p.closing)
+	})
+
+	stopped := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(stopped)
+	}()
+
+	var err error
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	p.cancel()
+	<-stopped
+
+	p.deadLetterMu.Lock()
+	defer p.deadLetterMu.Unlock()
+	return errors.Join(append([]error{err}, p.deadLetterErrs...)...)
+}
+
+type BulkProcessorStats struct {
+	Flushed      int64 // bulk requests sent, retries excluded
+	Indexed      int64 // actions that succeeded
+	Failed       int64 // actions that failed
+	Retried      int64 // actions sent again
+	Conflicted   int64 // actions rejected with a version conflict, whatever the policy
+	DeadLettered int64 // failed actions written to the dead-letter sink
+	BytesSent    int64 // payload bytes, retries included
+}
+
+func (p *BulkProcessor) Stats() BulkProcessorStats {
+	return BulkProcessorStats{
+		Flushed:      p.flushed.Load(),
+		Indexed:      p.indexed.Load(),
+		Failed:       p.failed.Load(),
+		Retried:      p.retried.Load(),
+		Conflicted:   p.conflicted.Load(),
+		DeadLettered: p.deadLettered.Load(),
+		BytesSent:    p.bytesSent.Load(),
+	}
+}
+
+func (p *BulkProcessor) work(flushes <-chan chan struct{}) {
+	var batch bulkProcessorBatch
+	size := 0
+	flush := func() {
+		if len(batch) > 0 {
+			p.send(batch)
+		}
+		batch = nil
+		size = 0
+	}
+
+	var tick <-chan time.Time
+	if p.flushInterval > 0 {
+		ticker := time.NewTicker(p.flushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case item := <-p.requests:
+			if p.bulkSize > 0 && len(batch) > 0 && size+len(item.body) > p.bulkSize {
+				flush()
+			}
+			batch = append(batch, item)
+			size += len(item.body)
+			if (p.bulkActions > 0 && len(batch) >= p.bulkActions) || (p.bulkSize > 0 && size >= p.bulkSize) {
+				flush()
+			}
+		case <-tick:
+			flush()
+		case flushed := <-flushes:
+			flush()
+			close(flushed)
+		case <-p.closing:
+			flush()
+			return
+		}
+	}
+}
+
+func (p *BulkProcessor) send(batch bulkProcessorBatch) {
+	p.flushed.Add(1)
+
+	start := time.Now()
+	for attempt := 1; len(batch) > 0; attempt++ {
+		p.bytesSent.Add(int64(batch.size()))
+		res, err := p.client.Bulk(p.ctx, p.index, batch, p.bulkOptions...)
+		if err != nil {
+			if e, ok := asError(err); ok && isRetryableStatus(e.Status) && p.wait(attempt, start) {
+				p.retried.Add(int64(len(batch)))
+				continue
+			}
+			p.reportError(batch, err, attempt)
+			return
+		}
+
+		batch = p.report(batch, res.Result, attempt)
+		if len(batch) > 0 && !p.wait(attempt, start) {
+			for _, item := range batch {
+				p.failure(item, item.response, nil, attempt)
+			}
+			return
+		}
+		p.retried.Add(int64(len(batch)))
+	}
+}
+
+// wait waits before the next attempt and reports whether to make it.
+func (p *BulkProcessor) wait(attempt int, start time.Time) bool {
+	if p.retry == nil || attempt >= p.retry.maxAttempts {
+		return false
+	}
+
+	wait := p.retry.backoff(attempt)
+	if p.retry.maxElapsed > 0 && time.Since(start)+wait > p.retry.maxElapsed {
+		return false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-p.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// isRetryableItemStatus reports the statuses of the actions rejected because
+// the cluster is overloaded, which succeed when sent again later.
+func isRetryableItemStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+func isVersionConflict(item *BulkResponseItem) bool {
+	return item.Status == http.StatusConflict && item.Error != nil && item.Error.Type == "version_conflict_engine_exception"
+}
+
+// report calls the callbacks for the actions of batch and returns the actions
+// to send again.
+func (p *BulkProcessor) report(batch bulkProcessorBatch, result *BulkResult, attempt int) bulkProcessorBatch {
+	var retry bulkProcessorBatch
+	for i, item := range batch {
+		item.response = nil
+		if result != nil && i < len(result.Items) {
+			for _, r := range result.Items[i] {
+				item.response = r
+			}
+		}
+		response := item.response
+
+		switch {
+		case response == nil:
+			p.failure(item, &BulkResponseItem{
+				Error: &ErrorDetails{Reason: "missing from the bulk response"},
+			}, fmt.Errorf("esclient: bulk response has no item %d", i), attempt)
+		case response.Status >= http.StatusOK && response.Status < http.StatusMultipleChoices:
+			p.indexed.Add(1)
+			if p.onSuccess != nil {
+				p.onSuccess(item.request, response)
+			}
+		case isVersionConflict(response):
+			p.conflicted.Add(1)
+			switch p.conflicts {
+			case VersionConflictIgnore:
+			case VersionConflictRetry:
+				retry = append(retry, item)
+			default:
+				p.failure(item, response, nil, attempt)
+			}
+		case isRetryableItemStatus(response.Status):
+			retry = append(retry, item)
+		default:
+			p.failure(item, response, nil, attempt)
+		}
+	}
+	return retry
+}
+
+func (p *BulkProcessor) reportError(batch bulkProcessorBatch, err error, attempt int) {
+	response := &BulkResponseItem{
+		Error: &ErrorDetails{Reason: err.Error()},
+	}
+	if e, ok := asError(err); ok {
+		response.Status = e.Status
+		if e.Details != nil {
+			response.Error = e.Details
+		}
+	}
+
+	for _, item := range batch {
+		p.failure(item, response, err, attempt)
+	}
+}
+
+func (p *BulkProcessor) failure(item *bulkProcessorItem, response *BulkResponseItem, err error, attempt int) {
+	p.failed.Add(1)
+	if p.onFailure != nil {
+		p.onFailure(item.request, response, err)
+	}
+	if p.deadLetter == nil {
+		return
+	}
+
+	letter := &DeadLetter{
+		Index:    p.index,
+		Request:  item.body,
+		Status:   response.Status,
+		Error:    response.Error,
+		Attempts: attempt,
+		Time:     time.Now(),
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(p.ctx), deadLetterTimeout)
+	defer cancel()
+	if err := p.deadLetter.Write(ctx, letter); err != nil {
+		p.deadLetterMu.Lock()
+		p.deadLetterErrs = append(p.deadLetterErrs, err)
+		p.deadLetterMu.Unlock()
+		return
+	}
+	p.deadLettered.Add(1)
+}
@@ -0,0 +1,468 @@
+package esclient_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github/shaolim/kakashi/pkg/esclient"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var bulkItemErrors = map[int]map[string]string{
+	http.StatusBadRequest:         {"type": "mapper_parsing_exception", "reason": "failed to parse"},
+	http.StatusConflict:           {"type": "version_conflict_engine_exception", "reason": "version conflict, document already exists"},
+	http.StatusTooManyRequests:    {"type": "es_rejected_execution_exception", "reason": "rejected execution"},
+	http.StatusServiceUnavailable: {"type": "unavailable_shards_exception", "reason": "primary shard is not active"},
+}
+
+// newBulkServer answers every action of a bulk request with the status
+// returned by itemStatus for its id.
+func newBulkServer(t *testing.T, itemStatus func(id string) int) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var items []map[string]interface{}
+		errors := false
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var action map[string]struct {
+				Id string `json:"_id"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+				t.Errorf("invalid action %q: %v", scanner.Text(), err)
+				return
+			}
+			for name, meta := range action {
+				status := itemStatus(meta.Id)
+				item := map[string]interface{}{"_id": meta.Id, "status": status}
+				if status >= 300 {
+					errors = true
+					item["error"] = bulkItemErrors[status]
+				}
+				items = append(items, map[string]interface{}{name: item})
+				if name != "delete" {
+					scanner.Scan()
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"took": 1, "errors": errors, "items": items})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestBulkProcessorFlushesByActions(t *testing.T) {
+	server, requests := newBulkServer(t, func(string) int { return http.StatusCreated })
+	client := esclient.NewClient(server.URL)
+
+	var mu sync.Mutex
+	var succeeded []string
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+		esclient.BulkProcessorWithBulkActions(2),
+		esclient.BulkProcessorWithOnSuccess(func(_ esclient.BulkableRequest, item *esclient.BulkResponseItem) {
+			mu.Lock()
+			defer mu.Unlock()
+			succeeded = append(succeeded, item.Id)
+		}),
+	)
+
+	for i := 1; i <= 5; i++ {
+		id := fmt.Sprintf("SKU-%d", i)
+		assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkIndexRequest().SetId(id).SetDoc(map[string]string{"sku": id})))
+	}
+	assert.NoError(t, processor.Close(context.Background()))
+
+	assert.Equal(t, int32(3), requests.Load())
+	assert.ElementsMatch(t, []string{"SKU-1", "SKU-2", "SKU-3", "SKU-4", "SKU-5"}, succeeded)
+	stats := processor.Stats()
+	assert.Equal(t, int64(3), stats.Flushed)
+	assert.Equal(t, int64(5), stats.Indexed)
+	assert.Equal(t, int64(0), stats.Failed)
+	assert.Positive(t, stats.BytesSent)
+
+	err := processor.Add(context.Background(), esclient.NewBulkDeleteRequest("SKU-6"))
+	assert.ErrorIs(t, err, esclient.ErrBulkProcessorClosed)
+}
+
+func TestBulkProcessorFlushesBySize(t *testing.T) {
+	server, requests := newBulkServer(t, func(string) int { return http.StatusOK })
+	client := esclient.NewClient(server.URL)
+
+	request := esclient.NewBulkDeleteRequest("SKU-1")
+	body, err := request.String()
+	assert.NoError(t, err)
+
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+		esclient.BulkProcessorWithBulkActions(0),
+		esclient.BulkProcessorWithBulkSize(2*len(body)+1),
+	)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, processor.Add(context.Background(), request))
+	}
+	assert.NoError(t, processor.Close(context.Background()))
+
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, int64(4*len(body)), processor.Stats().BytesSent)
+}
+
+func TestBulkProcessorFlushInterval(t *testing.T) {
+	server, requests := newBulkServer(t, func(string) int { return http.StatusOK })
+	client := esclient.NewClient(server.URL)
+
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+		esclient.BulkProcessorWithFlushInterval(10*time.Millisecond),
+	)
+	defer processor.Close(context.Background())
+
+	assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkDeleteRequest("SKU-1")))
+	assert.Eventually(t, func() bool {
+		return processor.Stats().Indexed == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestBulkProcessorFlush(t *testing.T) {
+	server, requests := newBulkServer(t, func(string) int { return http.StatusOK })
+	client := esclient.NewClient(server.URL)
+
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+		esclient.BulkProcessorWithWorkers(4),
+	)
+	defer processor.Close(context.Background())
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkDeleteRequest(fmt.Sprintf("SKU-%d", i))))
+	}
+	assert.NoError(t, processor.Flush(context.Background()))
+	assert.Equal(t, int64(10), processor.Stats().Indexed)
+	assert.LessOrEqual(t, requests.Load(), int32(4))
+}
+
+func TestBulkProcessorFailures(t *testing.T) {
+	server, _ := newBulkServer(t, func(id string) int {
+		if id == "SKU-2" {
+			return http.StatusBadRequest
+		}
+		return http.StatusCreated
+	})
+	client := esclient.NewClient(server.URL)
+
+	var failed []*esclient.BulkResponseItem
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+		esclient.BulkProcessorWithOnFailure(func(_ esclient.BulkableRequest, item *esclient.BulkResponseItem, err error) {
+			assert.NoError(t, err)
+			failed = append(failed, item)
+		}),
+	)
+	for _, id := range []string{"SKU-1", "SKU-2", "SKU-3"} {
+		assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkCreateRequest(id).SetDoc(map[string]string{"sku": id})))
+	}
+	assert.NoError(t, processor.Close(context.Background()))
+
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "SKU-2", failed[0].Id)
+	assert.Equal(t, "mapper_parsing_exception", failed[0].Error.Type)
+	assert.Equal(t, int64(2), processor.Stats().Indexed)
+	assert.Equal(t, int64(1), processor.Stats().Failed)
+}
+
+func TestBulkProcessorRetry(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"type":"es_rejected_execution_exception","reason":"rejected"},"status":429}`))
+			return
+		}
+		w.Write([]byte(`{"took":1,"errors":false,"items":[{"delete":{"_id":"SKU-1","status":200}}]}`))
+	}))
+	defer server.Close()
+	client := esclient.NewClient(server.URL)
+
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+		esclient.BulkProcessorWithRetry(3, esclient.RetryWithBackoff(time.Millisecond, 5*time.Millisecond)),
+	)
+	assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkDeleteRequest("SKU-1")))
+	assert.NoError(t, processor.Close(context.Background()))
+
+	stats := processor.Stats()
+	assert.Equal(t, int32(2), attempts.Load())
+	assert.Equal(t, int64(1), stats.Indexed)
+	assert.Equal(t, int64(1), stats.Retried)
+	assert.Equal(t, int64(1), stats.Flushed)
+}
+
+func TestBulkProcessorRequestFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"type":"index_not_found_exception","reason":"no such index"},"status":404}`))
+	}))
+	defer server.Close()
+	client := esclient.NewClient(server.URL)
+
+	var reasons []string
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+		esclient.BulkProcessorWithOnFailure(func(_ esclient.BulkableRequest, item *esclient.BulkResponseItem, err error) {
+			assert.True(t, esclient.IsNotFound(err))
+			assert.Equal(t, http.StatusNotFound, item.Status)
+			reasons = append(reasons, item.Error.Reason)
+		}),
+	)
+	assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkDeleteRequest("SKU-1")))
+	assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkDeleteRequest("SKU-2")))
+	assert.NoError(t, processor.Close(context.Background()))
+
+	assert.Equal(t, []string{"no such index", "no such index"}, reasons)
+	assert.Equal(t, int64(2), processor.Stats().Failed)
+}
+
+func TestBulkProcessorBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"took":1,"errors":false,"items":[{"delete":{"_id":"SKU-1","status":200}}]}`))
+	}))
+	defer server.Close()
+	client := esclient.NewClient(server.URL)
+
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+		esclient.BulkProcessorWithBulkActions(1),
+	)
+	assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkDeleteRequest("SKU-1")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := processor.Add(ctx, esclient.NewBulkDeleteRequest("SKU-2"))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	close(release)
+	assert.NoError(t, processor.Close(context.Background()))
+	assert.Equal(t, int64(1), processor.Stats().Indexed)
+}
+
+func TestBulkProcessorBatchBody(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b := new(strings.Builder)
+		bufio.NewReader(r.Body).WriteTo(b)
+		body = b.String()
+		w.Write([]byte(`{"took":1,"errors":false,"items":[{"index":{"_id":"SKU-1","status":201}},{"delete":{"_id":"SKU-2","status":200}}]}`))
+	}))
+	defer server.Close()
+	client := esclient.NewClient(server.URL)
+
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja")
+	assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkIndexRequest().SetId("SKU-1").SetDoc(map[string]string{"sku": "SKU-1"})))
+	assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkDeleteRequest("SKU-2")))
+	assert.NoError(t, processor.Close(context.Background()))
+
+	assert.Equal(t, "{\"index\":{\"_id\":\"SKU-1\"}}\n{\"sku\":\"SKU-1\"}\n{\"delete\":{\"_id\":\"SKU-2\"}}\n", body)
+}
+
+func TestBulkProcessorRetriesFailedItems(t *testing.T) {
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	var bodies []string
+	server, requests := newBulkServer(t, func(id string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[id]++
+		switch {
+		case id == "SKU-2" && attempts[id] == 1:
+			return http.StatusTooManyRequests
+		case id == "SKU-3" && attempts[id] < 3:
+			return http.StatusServiceUnavailable
+		case id == "SKU-4":
+			return http.StatusBadRequest
+		}
+		return http.StatusCreated
+	})
+	client := esclient.NewClient(server.URL)
+
+	var deadLetters strings.Builder
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+		esclient.BulkProcessorWithRetry(3, esclient.RetryWithBackoff(time.Millisecond, 5*time.Millisecond)),
+		esclient.BulkProcessorWithDeadLetterSink(esclient.NewJSONLDeadLetterSink(&deadLetters)),
+	)
+	for _, id := range []string{"SKU-1", "SKU-2", "SKU-3", "SKU-4"} {
+		request := esclient.NewBulkIndexRequest().SetId(id).SetDoc(map[string]string{"sku": id})
+		body, _ := request.String()
+		bodies = append(bodies, body)
+		assert.NoError(t, processor.Add(context.Background(), request))
+	}
+	assert.NoError(t, processor.Close(context.Background()))
+
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Equal(t, map[string]int{"SKU-1": 1, "SKU-2": 2, "SKU-3": 3, "SKU-4": 1}, attempts)
+	stats := processor.Stats()
+	assert.Equal(t, int64(3), stats.Indexed)
+	assert.Equal(t, int64(1), stats.Failed)
+	assert.Equal(t, int64(3), stats.Retried)
+	assert.Equal(t, int64(1), stats.DeadLettered)
+
+	var letter esclient.DeadLetter
+	assert.NoError(t, json.Unmarshal([]byte(deadLetters.String()), &letter))
+	assert.Equal(t, "item_index_ja", letter.Index)
+	assert.Equal(t, bodies[3], letter.Request)
+	assert.Equal(t, http.StatusBadRequest, letter.Status)
+	assert.Equal(t, "mapper_parsing_exception", letter.Error.Type)
+	assert.Equal(t, 1, letter.Attempts)
+}
+
+func TestBulkProcessorGivesUpRetrying(t *testing.T) {
+	server, _ := newBulkServer(t, func(string) int { return http.StatusTooManyRequests })
+	client := esclient.NewClient(server.URL)
+
+	var deadLetters strings.Builder
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+		esclient.BulkProcessorWithRetry(2, esclient.RetryWithBackoff(time.Millisecond, 5*time.Millisecond)),
+		esclient.BulkProcessorWithDeadLetterSink(esclient.NewJSONLDeadLetterSink(&deadLetters)),
+	)
+	assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkDeleteRequest("SKU-1")))
+	assert.NoError(t, processor.Close(context.Background()))
+
+	var letter esclient.DeadLetter
+	assert.NoError(t, json.Unmarshal([]byte(deadLetters.String()), &letter))
+	assert.Equal(t, http.StatusTooManyRequests, letter.Status)
+	assert.Equal(t, 2, letter.Attempts)
+	assert.Equal(t, int64(1), processor.Stats().Retried)
+}
+
+func TestBulkProcessorVersionConflictPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   esclient.VersionConflictPolicy
+		attempts int
+		indexed  int64
+		failed   int64
+	}{
+		{name: "fail", policy: esclient.VersionConflictFail, attempts: 1, failed: 1},
+		{name: "ignore", policy: esclient.VersionConflictIgnore, attempts: 1},
+		{name: "retry", policy: esclient.VersionConflictRetry, attempts: 2, indexed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server, _ := newBulkServer(t, func(string) int {
+				attempts++
+				if attempts == 1 {
+					return http.StatusConflict
+				}
+				return http.StatusOK
+			})
+			client := esclient.NewClient(server.URL)
+
+			processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+				esclient.BulkProcessorWithRetry(3, esclient.RetryWithBackoff(time.Millisecond, 5*time.Millisecond)),
+				esclient.BulkProcessorWithVersionConflictPolicy(tt.policy),
+			)
+			assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkCreateRequest("SKU-1").SetDoc(map[string]string{"sku": "SKU-1"})))
+			assert.NoError(t, processor.Close(context.Background()))
+
+			stats := processor.Stats()
+			assert.Equal(t, tt.attempts, attempts)
+			assert.Equal(t, int64(1), stats.Conflicted)
+			assert.Equal(t, tt.indexed, stats.Indexed)
+			assert.Equal(t, tt.failed, stats.Failed)
+		})
+	}
+}
+
+type failingDeadLetterSink struct{}
+
+func (failingDeadLetterSink) Write(context.Context, *esclient.DeadLetter) error {
+	return errors.New("topic not found")
+}
+
+func TestBulkProcessorDeadLetterSinkError(t *testing.T) {
+	server, _ := newBulkServer(t, func(string) int { return http.StatusBadRequest })
+	client := esclient.NewClient(server.URL)
+
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+		esclient.BulkProcessorWithDeadLetterSink(failingDeadLetterSink{}),
+	)
+	assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkDeleteRequest("SKU-1")))
+	assert.EqualError(t, processor.Close(context.Background()), "topic not found")
+	assert.Equal(t, int64(0), processor.Stats().DeadLettered)
+}
+
+// contextDeadLetterSink fails like a remote sink once ctx is done.
+type contextDeadLetterSink struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (s *contextDeadLetterSink) Write(ctx context.Context, letter *esclient.DeadLetter) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, string(letter.Request))
+	return nil
+}
+
+func TestBulkProcessorDeadLettersAfterCancel(t *testing.T) {
+	sending := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(sending)
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	client := esclient.NewClient(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := &contextDeadLetterSink{}
+	processor := esclient.NewBulkProcessor(ctx, client, "item_index_ja",
+		esclient.BulkProcessorWithBulkActions(3),
+		esclient.BulkProcessorWithDeadLetterSink(sink),
+	)
+	for _, id := range []string{"SKU-1", "SKU-2", "SKU-3"} {
+		assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkDeleteRequest(id)))
+	}
+
+	<-sending
+	cancel()
+	assert.NoError(t, processor.Close(context.Background()))
+
+	assert.Len(t, sink.requests, 3)
+	for i, request := range sink.requests {
+		assert.Contains(t, request, fmt.Sprintf(`"_id":"SKU-%d"`, i+1))
+	}
+	assert.Equal(t, int64(3), processor.Stats().DeadLettered)
+}
+
+func TestBulkProcessorCloseTimeoutDeadLetters(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	client := esclient.NewClient(server.URL)
+
+	sink := &contextDeadLetterSink{}
+	processor := esclient.NewBulkProcessor(context.Background(), client, "item_index_ja",
+		esclient.BulkProcessorWithDeadLetterSink(sink),
+	)
+	assert.NoError(t, processor.Add(context.Background(), esclient.NewBulkDeleteRequest("SKU-1")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.True(t, errors.Is(processor.Close(ctx), context.DeadlineExceeded))
+	assert.Len(t, sink.requests, 1)
+}
@@ -2,7 +2,9 @@ package esclient
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"io"
 	"strings"
 )
 
@@ -10,6 +12,59 @@ type BulkableRequest interface {
 	String() (string, error)
 }
 
+// BulkWriter is implemented by the bulk requests that can write their NDJSON
+// lines straight into the request body. Other requests are written with their
+// String method.
+type BulkWriter interface {
+	WriteBulk(w io.Writer) error
+}
+
+func writeBulk(w io.Writer, request BulkableRequest) error {
+	if writer, ok := request.(BulkWriter); ok {
+		return writer.WriteBulk(w)
+	}
+	str, err := request.String()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, str)
+	return err
+}
+
+// bulkString builds the String of a request from its WriteBulk.
+func bulkString(writer BulkWriter) (string, error) {
+	var sb strings.Builder
+	if err := writer.WriteBulk(&sb); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// writeBulkAction writes the action line, e.g. {"index":{"_id":"1"}}.
+func writeBulkAction(w io.Writer, action string, meta interface{}) error {
+	p, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, `{"`+action+`":`); err != nil {
+		return err
+	}
+	if _, err := w.Write(p); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "}\n")
+	return err
+}
+
+// writeBulkSource writes the source line of doc, {} when doc is nil.
+func writeBulkSource(w io.Writer, doc interface{}) error {
+	if doc == nil {
+		_, err := io.WriteString(w, "{}\n")
+		return err
+	}
+	return json.NewEncoder(w).Encode(doc)
+}
+
 type BulkRequests struct {
 	BulkRequests []BulkableRequest
 }
@@ -24,20 +79,26 @@ func (b *BulkRequests) Length() int {
 }
 
 func (b BulkRequests) String() (string, error) {
-	var sb strings.Builder
+	return bulkString(b)
+}
+
+func (b BulkRequests) WriteBulk(w io.Writer) error {
 	for _, bulkRequest := range b.BulkRequests {
-		str, err := bulkRequest.String()
-		if err != nil {
-			return "", err
+		if err := writeBulk(w, bulkRequest); err != nil {
+			return err
 		}
-		sb.WriteString(str)
 	}
-	return sb.String(), nil
+	return nil
 }
 
 type bulkDeleteRequest struct {
-	Index string `json:"_index,omitempty"`
-	Id    string `json:"_id"`
+	Index         string `json:"_index,omitempty"`
+	Id            string `json:"_id"`
+	Routing       string `json:"routing,omitempty"`
+	IfSeqNo       *int64 `json:"if_seq_no,omitempty"`
+	IfPrimaryTerm *int64 `json:"if_primary_term,omitempty"`
+	Version       *int64 `json:"version,omitempty"`
+	VersionType   string `json:"version_type,omitempty"`
 }
 
 func NewBulkDeleteRequest(id string) *bulkDeleteRequest {
@@ -51,22 +112,52 @@ func (b *bulkDeleteRequest) SetIndex(index string) *bulkDeleteRequest {
 	return b
 }
 
+func (b *bulkDeleteRequest) SetRouting(routing string) *bulkDeleteRequest {
+	b.Routing = routing
+	return b
+}
+
+func (b *bulkDeleteRequest) SetIfSeqNo(ifSeqNo int64) *bulkDeleteRequest {
+	b.IfSeqNo = &ifSeqNo
+	return b
+}
+
+func (b *bulkDeleteRequest) SetIfPrimaryTerm(ifPrimaryTerm int64) *bulkDeleteRequest {
+	b.IfPrimaryTerm = &ifPrimaryTerm
+	return b
+}
+
+func (b *bulkDeleteRequest) SetVersion(version int64) *bulkDeleteRequest {
+	b.Version = &version
+	return b
+}
+
+// valid version types: ["internal", "external", "external_gte"]
+func (b *bulkDeleteRequest) SetVersionType(versionType string) *bulkDeleteRequest {
+	b.VersionType = versionType
+	return b
+}
+
 func (b *bulkDeleteRequest) String() (string, error) {
-	p, err := json.Marshal(b)
-	if err != nil {
-		return "", err
-	}
-	return fmt.Sprintf("{\"delete\":%v}\n", string(p)), nil
+	return bulkString(b)
+}
+
+func (b *bulkDeleteRequest) WriteBulk(w io.Writer) error {
+	return writeBulkAction(w, "delete", b)
 }
 
 type bulkIndexRequest struct {
-	Index         string      `json:"_index,omitempty"`
-	Id            string      `json:"_id"`
-	Routing       string      `json:"routing,omitempty"`
-	Pipeline      string      `json:"pipeline,omitempty"`
-	IfSeqNo       int64       `json:"if_seq_no,omitempty"`
-	IfPrimaryTerm int64       `json:"if_primary_term,omitempty"`
-	Doc           interface{} `json:"-"`
+	Index            string            `json:"_index,omitempty"`
+	Id               string            `json:"_id"`
+	Routing          string            `json:"routing,omitempty"`
+	Pipeline         string            `json:"pipeline,omitempty"`
+	IfSeqNo          *int64            `json:"if_seq_no,omitempty"`
+	IfPrimaryTerm    *int64            `json:"if_primary_term,omitempty"`
+	Version          *int64            `json:"version,omitempty"`
+	VersionType      string            `json:"version_type,omitempty"`
+	RequireAlias     bool              `json:"require_alias,omitempty"`
+	DynamicTemplates map[string]string `json:"dynamic_templates,omitempty"`
+	Doc              interface{}       `json:"-"`
 }
 
 func NewBulkIndexRequest() *bulkIndexRequest {
@@ -94,12 +185,40 @@ func (b *bulkIndexRequest) SetPipeline(pipeline string) *bulkIndexRequest {
 }
 
 func (b *bulkIndexRequest) SetIfSeqNo(ifSeqNo int64) *bulkIndexRequest {
-	b.IfSeqNo = ifSeqNo
+	b.IfSeqNo = &ifSeqNo
 	return b
 }
 
 func (b *bulkIndexRequest) SetIfPrimaryTerm(ifPrimaryTerm int64) *bulkIndexRequest {
-	b.IfPrimaryTerm = ifPrimaryTerm
+	b.IfPrimaryTerm = &ifPrimaryTerm
+	return b
+}
+
+func (b *bulkIndexRequest) SetVersion(version int64) *bulkIndexRequest {
+	b.Version = &version
+	return b
+}
+
+// valid version types: ["internal", "external", "external_gte"]
+func (b *bulkIndexRequest) SetVersionType(versionType string) *bulkIndexRequest {
+	b.VersionType = versionType
+	return b
+}
+
+// SetRequireAlias fails the action unless the index is an alias, instead of
+// creating a new index with the name of the alias.
+func (b *bulkIndexRequest) SetRequireAlias(requireAlias bool) *bulkIndexRequest {
+	b.RequireAlias = requireAlias
+	return b
+}
+
+// SetDynamicTemplate maps the new field at path with the named dynamic
+// template of the index mapping.
+func (b *bulkIndexRequest) SetDynamicTemplate(path, template string) *bulkIndexRequest {
+	if b.DynamicTemplates == nil {
+		b.DynamicTemplates = make(map[string]string)
+	}
+	b.DynamicTemplates[path] = template
 	return b
 }
 
@@ -109,32 +228,35 @@ func (b *bulkIndexRequest) SetDoc(doc interface{}) *bulkIndexRequest {
 }
 
 func (b *bulkIndexRequest) String() (string, error) {
-	p, err := json.Marshal(b)
-	if err != nil {
-		return "", err
-	}
-	action := fmt.Sprintf("{\"index\":%v}", string(p))
+	return bulkString(b)
+}
 
-	doc := "{}"
-	if b.Doc != nil {
-		_docs, err := json.Marshal(b.Doc)
-		if err != nil {
-			return "", err
-		}
-		doc = string(_docs)
+func (b *bulkIndexRequest) WriteBulk(w io.Writer) error {
+	if err := writeBulkAction(w, "index", b); err != nil {
+		return err
 	}
-
-	return fmt.Sprintf("%s\n%s\n", action, doc), nil
+	return writeBulkSource(w, b.Doc)
 }
 
+// bulkUpdateRequest has no version or dynamic templates, elasticsearch
+// rejects both on updates.
 type bulkUpdateRequest struct {
-	Index           string      `json:"_index,omitempty"`
-	Id              string      `json:"_id"`
-	RetryOnConflict int         `json:"retry_on_conflict,omitempty"`
-	Routing         string      `json:"routing,omitempty"`
-	IfSeqNo         int64       `json:"if_seq_no,omitempty"`
-	IfPrimaryTerm   int64       `json:"if_primary_term,omitempty"`
-	Doc             interface{} `json:"-"`
+	Index           string `json:"_index,omitempty"`
+	Id              string `json:"_id"`
+	RetryOnConflict int    `json:"retry_on_conflict,omitempty"`
+	Routing         string `json:"routing,omitempty"`
+	Pipeline        string `json:"pipeline,omitempty"`
+	IfSeqNo         *int64 `json:"if_seq_no,omitempty"`
+	IfPrimaryTerm   *int64 `json:"if_primary_term,omitempty"`
+	RequireAlias    bool   `json:"require_alias,omitempty"`
+
+	Doc            interface{}     `json:"-"`
+	Script         *esquery.Script `json:"-"`
+	Upsert         interface{}     `json:"-"`
+	DocAsUpsert    *bool           `json:"-"`
+	ScriptedUpsert *bool           `json:"-"`
+	DetectNoop     *bool           `json:"-"`
+	Source         interface{}     `json:"-"`
 }
 
 func NewBulkUpdateRequest(id string) *bulkUpdateRequest {
@@ -158,46 +280,103 @@ func (b *bulkUpdateRequest) SetRouting(routing string) *bulkUpdateRequest {
 	return b
 }
 
+// SetPipeline runs the ingest pipeline on the upsert document when the
+// document does not exist yet. Updates of existing documents skip it.
+func (b *bulkUpdateRequest) SetPipeline(pipeline string) *bulkUpdateRequest {
+	b.Pipeline = pipeline
+	return b
+}
+
 func (b *bulkUpdateRequest) SetIfSeqNo(ifSeqNo int64) *bulkUpdateRequest {
-	b.IfSeqNo = ifSeqNo
+	b.IfSeqNo = &ifSeqNo
 	return b
 }
 
 func (b *bulkUpdateRequest) SetIfPrimaryTerm(ifPrimaryTerm int64) *bulkUpdateRequest {
-	b.IfPrimaryTerm = ifPrimaryTerm
+	b.IfPrimaryTerm = &ifPrimaryTerm
 	return b
 }
 
+func (b *bulkUpdateRequest) SetRequireAlias(requireAlias bool) *bulkUpdateRequest {
+	b.RequireAlias = requireAlias
+	return b
+}
+
+// SetDoc sets the partial document merged into the existing one.
 func (b *bulkUpdateRequest) SetDoc(doc interface{}) *bulkUpdateRequest {
 	b.Doc = doc
 	return b
 }
 
+func (b *bulkUpdateRequest) SetScript(script *esquery.Script) *bulkUpdateRequest {
+	b.Script = script
+	return b
+}
+
+// SetUpsert sets the document indexed when the document does not exist yet.
+func (b *bulkUpdateRequest) SetUpsert(upsert interface{}) *bulkUpdateRequest {
+	b.Upsert = upsert
+	return b
+}
+
+func (b *bulkUpdateRequest) SetDocAsUpsert(docAsUpsert bool) *bulkUpdateRequest {
+	b.DocAsUpsert = &docAsUpsert
+	return b
+}
+
+func (b *bulkUpdateRequest) SetScriptedUpsert(scriptedUpsert bool) *bulkUpdateRequest {
+	b.ScriptedUpsert = &scriptedUpsert
+	return b
+}
+
+func (b *bulkUpdateRequest) SetDetectNoop(detectNoop bool) *bulkUpdateRequest {
+	b.DetectNoop = &detectNoop
+	return b
+}
+
+// SetSource returns the updated source in BulkResponseItem.Get, either a
+// bool or a list of fields.
+func (b *bulkUpdateRequest) SetSource(source interface{}) *bulkUpdateRequest {
+	b.Source = source
+	return b
+}
+
 func (b *bulkUpdateRequest) String() (string, error) {
-	p, err := json.Marshal(b)
-	if err != nil {
-		return "", err
-	}
-	action := fmt.Sprintf("{\"update\":%v}", string(p))
+	return bulkString(b)
+}
 
-	doc := "{}"
-	if b.Doc != nil {
-		_docs, err := json.Marshal(b.Doc)
-		if err != nil {
-			return "", err
-		}
-		doc = string(_docs)
+func (b *bulkUpdateRequest) WriteBulk(w io.Writer) error {
+	if b.Doc != nil && b.Script != nil {
+		return errors.New("esclient: bulk update of " + b.Id + " has both a doc and a script")
 	}
-
-	return fmt.Sprintf("%s\n{\"doc\":%s}\n", action, doc), nil
+	if err := writeBulkAction(w, "update", b); err != nil {
+		return err
+	}
+	doc := b.Doc
+	if doc == nil && b.Script == nil {
+		doc = struct{}{}
+	}
+	return writeBulkSource(w, &updateDocumentRequest{
+		Doc:            doc,
+		Script:         b.Script,
+		Upsert:         b.Upsert,
+		DocAsUpsert:    b.DocAsUpsert,
+		ScriptedUpsert: b.ScriptedUpsert,
+		DetectNoop:     b.DetectNoop,
+		Source:         b.Source,
+	})
 }
 
 type bulkCreateRequest struct {
-	Index    string      `json:"_index,omitempty"`
-	Id       string      `json:"_id"`
-	Routing  string      `json:"routing,omitempty"`
-	Pipeline string      `json:"pipeline,omitempty"`
-	Doc      interface{} `json:"-"`
+	Index            string            `json:"_index,omitempty"`
+	Id               string            `json:"_id"`
+	Routing          string            `json:"routing,omitempty"`
+	Pipeline         string            `json:"pipeline,omitempty"`
+	Version          *int64            `json:"version,omitempty"`
+	VersionType      string            `json:"version_type,omitempty"`
+	RequireAlias     bool              `json:"require_alias,omitempty"`
+	DynamicTemplates map[string]string `json:"dynamic_templates,omitempty"`
+	Doc              interface{}       `json:"-"`
 }
 
 func NewBulkCreateRequest(id string) *bulkCreateRequest {
@@ -221,26 +400,43 @@ func (b *bulkCreateRequest) SetPipeline(pipeline string) *bulkCreateRequest {
 	return b
 }
 
+// SetVersion with an external version type creates the document with the
+// version of the source system.
+func (b *bulkCreateRequest) SetVersion(version int64) *bulkCreateRequest {
+	b.Version = &version
+	return b
+}
+
+func (b *bulkCreateRequest) SetVersionType(versionType string) *bulkCreateRequest {
+	b.VersionType = versionType
+	return b
+}
+
+func (b *bulkCreateRequest) SetRequireAlias(requireAlias bool) *bulkCreateRequest {
+	b.RequireAlias = requireAlias
+	return b
+}
+
+func (b *bulkCreateRequest) SetDynamicTemplate(path, template string) *bulkCreateRequest {
+	if b.DynamicTemplates == nil {
+		b.DynamicTemplates = make(map[string]string)
+	}
+	b.DynamicTemplates[path] = template
+	return b
+}
+
 func (b *bulkCreateRequest) SetDoc(doc interface{}) *bulkCreateRequest {
 	b.Doc = doc
 	return b
 }
 
 func (b *bulkCreateRequest) String() (string, error) {
-	p, err := json.Marshal(b)
-	if err != nil {
-		return "", err
-	}
-	action := fmt.Sprintf("{\"create\":%v}", string(p))
+	return bulkString(b)
+}
 
-	doc := "{}"
-	if b.Doc != nil {
-		_docs, err := json.Marshal(b.Doc)
-		if err != nil {
-			return "", err
-		}
-		doc = string(_docs)
+func (b *bulkCreateRequest) WriteBulk(w io.Writer) error {
+	if err := writeBulkAction(w, "create", b); err != nil {
+		return err
 	}
-
-	return fmt.Sprintf("%s\n%s\n", action, doc), nil
+	return writeBulkSource(w, b.Doc)
 }
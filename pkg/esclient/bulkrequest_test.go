@@ -2,6 +2,7 @@ package esclient_test
 
 import (
 	"github/shaolim/kakashi/pkg/esclient"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -41,3 +42,92 @@ func TestBulkRequest(t *testing.T) {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func TestBulkRequestOptions(t *testing.T) {
+	expected := `{"update":{"_index":"products","_id":"1","retry_on_conflict":3,"routing":"shop-1","pipeline":"prices","require_alias":true}}
+{"script":{"source":"ctx._source.stock -= params.count","params":{"count":1}},"upsert":{"stock":10},"scripted_upsert":true,"_source":true}
+{"update":{"_id":"2","if_seq_no":10,"if_primary_term":2}}
+{"doc":{"price":649.99},"doc_as_upsert":true,"detect_noop":false}
+{"delete":{"_index":"products","_id":"3","routing":"shop-1","if_seq_no":7,"if_primary_term":1}}
+{"delete":{"_id":"4","version":5,"version_type":"external"}}
+{"index":{"_id":"5","version":3,"version_type":"external_gte","require_alias":true,"dynamic_templates":{"location":"geo_point"}}}
+{"location":"35.68,139.69"}
+{"create":{"_id":"6","pipeline":"prices","require_alias":true,"dynamic_templates":{"location":"geo_point"}}}
+{"location":"35.68,139.69"}
+`
+
+	type location struct {
+		Location string `json:"location"`
+	}
+
+	actual, err := esclient.BulkRequests{
+		BulkRequests: []esclient.BulkableRequest{
+			esclient.NewBulkUpdateRequest("1").SetIndex("products").
+				SetRetryOnConflict(3).
+				SetRouting("shop-1").
+				SetPipeline("prices").
+				SetRequireAlias(true).
+				SetScript(esquery.NewScript("ctx._source.stock -= params.count").SetParams(map[string]interface{}{"count": 1})).
+				SetUpsert(map[string]int{"stock": 10}).
+				SetScriptedUpsert(true).
+				SetSource(true),
+			esclient.NewBulkUpdateRequest("2").
+				SetIfSeqNo(10).
+				SetIfPrimaryTerm(2).
+				SetDoc(map[string]float64{"price": 649.99}).
+				SetDocAsUpsert(true).
+				SetDetectNoop(false),
+			esclient.NewBulkDeleteRequest("3").SetIndex("products").
+				SetRouting("shop-1").
+				SetIfSeqNo(7).
+				SetIfPrimaryTerm(1),
+			esclient.NewBulkDeleteRequest("4").
+				SetVersion(5).
+				SetVersionType("external"),
+			esclient.NewBulkIndexRequest().SetId("5").
+				SetVersion(3).
+				SetVersionType("external_gte").
+				SetRequireAlias(true).
+				SetDynamicTemplate("location", "geo_point").
+				SetDoc(location{Location: "35.68,139.69"}),
+			esclient.NewBulkCreateRequest("6").
+				SetPipeline("prices").
+				SetRequireAlias(true).
+				SetDynamicTemplate("location", "geo_point").
+				SetDoc(location{Location: "35.68,139.69"}),
+		},
+	}.
+		String()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestBulkRequestZeroSeqNo(t *testing.T) {
+	expected := `{"delete":{"_id":"1","if_seq_no":0,"if_primary_term":1}}
+{"index":{"_id":"2","if_seq_no":0,"if_primary_term":1}}
+{"price":0}
+{"update":{"_id":"3","if_seq_no":0,"if_primary_term":1}}
+{"doc":{"price":0}}
+{"delete":{"_id":"4","version":0,"version_type":"external"}}
+`
+
+	actual, err := esclient.BulkRequests{
+		BulkRequests: []esclient.BulkableRequest{
+			esclient.NewBulkDeleteRequest("1").SetIfSeqNo(0).SetIfPrimaryTerm(1),
+			esclient.NewBulkIndexRequest().SetId("2").SetIfSeqNo(0).SetIfPrimaryTerm(1).SetDoc(map[string]int{"price": 0}),
+			esclient.NewBulkUpdateRequest("3").SetIfSeqNo(0).SetIfPrimaryTerm(1).SetDoc(map[string]int{"price": 0}),
+			esclient.NewBulkDeleteRequest("4").SetVersion(0).SetVersionType("external"),
+		},
+	}.
+		String()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestBulkUpdateRequestWithDocAndScript(t *testing.T) {
+	_, err := esclient.NewBulkUpdateRequest("1").
+		SetDoc(map[string]float64{"price": 649.99}).
+		SetScript(esquery.NewScript("ctx._source.stock -= 1")).
+		String()
+	assert.Error(t, err)
+}
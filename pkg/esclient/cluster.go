@@ -0,0 +1,161 @@
+package esclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHealthCheckInterval = 60 * time.Second
+	healthCheckTimeout         = 5 * time.Second
+)
+
+// WithNodes adds more nodes of the same cluster. Requests are spread across
+// the live nodes in a round-robin fashion.
+func WithNodes(urls ...string) ClientOption {
+	return func(c *client) {
+		c.urls = append(c.urls, urls...)
+	}
+}
+
+// WithHealthCheck sets how often dead nodes are pinged to bring them back to
+// the pool. Health checks run by default when the client has more than one
+// node, an interval of 0 disables them.
+func WithHealthCheck(interval time.Duration) ClientOption {
+	return func(c *client) {
+		c.healthCheckInterval = interval
+	}
+}
+
+// WithSniffer discovers the nodes of the cluster through the _nodes/http API
+// when the client is created and then on every interval.
+func WithSniffer(interval time.Duration) ClientOption {
+	return func(c *client) {
+		c.sniffInterval = interval
+	}
+}
+
+func (c *client) startMaintenance() {
+	if c.sniffInterval > 0 {
+		ctx, cancel := context.WithTimeout(c.ctx, healthCheckTimeout)
+		c.sniff(ctx)
+		cancel()
+	}
+
+	healthCheck := c.healthCheckInterval > 0 && (len(c.urls) > 1 || c.sniffInterval > 0)
+	if !healthCheck && c.sniffInterval <= 0 {
+		return
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		var healthCheckC, sniffC <-chan time.Time
+		if healthCheck {
+			ticker := time.NewTicker(c.healthCheckInterval)
+			defer ticker.Stop()
+			healthCheckC = ticker.C
+		}
+		if c.sniffInterval > 0 {
+			ticker := time.NewTicker(c.sniffInterval)
+			defer ticker.Stop()
+			sniffC = ticker.C
+		}
+
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-healthCheckC:
+				c.healthCheck(c.ctx)
+			case <-sniffC:
+				ctx, cancel := context.WithTimeout(c.ctx, healthCheckTimeout)
+				c.sniff(ctx)
+				cancel()
+			}
+		}
+	}()
+}
+
+// healthCheck pings every dead node and marks it alive when it answers.
+// Any http response counts, an authentication error still means the node is up.
+func (c *client) healthCheck(ctx context.Context) {
+	for _, n := range c.pool.deadNodes() {
+		pingCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		_, err := c.nodeClient(n).Ping(pingCtx, PingWithHttpHeadOnly())
+		cancel()
+
+		if _, ok := asError(err); err == nil || ok {
+			c.pool.markAlive(n)
+		}
+	}
+}
+
+// nodeClient returns a client that only talks to the given node.
+func (c *client) nodeClient(n *node) *client {
+	return &client{
+		httpClient: c.httpClient,
+		pool:       &connectionPool{nodes: []*node{{url: n.url}}},
+		auth:       c.auth,
+	}
+}
+
+type nodesHttpResult struct {
+	Nodes map[string]struct {
+		Http struct {
+			PublishAddress string `json:"publish_address"`
+		} `json:"http"`
+	} `json:"nodes"`
+}
+
+func (c *client) sniff(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "/_nodes/http", nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	response := &Response[nodesHttpResult]{
+		StatusCode: res.StatusCode,
+	}
+	if err := response.SetBody(res.Body); err != nil {
+		return err
+	}
+	if response.Result == nil {
+		return nil
+	}
+
+	scheme := c.pool.scheme()
+	urls := make([]string, 0, len(response.Result.Nodes))
+	for _, n := range response.Result.Nodes {
+		if addr := publishAddress(n.Http.PublishAddress); addr != "" {
+			urls = append(urls, scheme+"://"+addr)
+		}
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+
+	return c.pool.setNodes(urls)
+}
+
+// publishAddress turns a publish address such as "es01/172.18.0.2:9200"
+// into a host:port pair.
+func publishAddress(addr string) string {
+	if i := strings.LastIndex(addr, "/"); i >= 0 {
+		addr = addr[i+1:]
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return ""
+	}
+	return addr
+}
@@ -0,0 +1,134 @@
+package esclient_test
+
+import (
+	"context"
+	"fmt"
+	"github/shaolim/kakashi/pkg/esclient"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testNode struct {
+	*httptest.Server
+	hits atomic.Int32
+	down atomic.Bool
+}
+
+// newTestNode starts a node that answers every request with an empty json
+// object and drops the connection while it is down.
+func newTestNode(t *testing.T, handler http.HandlerFunc) *testNode {
+	n := &testNode{}
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.down.Load() {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		n.hits.Add(1)
+		if handler != nil {
+			handler(w, r)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(n.Close)
+	return n
+}
+
+func TestClientRoundRobin(t *testing.T) {
+	nodes := []*testNode{newTestNode(t, nil), newTestNode(t, nil), newTestNode(t, nil)}
+
+	client := esclient.NewClient(nodes[0].URL, esclient.WithNodes(nodes[1].URL, nodes[2].URL))
+	defer client.Close()
+
+	for i := 0; i < 6; i++ {
+		_, err := client.Ping(context.Background())
+		assert.NoError(t, err)
+	}
+
+	for _, n := range nodes {
+		assert.Equal(t, int32(2), n.hits.Load())
+	}
+}
+
+func TestClientFailsOverDeadNode(t *testing.T) {
+	alive := newTestNode(t, nil)
+	dead := newTestNode(t, nil)
+	dead.down.Store(true)
+
+	client := esclient.NewClient(dead.URL,
+		esclient.WithNodes(alive.URL),
+		esclient.WithRetry(2, esclient.RetryWithBackoff(time.Millisecond, time.Millisecond)),
+		esclient.WithHealthCheck(0),
+	)
+	defer client.Close()
+
+	for i := 0; i < 4; i++ {
+		_, err := client.Ping(context.Background())
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(4), alive.hits.Load())
+	assert.Equal(t, int32(0), dead.hits.Load())
+}
+
+func TestClientResurrectsNode(t *testing.T) {
+	first := newTestNode(t, nil)
+	second := newTestNode(t, nil)
+	second.down.Store(true)
+
+	client := esclient.NewClient(first.URL,
+		esclient.WithNodes(second.URL),
+		esclient.WithHealthCheck(10*time.Millisecond),
+	)
+	defer client.Close()
+
+	client.Ping(context.Background())
+	_, err := client.Ping(context.Background())
+	assert.Error(t, err)
+
+	second.down.Store(false)
+	assert.Eventually(t, func() bool {
+		return second.hits.Load() > 0
+	}, time.Second, 10*time.Millisecond)
+
+	before := second.hits.Load()
+	for i := 0; i < 4; i++ {
+		_, err := client.Ping(context.Background())
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, before+2, second.hits.Load())
+}
+
+func TestClientSniffsNodes(t *testing.T) {
+	first := newTestNode(t, nil)
+	second := newTestNode(t, nil)
+	seed := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_nodes/http" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		fmt.Fprintf(w, `{"nodes":{"a":{"http":{"publish_address":"es01/%s"}},"b":{"http":{"publish_address":"%s"}}}}`,
+			strings.TrimPrefix(first.URL, "http://"), strings.TrimPrefix(second.URL, "http://"))
+	})
+
+	client := esclient.NewClient(seed.URL, esclient.WithSniffer(time.Hour))
+	defer client.Close()
+
+	for i := 0; i < 4; i++ {
+		_, err := client.Ping(context.Background())
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), seed.hits.Load())
+	assert.Equal(t, int32(2), first.hits.Load())
+	assert.Equal(t, int32(2), second.hits.Load())
+}
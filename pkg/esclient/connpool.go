@@ -0,0 +1,155 @@
+package esclient
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNoNodes = errors.New("esclient: no nodes configured")
+
+type node struct {
+	url       *url.URL
+	dead      bool
+	deadSince time.Time
+}
+
+func newNode(rawUrl string) (*node, error) {
+	u, err := url.Parse(strings.TrimRight(rawUrl, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, errors.New("esclient: invalid node url " + rawUrl)
+	}
+	return &node{url: u}, nil
+}
+
+// resolve returns the absolute url of a relative request url on this node,
+// keeping any path prefix of the node url.
+func (n *node) resolve(ref *url.URL) *url.URL {
+	u := *n.url
+	u.Path = strings.TrimRight(n.url.Path, "/") + ref.Path
+	u.RawPath = strings.TrimRight(n.url.EscapedPath(), "/") + ref.EscapedPath()
+	u.RawQuery = ref.RawQuery
+	return &u
+}
+
+// connectionPool round-robins requests across the live nodes of a cluster.
+type connectionPool struct {
+	mu      sync.Mutex
+	nodes   []*node
+	current int
+}
+
+func newConnectionPool(urls []string) (*connectionPool, error) {
+	p := &connectionPool{}
+	for _, rawUrl := range urls {
+		n, err := newNode(rawUrl)
+		if err != nil {
+			return nil, err
+		}
+		p.nodes = append(p.nodes, n)
+	}
+	return p, nil
+}
+
+// next returns the next live node. When every node is dead, the node that
+// died first is returned so that requests still get a chance to go through.
+func (p *connectionPool) next() (*node, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.nodes) == 0 {
+		return nil, ErrNoNodes
+	}
+
+	for range p.nodes {
+		n := p.nodes[p.current%len(p.nodes)]
+		p.current = (p.current + 1) % len(p.nodes)
+		if !n.dead {
+			return n, nil
+		}
+	}
+
+	oldest := p.nodes[0]
+	for _, n := range p.nodes[1:] {
+		if n.deadSince.Before(oldest.deadSince) {
+			oldest = n
+		}
+	}
+	return oldest, nil
+}
+
+func (p *connectionPool) markDead(n *node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !n.dead {
+		n.dead = true
+		n.deadSince = time.Now()
+	}
+}
+
+func (p *connectionPool) markAlive(n *node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n.dead = false
+	n.deadSince = time.Time{}
+}
+
+func (p *connectionPool) deadNodes() []*node {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var dead []*node
+	for _, n := range p.nodes {
+		if n.dead {
+			dead = append(dead, n)
+		}
+	}
+	return dead
+}
+
+func (p *connectionPool) scheme() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.nodes) == 0 {
+		return "http"
+	}
+	return p.nodes[0].url.Scheme
+}
+
+// setNodes replaces the nodes of the pool, keeping the state of the nodes
+// that were already known.
+func (p *connectionPool) setNodes(urls []string) error {
+	nodes := make([]*node, 0, len(urls))
+	for _, rawUrl := range urls {
+		n, err := newNode(rawUrl)
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, n)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	known := make(map[string]*node, len(p.nodes))
+	for _, n := range p.nodes {
+		known[n.url.String()] = n
+	}
+	for i, n := range nodes {
+		if existing, ok := known[n.url.String()]; ok {
+			nodes[i] = existing
+		}
+	}
+
+	p.nodes = nodes
+	p.current = 0
+	return nil
+}
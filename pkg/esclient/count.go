@@ -2,16 +2,17 @@ package esclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"github/shaolim/kakashi/pkg/esclient/esquery"
 	"net/http"
 )
 
 type Count interface {
-	Count(index string, query esquery.QueryType) (*Response[CountResponse], error)
+	Count(ctx context.Context, index string, query esquery.QueryType) (*Response[CountResponse], error)
 }
 
-func (c *client) Count(index string, query esquery.QueryType) (*Response[CountResponse], error) {
+func (c *client) Count(ctx context.Context, index string, query esquery.QueryType) (*Response[CountResponse], error) {
 	queryRequest := esquery.KeyVal{
 		"query": query,
 	}
@@ -21,7 +22,7 @@ func (c *client) Count(index string, query esquery.QueryType) (*Response[CountRe
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", c.baseUrl+"/"+index+"/_count", bytes.NewReader(r))
+	req, err := http.NewRequestWithContext(ctx, "POST", "/"+index+"/_count", bytes.NewReader(r))
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +36,9 @@ func (c *client) Count(index string, query esquery.QueryType) (*Response[CountRe
 	response := &Response[CountResponse]{
 		StatusCode: res.StatusCode,
 	}
-	response.SetBody(res.Body)
+	if err := response.SetBody(res.Body); err != nil {
+		return response, err
+	}
 
 	return response, nil
 }
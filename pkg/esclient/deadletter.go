@@ -0,0 +1,44 @@
+package esclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// DeadLetter is a bulk action that could not be written. Request holds its
+// action and source lines, ready to be sent to the bulk API again.
+type DeadLetter struct {
+	Index    string        `json:"index"`
+	Request  string        `json:"request"`
+	Status   int           `json:"status,omitempty"`
+	Error    *ErrorDetails `json:"error,omitempty"`
+	Attempts int           `json:"attempts"`
+	Time     time.Time     `json:"time"`
+}
+
+// DeadLetterSink stores the bulk actions that failed for good. It is called
+// from the workers of a BulkProcessor and must be safe for concurrent use.
+type DeadLetterSink interface {
+	Write(ctx context.Context, letter *DeadLetter) error
+}
+
+// JSONLDeadLetterSink writes each dead letter as a line of JSON.
+type JSONLDeadLetterSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func NewJSONLDeadLetterSink(w io.Writer) *JSONLDeadLetterSink {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &JSONLDeadLetterSink{encoder: encoder}
+}
+
+func (s *JSONLDeadLetterSink) Write(_ context.Context, letter *DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(letter)
+}
@@ -0,0 +1,348 @@
+package esclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type Document interface {
+	GetDocument(ctx context.Context, index, id string, options ...documentOptions) (*Response[GetResult], error)
+	DocumentExists(ctx context.Context, index, id string, options ...documentOptions) (bool, error)
+	IndexDocument(ctx context.Context, index, id string, doc interface{}, options ...documentOptions) (*Response[DocumentResult], error)
+	CreateDocument(ctx context.Context, index, id string, doc interface{}, options ...documentOptions) (*Response[DocumentResult], error)
+	UpdateDocument(ctx context.Context, index, id string, update *updateDocumentRequest, options ...documentOptions) (*Response[DocumentResult], error)
+	DeleteDocument(ctx context.Context, index, id string, options ...documentOptions) (*Response[DocumentResult], error)
+}
+
+type GetResult struct {
+	Index       string                 `json:"_index"`
+	Id          string                 `json:"_id"`
+	Version     int64                  `json:"_version,omitempty"`
+	SeqNo       int64                  `json:"_seq_no,omitempty"`
+	PrimaryTerm int64                  `json:"_primary_term,omitempty"`
+	Routing     string                 `json:"_routing,omitempty"`
+	Found       bool                   `json:"found"`
+	Source      json.RawMessage        `json:"_source,omitempty"`
+	Fields      map[string]interface{} `json:"fields,omitempty"`
+}
+
+// DecodeSource decodes the stored document source into v.
+func (r *GetResult) DecodeSource(v interface{}) error {
+	return json.Unmarshal(r.Source, v)
+}
+
+type DocumentResult struct {
+	Index         string      `json:"_index"`
+	Id            string      `json:"_id"`
+	Version       int64       `json:"_version,omitempty"`
+	Result        string      `json:"result,omitempty"` // created, updated, deleted, not_found or noop
+	Shards        *ShardsInfo `json:"_shards,omitempty"`
+	SeqNo         int64       `json:"_seq_no,omitempty"`
+	PrimaryTerm   int64       `json:"_primary_term,omitempty"`
+	ForcedRefresh bool        `json:"forced_refresh,omitempty"`
+	Get           *GetResult  `json:"get,omitempty"` // only returned by updates asking for _source
+}
+
+type Refresh string
+
+const (
+	RefreshTrue    Refresh = "true"
+	RefreshFalse   Refresh = "false"
+	RefreshWaitFor Refresh = "wait_for"
+)
+
+type documentOptions func(*documentParams)
+
+type documentParams struct {
+	refresh         Refresh
+	routing         string
+	version         *int64
+	versionType     string
+	ifSeqNo         *int64
+	ifPrimaryTerm   *int64
+	pipeline        string
+	retryOnConflict *int
+	sourceIncludes  []string
+	sourceExcludes  []string
+	timeout         string
+}
+
+func DocumentWithRefresh(refresh Refresh) documentOptions {
+	return func(params *documentParams) {
+		params.refresh = refresh
+	}
+}
+
+func DocumentWithRouting(routing string) documentOptions {
+	return func(params *documentParams) {
+		params.routing = routing
+	}
+}
+
+func DocumentWithVersion(version int64) documentOptions {
+	return func(params *documentParams) {
+		params.version = &version
+	}
+}
+
+// valid version types: ["internal", "external", "external_gte"]
+func DocumentWithVersionType(versionType string) documentOptions {
+	return func(params *documentParams) {
+		params.versionType = versionType
+	}
+}
+
+// DocumentWithIfSeqNo only applies the write when the document still has the
+// given sequence number and primary term.
+func DocumentWithIfSeqNo(seqNo, primaryTerm int64) documentOptions {
+	return func(params *documentParams) {
+		params.ifSeqNo = &seqNo
+		params.ifPrimaryTerm = &primaryTerm
+	}
+}
+
+func DocumentWithPipeline(pipeline string) documentOptions {
+	return func(params *documentParams) {
+		params.pipeline = pipeline
+	}
+}
+
+func DocumentWithRetryOnConflict(retryOnConflict int) documentOptions {
+	return func(params *documentParams) {
+		params.retryOnConflict = &retryOnConflict
+	}
+}
+
+func DocumentWithSourceIncludes(fields ...string) documentOptions {
+	return func(params *documentParams) {
+		params.sourceIncludes = append(params.sourceIncludes, fields...)
+	}
+}
+
+func DocumentWithSourceExcludes(fields ...string) documentOptions {
+	return func(params *documentParams) {
+		params.sourceExcludes = append(params.sourceExcludes, fields...)
+	}
+}
+
+func DocumentWithTimeout(timeout string) documentOptions {
+	return func(params *documentParams) {
+		params.timeout = timeout
+	}
+}
+
+func newDocumentParams(options []documentOptions) *documentParams {
+	params := &documentParams{}
+	for _, option := range options {
+		option(params)
+	}
+	return params
+}
+
+func (p *documentParams) query() url.Values {
+	q := url.Values{}
+	if p.refresh != "" {
+		q.Set("refresh", string(p.refresh))
+	}
+	if p.routing != "" {
+		q.Set("routing", p.routing)
+	}
+	if p.version != nil {
+		q.Set("version", strconv.FormatInt(*p.version, 10))
+	}
+	if p.versionType != "" {
+		q.Set("version_type", p.versionType)
+	}
+	if p.ifSeqNo != nil {
+		q.Set("if_seq_no", strconv.FormatInt(*p.ifSeqNo, 10))
+	}
+	if p.ifPrimaryTerm != nil {
+		q.Set("if_primary_term", strconv.FormatInt(*p.ifPrimaryTerm, 10))
+	}
+	if p.pipeline != "" {
+		q.Set("pipeline", p.pipeline)
+	}
+	if p.retryOnConflict != nil {
+		q.Set("retry_on_conflict", strconv.Itoa(*p.retryOnConflict))
+	}
+	if len(p.sourceIncludes) > 0 {
+		q.Set("_source_includes", strings.Join(p.sourceIncludes, ","))
+	}
+	if len(p.sourceExcludes) > 0 {
+		q.Set("_source_excludes", strings.Join(p.sourceExcludes, ","))
+	}
+	if p.timeout != "" {
+		q.Set("timeout", p.timeout)
+	}
+	return q
+}
+
+func documentUrl(index, endpoint, id string, query url.Values) string {
+	uri := "/" + index + "/" + endpoint
+	if id != "" {
+		uri += "/" + url.PathEscape(id)
+	}
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
+	return uri
+}
+
+// Response codes `200`, `404`
+// `404` is returned as an *Error if the document does not exist
+func (c *client) GetDocument(ctx context.Context, index, id string, options ...documentOptions) (*Response[GetResult], error) {
+	params := newDocumentParams(options)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", documentUrl(index, "_doc", id, params.query()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[GetResult](c, req)
+}
+
+func (c *client) DocumentExists(ctx context.Context, index, id string, options ...documentOptions) (bool, error) {
+	params := newDocumentParams(options)
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", documentUrl(index, "_doc", id, params.query()), nil)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = execute[struct{}](c, req)
+	if err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// IndexDocument creates or replaces a document. An empty id lets
+// elasticsearch generate one.
+func (c *client) IndexDocument(ctx context.Context, index, id string, doc interface{}, options ...documentOptions) (*Response[DocumentResult], error) {
+	params := newDocumentParams(options)
+
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	method := "PUT"
+	if id == "" {
+		method = "POST"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, documentUrl(index, "_doc", id, params.query()), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[DocumentResult](c, req)
+}
+
+// CreateDocument indexes a document only if it does not exist yet,
+// a `409` *Error is returned otherwise.
+func (c *client) CreateDocument(ctx context.Context, index, id string, doc interface{}, options ...documentOptions) (*Response[DocumentResult], error) {
+	params := newDocumentParams(options)
+
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", documentUrl(index, "_create", id, params.query()), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[DocumentResult](c, req)
+}
+
+func (c *client) UpdateDocument(ctx context.Context, index, id string, update *updateDocumentRequest, options ...documentOptions) (*Response[DocumentResult], error) {
+	params := newDocumentParams(options)
+
+	body, err := json.Marshal(update)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", documentUrl(index, "_update", id, params.query()), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[DocumentResult](c, req)
+}
+
+func (c *client) DeleteDocument(ctx context.Context, index, id string, options ...documentOptions) (*Response[DocumentResult], error) {
+	params := newDocumentParams(options)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", documentUrl(index, "_doc", id, params.query()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[DocumentResult](c, req)
+}
+
+type updateDocumentRequest struct {
+	Doc            interface{}     `json:"doc,omitempty"`
+	Script         *esquery.Script `json:"script,omitempty"`
+	Upsert         interface{}     `json:"upsert,omitempty"`
+	DocAsUpsert    *bool           `json:"doc_as_upsert,omitempty"`
+	ScriptedUpsert *bool           `json:"scripted_upsert,omitempty"`
+	DetectNoop     *bool           `json:"detect_noop,omitempty"`
+	Source         interface{}     `json:"_source,omitempty"`
+}
+
+func NewUpdateDocumentRequest() *updateDocumentRequest {
+	return &updateDocumentRequest{}
+}
+
+// SetDoc sets the partial document merged into the existing one.
+func (u *updateDocumentRequest) SetDoc(doc interface{}) *updateDocumentRequest {
+	u.Doc = doc
+	return u
+}
+
+func (u *updateDocumentRequest) SetScript(script *esquery.Script) *updateDocumentRequest {
+	u.Script = script
+	return u
+}
+
+// SetUpsert sets the document indexed when the document does not exist yet.
+func (u *updateDocumentRequest) SetUpsert(upsert interface{}) *updateDocumentRequest {
+	u.Upsert = upsert
+	return u
+}
+
+func (u *updateDocumentRequest) SetDocAsUpsert(docAsUpsert bool) *updateDocumentRequest {
+	u.DocAsUpsert = &docAsUpsert
+	return u
+}
+
+func (u *updateDocumentRequest) SetScriptedUpsert(scriptedUpsert bool) *updateDocumentRequest {
+	u.ScriptedUpsert = &scriptedUpsert
+	return u
+}
+
+func (u *updateDocumentRequest) SetDetectNoop(detectNoop bool) *updateDocumentRequest {
+	u.DetectNoop = &detectNoop
+	return u
+}
+
+// SetSource returns the updated source in DocumentResult.Get, either a bool
+// or a list of fields.
+func (u *updateDocumentRequest) SetSource(source interface{}) *updateDocumentRequest {
+	u.Source = source
+	return u
+}
@@ -0,0 +1,161 @@
+package esclient_test
+
+import (
+	"context"
+	"github/shaolim/kakashi/pkg/esclient"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedRequest struct {
+	method string
+	uri    string
+	body   string
+}
+
+func newRecordingServer(t *testing.T, status int, response string) (*httptest.Server, *recordedRequest) {
+	recorded := &recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		recorded.method = r.Method
+		recorded.uri = r.URL.RequestURI()
+		recorded.body = string(body)
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, recorded
+}
+
+func TestGetDocument(t *testing.T) {
+	server, recorded := newRecordingServer(t, 200, `{
+		"_index": "item_index_ja",
+		"_id": "SKU/1",
+		"_version": 3,
+		"_seq_no": 10,
+		"_primary_term": 1,
+		"found": true,
+		"_source": {"sku": "SKU/1", "title": "シャツ"}
+	}`)
+
+	client := esclient.NewClient(server.URL)
+	res, err := client.GetDocument(context.Background(), "item_index_ja", "SKU/1",
+		esclient.DocumentWithRouting("shop1"),
+		esclient.DocumentWithSourceIncludes("sku", "title"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "GET", recorded.method)
+	assert.Equal(t, "/item_index_ja/_doc/SKU%2F1?_source_includes=sku%2Ctitle&routing=shop1", recorded.uri)
+	assert.True(t, res.Result.Found)
+	assert.Equal(t, int64(10), res.Result.SeqNo)
+	assert.Equal(t, int64(1), res.Result.PrimaryTerm)
+
+	var doc struct {
+		Sku   string `json:"sku"`
+		Title string `json:"title"`
+	}
+	assert.NoError(t, res.Result.DecodeSource(&doc))
+	assert.Equal(t, "シャツ", doc.Title)
+}
+
+func TestGetDocumentNotFound(t *testing.T) {
+	server, _ := newRecordingServer(t, 404, `{"_index":"item_index_ja","_id":"1","found":false}`)
+
+	client := esclient.NewClient(server.URL)
+	_, err := client.GetDocument(context.Background(), "item_index_ja", "1")
+	assert.True(t, esclient.IsNotFound(err))
+	assert.EqualError(t, err, "elasticsearch: 404 Not Found")
+
+	exists, err := client.DocumentExists(context.Background(), "item_index_ja", "1")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestWriteDocument(t *testing.T) {
+	writeResponse := `{"_index":"products","_id":"1","_version":2,"result":"updated","_seq_no":5,"_primary_term":1}`
+	doc := map[string]interface{}{"name": "Laptop"}
+
+	tests := []struct {
+		name         string
+		call         func(client esclient.Client) (*esclient.Response[esclient.DocumentResult], error)
+		expectedReq  recordedRequest
+		expectedBody string
+	}{
+		{
+			name: "index",
+			call: func(client esclient.Client) (*esclient.Response[esclient.DocumentResult], error) {
+				return client.IndexDocument(context.Background(), "products", "1", doc,
+					esclient.DocumentWithRefresh(esclient.RefreshWaitFor),
+					esclient.DocumentWithIfSeqNo(4, 1))
+			},
+			expectedReq:  recordedRequest{method: "PUT", uri: "/products/_doc/1?if_primary_term=1&if_seq_no=4&refresh=wait_for"},
+			expectedBody: `{"name":"Laptop"}`,
+		},
+		{
+			name: "index with generated id",
+			call: func(client esclient.Client) (*esclient.Response[esclient.DocumentResult], error) {
+				return client.IndexDocument(context.Background(), "products", "", doc)
+			},
+			expectedReq:  recordedRequest{method: "POST", uri: "/products/_doc"},
+			expectedBody: `{"name":"Laptop"}`,
+		},
+		{
+			name: "create",
+			call: func(client esclient.Client) (*esclient.Response[esclient.DocumentResult], error) {
+				return client.CreateDocument(context.Background(), "products", "1", doc, esclient.DocumentWithPipeline("enrich"))
+			},
+			expectedReq:  recordedRequest{method: "PUT", uri: "/products/_create/1?pipeline=enrich"},
+			expectedBody: `{"name":"Laptop"}`,
+		},
+		{
+			name: "update with script and upsert",
+			call: func(client esclient.Client) (*esclient.Response[esclient.DocumentResult], error) {
+				return client.UpdateDocument(context.Background(), "products", "1",
+					esclient.NewUpdateDocumentRequest().
+						SetScript(esquery.NewScript("ctx._source.price = params.price").SetParam("price", 10)).
+						SetUpsert(doc),
+					esclient.DocumentWithRetryOnConflict(3))
+			},
+			expectedReq:  recordedRequest{method: "POST", uri: "/products/_update/1?retry_on_conflict=3"},
+			expectedBody: `{"script":{"source":"ctx._source.price = params.price","params":{"price":10}},"upsert":{"name":"Laptop"}}`,
+		},
+		{
+			name: "update with doc as upsert",
+			call: func(client esclient.Client) (*esclient.Response[esclient.DocumentResult], error) {
+				return client.UpdateDocument(context.Background(), "products", "1",
+					esclient.NewUpdateDocumentRequest().SetDoc(doc).SetDocAsUpsert(true).SetDetectNoop(false))
+			},
+			expectedReq:  recordedRequest{method: "POST", uri: "/products/_update/1"},
+			expectedBody: `{"doc":{"name":"Laptop"},"doc_as_upsert":true,"detect_noop":false}`,
+		},
+		{
+			name: "delete",
+			call: func(client esclient.Client) (*esclient.Response[esclient.DocumentResult], error) {
+				return client.DeleteDocument(context.Background(), "products", "1",
+					esclient.DocumentWithVersion(7), esclient.DocumentWithVersionType("external"))
+			},
+			expectedReq: recordedRequest{method: "DELETE", uri: "/products/_doc/1?version=7&version_type=external"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, recorded := newRecordingServer(t, 200, writeResponse)
+
+			res, err := test.call(esclient.NewClient(server.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedReq.method, recorded.method)
+			assert.Equal(t, test.expectedReq.uri, recorded.uri)
+			if test.expectedBody != "" {
+				assert.JSONEq(t, test.expectedBody, recorded.body)
+			}
+			assert.Equal(t, int64(5), res.Result.SeqNo)
+			assert.Equal(t, "updated", res.Result.Result)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package esclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Error is returned by every client call when elasticsearch answers with a non 2xx status.
+type Error struct {
+	Status  int           `json:"status"`
+	Details *ErrorDetails `json:"error,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Details == nil || (e.Details.Type == "" && e.Details.Reason == "") {
+		return fmt.Sprintf("elasticsearch: %d %s", e.Status, http.StatusText(e.Status))
+	}
+	if e.Details.Type == "" {
+		return fmt.Sprintf("elasticsearch: %d %s", e.Status, e.Details.Reason)
+	}
+	return fmt.Sprintf("elasticsearch: %d [%s] %s", e.Status, e.Details.Type, e.Details.Reason)
+}
+
+// Type returns the error type, e.g. "index_not_found_exception".
+func (e *Error) Type() string {
+	if e.Details == nil {
+		return ""
+	}
+	return e.Details.Type
+}
+
+type ErrorDetails struct {
+	Type         string          `json:"type"`
+	Reason       string          `json:"reason"`
+	IndexUUID    string          `json:"index_uuid,omitempty"`
+	Index        string          `json:"index,omitempty"`
+	Shard        json.Number     `json:"shard,omitempty"`
+	ResourceType string          `json:"resource.type,omitempty"`
+	ResourceId   string          `json:"resource.id,omitempty"`
+	RootCause    []*ErrorDetails `json:"root_cause,omitempty"`
+	CausedBy     *ErrorDetails   `json:"caused_by,omitempty"`
+	FailedShards []*FailedShard  `json:"failed_shards,omitempty"`
+}
+
+type FailedShard struct {
+	Shard  int           `json:"shard"`
+	Index  string        `json:"index,omitempty"`
+	Node   string        `json:"node,omitempty"`
+	Reason *ErrorDetails `json:"reason,omitempty"`
+}
+
+// parseError builds an *Error from an error response body. Elasticsearch
+// usually returns {"error":{...},"status":n}, but proxies answer with plain
+// text, which is kept as the reason. Other json bodies such as the
+// {"found":false} of the get API carry no details.
+func parseError(statusCode int, body io.Reader) *Error {
+	e := &Error{Status: statusCode}
+	if body == nil {
+		return e
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil || len(data) == 0 {
+		return e
+	}
+
+	var raw struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		e.Details = &ErrorDetails{Reason: strings.TrimSpace(string(data))}
+		return e
+	}
+	if len(raw.Error) == 0 {
+		return e
+	}
+
+	var details ErrorDetails
+	if err := json.Unmarshal(raw.Error, &details); err == nil {
+		e.Details = &details
+		return e
+	}
+
+	var reason string
+	if err := json.Unmarshal(raw.Error, &reason); err == nil {
+		e.Details = &ErrorDetails{Reason: reason}
+		return e
+	}
+
+	e.Details = &ErrorDetails{Reason: string(raw.Error)}
+	return e
+}
+
+func asError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+func IsNotFound(err error) bool {
+	e, ok := asError(err)
+	return ok && e.Status == http.StatusNotFound
+}
+
+func IsConflict(err error) bool {
+	e, ok := asError(err)
+	return ok && e.Status == http.StatusConflict
+}
+
+func IsTooManyRequests(err error) bool {
+	e, ok := asError(err)
+	return ok && e.Status == http.StatusTooManyRequests
+}
+
+func IsResourceAlreadyExists(err error) bool {
+	e, ok := asError(err)
+	return ok && e.Type() == "resource_already_exists_exception"
+}
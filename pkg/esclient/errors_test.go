@@ -0,0 +1,83 @@
+package esclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github/shaolim/kakashi/pkg/esclient"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{
+			"error": {
+				"root_cause": [
+					{
+						"type": "resource_already_exists_exception",
+						"reason": "index [products/abc] already exists",
+						"index_uuid": "abc",
+						"index": "products"
+					}
+				],
+				"type": "resource_already_exists_exception",
+				"reason": "index [products/abc] already exists",
+				"index_uuid": "abc",
+				"index": "products"
+			},
+			"status": 400
+		}`))
+	}))
+	defer server.Close()
+
+	client := esclient.NewClient(server.URL)
+	res, err := client.CreateIndex(context.Background(), "products", strings.NewReader(`{}`))
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Nil(t, res.Result)
+
+	var esErr *esclient.Error
+	assert.True(t, errors.As(fmt.Errorf("create index: %w", err), &esErr))
+	assert.Equal(t, http.StatusBadRequest, esErr.Status)
+	assert.Equal(t, "resource_already_exists_exception", esErr.Type())
+	assert.Len(t, esErr.Details.RootCause, 1)
+	assert.Equal(t, "products", esErr.Details.RootCause[0].Index)
+	assert.True(t, esclient.IsResourceAlreadyExists(err))
+	assert.False(t, esclient.IsNotFound(err))
+}
+
+func TestErrorHelpers(t *testing.T) {
+	tests := []struct {
+		status  int
+		body    string
+		check   func(error) bool
+		message string
+	}{
+		{status: 404, body: "", check: esclient.IsNotFound, message: "elasticsearch: 404 Not Found"},
+		{status: 409, body: `{"error":{"type":"version_conflict_engine_exception","reason":"version conflict","shard":"0"},"status":409}`,
+			check: esclient.IsConflict, message: "elasticsearch: 409 [version_conflict_engine_exception] version conflict"},
+		{status: 429, body: `{"error":"too many requests","status":429}`, check: esclient.IsTooManyRequests,
+			message: "elasticsearch: 429 too many requests"},
+		{status: 502, body: `<html>bad gateway</html>`, check: func(err error) bool { return err != nil },
+			message: "elasticsearch: 502 <html>bad gateway</html>"},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+			w.Write([]byte(test.body))
+		}))
+
+		_, err := esclient.NewClient(server.URL).Ping(context.Background())
+		assert.True(t, test.check(err))
+		assert.EqualError(t, err, test.message)
+
+		server.Close()
+	}
+}
@@ -1,9 +1,13 @@
 package esclient
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"sync"
+	"time"
 )
 
 type Client interface {
@@ -12,13 +16,32 @@ type Client interface {
 	Bulk
 	Search
 	Count
+	Document
+	MultiGet
+	Scroll
+	ValidateQuery
+	Explain
+
+	// Close stops the background health checks and node sniffing.
+	Close() error
 }
 
 type client struct {
 	httpClient *http.Client
-	baseUrl    string
-	username   string
-	password   string
+	urls       []string
+	pool       *connectionPool
+	err        error
+	auth       Authenticator
+	tls        tlsSettings
+	retry      *retryPolicy
+	bulkGzip   bool
+
+	healthCheckInterval time.Duration
+	sniffInterval       time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 type ClientOption func(*client)
@@ -29,64 +52,132 @@ func WithHttpClient(httpClient *http.Client) ClientOption {
 	}
 }
 
-func WithBasicAuth(username, password string) ClientOption {
-	return func(c *client) {
-		c.username = username
-		c.password = password
-	}
-}
-
 func NewClient(baseUrl string, options ...ClientOption) Client {
 	c := &client{
-		baseUrl: baseUrl,
+		urls:                []string{baseUrl},
+		healthCheckInterval: defaultHealthCheckInterval,
 	}
 
 	for _, option := range options {
 		option(c)
 	}
 
+	if c.err == nil {
+		c.err = c.configureTLS()
+	}
+
 	if c.httpClient == nil {
 		c.httpClient = &http.Client{}
 	}
 
+	if c.err == nil {
+		c.pool, c.err = newConnectionPool(c.urls)
+	}
+
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	if c.err == nil {
+		c.startMaintenance()
+	}
+
 	return c
 }
 
+func (c *client) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+	return nil
+}
+
 func (c *client) do(req *http.Request) (*http.Response, error) {
 	if len(req.Header) == 0 {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	if c.username != "" && c.password != "" {
-		req.SetBasicAuth(c.username, c.password)
+	if c.auth != nil {
+		if err := c.auth.Authenticate(req); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.retry != nil {
+		return c.retry.do(c.perform, req)
 	}
 
-	// TODO(Michael): add retry mechanism
-	return c.httpClient.Do(req)
+	return c.perform(req)
+}
+
+// perform sends the request to the next live node. Requests are built with a
+// relative url which is resolved against the node on every attempt.
+func (c *client) perform(req *http.Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	n, err := c.pool.next()
+	if err != nil {
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	r.URL = n.resolve(req.URL)
+	r.Host = ""
+
+	res, err := c.httpClient.Do(r)
+	if err != nil {
+		if req.Context().Err() == nil && !isBodyError(err) {
+			c.pool.markDead(n)
+		}
+		return nil, err
+	}
+	c.pool.markAlive(n)
+
+	return res, nil
+}
+
+// bodyError is a failure to produce a streamed request body, e.g. a document
+// that cannot be encoded. It says nothing about the node the body was sent to.
+type bodyError struct {
+	err error
+}
+
+func (e *bodyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *bodyError) Unwrap() error {
+	return e.err
+}
+
+func isBodyError(err error) bool {
+	var bodyErr *bodyError
+	return errors.As(err, &bodyErr)
 }
 
 type Response[T any] struct {
-	StatusCode   int
-	ErrorMessage string
-	Result       *T
+	StatusCode int
+	Error      *Error
+	Result     *T
 }
 
+// SetBody decodes the response body into Result. For error statuses the body
+// is decoded into Error instead, which is also returned.
 func (r *Response[T]) SetBody(body io.ReadCloser) error {
-	if body == nil {
-		return nil
-	}
-
 	if r.IsError() {
-		errorMessages, err := parseCloserToString(body)
-		if err != nil {
-			return err
-		}
+		r.Error = parseError(r.StatusCode, body)
+		return r.Error
+	}
 
-		r.ErrorMessage = errorMessages
+	if body == nil {
+		return nil
 	}
 
 	var result T
 	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
 		return err
 	}
 	r.Result = &result
@@ -94,6 +185,24 @@ func (r *Response[T]) SetBody(body io.ReadCloser) error {
 	return nil
 }
 
+// execute sends the request and decodes the response into a Response[T].
+func execute[T any](c *client, req *http.Request) (*Response[T], error) {
+	res, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	response := &Response[T]{
+		StatusCode: res.StatusCode,
+	}
+	if err := response.SetBody(res.Body); err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
 func (r *Response[T]) String() string {
 	str, _ := json.Marshal(r.Result)
 	return string(str)
@@ -102,23 +211,3 @@ func (r *Response[T]) String() string {
 func (r *Response[T]) IsError() bool {
 	return r.StatusCode > 299
 }
-
-func parseCloserToString(r io.ReadCloser) (string, error) {
-	body, err := io.ReadAll(r)
-	if err != nil {
-		return "", nil
-	}
-
-	var data interface{}
-	err = json.Unmarshal(body, &data)
-	if err != nil {
-		return "", err
-	}
-
-	jsonBytes, err := json.Marshal(data)
-	if err != nil {
-		return "", err
-	}
-
-	return string(jsonBytes), nil
-}
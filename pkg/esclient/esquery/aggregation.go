@@ -0,0 +1,444 @@
+package esquery
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+)
+
+type Aggregation interface {
+	json.Marshaler
+}
+
+func marshalAggregation(kind string, body interface{}, aggs map[string]Aggregation) ([]byte, error) {
+	agg := KeyVal{
+		kind: body,
+	}
+	if len(aggs) > 0 {
+		agg["aggs"] = aggs
+	}
+	return json.Marshal(agg)
+}
+
+func addAggregation(aggs map[string]Aggregation, name string, agg Aggregation) map[string]Aggregation {
+	if aggs == nil {
+		aggs = make(map[string]Aggregation)
+	}
+	aggs[name] = agg
+	return aggs
+}
+
+type termsAggregation struct {
+	Field       string                 `json:"field"`
+	Size        *int                   `json:"size,omitempty"`
+	ShardSize   *int                   `json:"shard_size,omitempty"`
+	MinDocCount *int                   `json:"min_doc_count,omitempty"`
+	Missing     interface{}            `json:"missing,omitempty"`
+	Order       []KeyVal               `json:"order,omitempty"`
+	Include     interface{}            `json:"include,omitempty"`
+	Exclude     interface{}            `json:"exclude,omitempty"`
+	Aggs        map[string]Aggregation `json:"-"`
+}
+
+func TermsAgg(field string) *termsAggregation {
+	return &termsAggregation{Field: field}
+}
+
+func (t *termsAggregation) MarshalJSON() ([]byte, error) {
+	return marshalAggregation("terms", *t, t.Aggs)
+}
+
+func (t *termsAggregation) Validate() error {
+	if err := validateField("terms aggregation", t.Field); err != nil {
+		return err
+	}
+	if t.Size != nil && *t.Size <= 0 {
+		return invalid("terms aggregation", "size must be positive")
+	}
+	if t.ShardSize != nil && t.Size != nil && *t.ShardSize < *t.Size {
+		return invalid("terms aggregation", "shard_size is less than size")
+	}
+	return validateAggregations(t.Aggs)
+}
+
+func (t *termsAggregation) SetSize(size int) *termsAggregation {
+	t.Size = &size
+	return t
+}
+
+func (t *termsAggregation) SetShardSize(shardSize int) *termsAggregation {
+	t.ShardSize = &shardSize
+	return t
+}
+
+func (t *termsAggregation) SetMinDocCount(minDocCount int) *termsAggregation {
+	t.MinDocCount = &minDocCount
+	return t
+}
+
+// SetMissing puts the documents without the field in a bucket with this key.
+func (t *termsAggregation) SetMissing(missing interface{}) *termsAggregation {
+	t.Missing = missing
+	return t
+}
+
+// SetOrder sorts the buckets by "_count", "_key" or a sub aggregation name.
+func (t *termsAggregation) SetOrder(key string, order Order) *termsAggregation {
+	t.Order = append(t.Order, KeyVal{key: order})
+	return t
+}
+
+func (t *termsAggregation) SetInclude(values ...string) *termsAggregation {
+	t.Include = values
+	return t
+}
+
+func (t *termsAggregation) SetIncludePattern(pattern string) *termsAggregation {
+	t.Include = pattern
+	return t
+}
+
+func (t *termsAggregation) SetExclude(values ...string) *termsAggregation {
+	t.Exclude = values
+	return t
+}
+
+func (t *termsAggregation) SetExcludePattern(pattern string) *termsAggregation {
+	t.Exclude = pattern
+	return t
+}
+
+func (t *termsAggregation) SetSubAggregation(name string, agg Aggregation) *termsAggregation {
+	t.Aggs = addAggregation(t.Aggs, name, agg)
+	return t
+}
+
+type rangeAggregation struct {
+	Field  string                 `json:"field"`
+	Ranges []aggregationRange     `json:"ranges"`
+	Keyed  bool                   `json:"keyed,omitempty"`
+	Aggs   map[string]Aggregation `json:"-"`
+}
+
+type aggregationRange struct {
+	Key  string      `json:"key,omitempty"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+func RangeAgg(field string) *rangeAggregation {
+	return &rangeAggregation{Field: field}
+}
+
+func (r *rangeAggregation) MarshalJSON() ([]byte, error) {
+	return marshalAggregation("range", *r, r.Aggs)
+}
+
+func (r *rangeAggregation) Validate() error {
+	if err := validateField("range aggregation", r.Field); err != nil {
+		return err
+	}
+	if len(r.Ranges) == 0 {
+		return invalid("range aggregation", "no ranges")
+	}
+	for _, bucket := range r.Ranges {
+		if bucket.From == nil && bucket.To == nil {
+			return invalid("range aggregation", "range without from and to")
+		}
+	}
+	return validateAggregations(r.Aggs)
+}
+
+// AddRange adds a bucket from (inclusive) to (exclusive), nil leaves a side unbounded.
+func (r *rangeAggregation) AddRange(from, to interface{}) *rangeAggregation {
+	r.Ranges = append(r.Ranges, aggregationRange{From: from, To: to})
+	return r
+}
+
+func (r *rangeAggregation) AddKeyedRange(key string, from, to interface{}) *rangeAggregation {
+	r.Ranges = append(r.Ranges, aggregationRange{Key: key, From: from, To: to})
+	return r
+}
+
+func (r *rangeAggregation) SetSubAggregation(name string, agg Aggregation) *rangeAggregation {
+	r.Aggs = addAggregation(r.Aggs, name, agg)
+	return r
+}
+
+type extendedBounds struct {
+	Min interface{} `json:"min"`
+	Max interface{} `json:"max"`
+}
+
+type histogramAggregation struct {
+	Field          string                 `json:"field"`
+	Interval       float64                `json:"interval"`
+	Offset         *float64               `json:"offset,omitempty"`
+	MinDocCount    *int                   `json:"min_doc_count,omitempty"`
+	ExtendedBounds *extendedBounds        `json:"extended_bounds,omitempty"`
+	Missing        interface{}            `json:"missing,omitempty"`
+	Aggs           map[string]Aggregation `json:"-"`
+}
+
+func HistogramAgg(field string, interval float64) *histogramAggregation {
+	return &histogramAggregation{Field: field, Interval: interval}
+}
+
+func (h *histogramAggregation) MarshalJSON() ([]byte, error) {
+	return marshalAggregation("histogram", *h, h.Aggs)
+}
+
+func (h *histogramAggregation) Validate() error {
+	if err := validateField("histogram aggregation", h.Field); err != nil {
+		return err
+	}
+	if h.Interval <= 0 {
+		return invalid("histogram aggregation", "interval must be positive")
+	}
+	return validateAggregations(h.Aggs)
+}
+
+func (h *histogramAggregation) SetOffset(offset float64) *histogramAggregation {
+	h.Offset = &offset
+	return h
+}
+
+func (h *histogramAggregation) SetMinDocCount(minDocCount int) *histogramAggregation {
+	h.MinDocCount = &minDocCount
+	return h
+}
+
+func (h *histogramAggregation) SetExtendedBounds(min, max float64) *histogramAggregation {
+	h.ExtendedBounds = &extendedBounds{Min: min, Max: max}
+	return h
+}
+
+func (h *histogramAggregation) SetMissing(missing interface{}) *histogramAggregation {
+	h.Missing = missing
+	return h
+}
+
+func (h *histogramAggregation) SetSubAggregation(name string, agg Aggregation) *histogramAggregation {
+	h.Aggs = addAggregation(h.Aggs, name, agg)
+	return h
+}
+
+type dateHistogramAggregation struct {
+	Field            string                 `json:"field"`
+	CalendarInterval string                 `json:"calendar_interval,omitempty"`
+	FixedInterval    string                 `json:"fixed_interval,omitempty"`
+	Format           string                 `json:"format,omitempty"`
+	TimeZone         string                 `json:"time_zone,omitempty"`
+	Offset           string                 `json:"offset,omitempty"`
+	MinDocCount      *int                   `json:"min_doc_count,omitempty"`
+	ExtendedBounds   *extendedBounds        `json:"extended_bounds,omitempty"`
+	Aggs             map[string]Aggregation `json:"-"`
+}
+
+func DateHistogramAgg(field string) *dateHistogramAggregation {
+	return &dateHistogramAggregation{Field: field}
+}
+
+func (d *dateHistogramAggregation) MarshalJSON() ([]byte, error) {
+	return marshalAggregation("date_histogram", *d, d.Aggs)
+}
+
+func (d *dateHistogramAggregation) Validate() error {
+	if err := validateField("date_histogram aggregation", d.Field); err != nil {
+		return err
+	}
+	if (d.CalendarInterval == "") == (d.FixedInterval == "") {
+		return invalid("date_histogram aggregation", "needs either calendar_interval or fixed_interval")
+	}
+	return validateAggregations(d.Aggs)
+}
+
+// SetCalendarInterval sets a calendar aware interval, e.g. "day", "month" or "1q".
+func (d *dateHistogramAggregation) SetCalendarInterval(interval string) *dateHistogramAggregation {
+	d.CalendarInterval = interval
+	return d
+}
+
+// SetFixedInterval sets a fixed interval, e.g. "90m" or "7d".
+func (d *dateHistogramAggregation) SetFixedInterval(interval string) *dateHistogramAggregation {
+	d.FixedInterval = interval
+	return d
+}
+
+func (d *dateHistogramAggregation) SetFormat(format string) *dateHistogramAggregation {
+	d.Format = format
+	return d
+}
+
+func (d *dateHistogramAggregation) SetTimeZone(timeZone string) *dateHistogramAggregation {
+	d.TimeZone = timeZone
+	return d
+}
+
+func (d *dateHistogramAggregation) SetOffset(offset string) *dateHistogramAggregation {
+	d.Offset = offset
+	return d
+}
+
+func (d *dateHistogramAggregation) SetMinDocCount(minDocCount int) *dateHistogramAggregation {
+	d.MinDocCount = &minDocCount
+	return d
+}
+
+func (d *dateHistogramAggregation) SetExtendedBounds(min, max interface{}) *dateHistogramAggregation {
+	d.ExtendedBounds = &extendedBounds{Min: min, Max: max}
+	return d
+}
+
+func (d *dateHistogramAggregation) SetSubAggregation(name string, agg Aggregation) *dateHistogramAggregation {
+	d.Aggs = addAggregation(d.Aggs, name, agg)
+	return d
+}
+
+type filterAggregation struct {
+	Filter QueryType
+	Aggs   map[string]Aggregation
+}
+
+func FilterAgg(filter QueryType) *filterAggregation {
+	return &filterAggregation{Filter: filter}
+}
+
+func (f *filterAggregation) MarshalJSON() ([]byte, error) {
+	return marshalAggregation("filter", f.Filter, f.Aggs)
+}
+
+func (f *filterAggregation) Validate() error {
+	if err := validateQuery("filter aggregation", "filter", f.Filter); err != nil {
+		return err
+	}
+	return validateAggregations(f.Aggs)
+}
+
+func (f *filterAggregation) SetSubAggregation(name string, agg Aggregation) *filterAggregation {
+	f.Aggs = addAggregation(f.Aggs, name, agg)
+	return f
+}
+
+type filtersAggregation struct {
+	Filters        map[string]QueryType   `json:"filters"`
+	OtherBucketKey string                 `json:"other_bucket_key,omitempty"`
+	Aggs           map[string]Aggregation `json:"-"`
+}
+
+func FiltersAgg() *filtersAggregation {
+	return &filtersAggregation{Filters: make(map[string]QueryType)}
+}
+
+func (f *filtersAggregation) MarshalJSON() ([]byte, error) {
+	return marshalAggregation("filters", *f, f.Aggs)
+}
+
+func (f *filtersAggregation) Validate() error {
+	if len(f.Filters) == 0 {
+		return invalid("filters aggregation", "no filters")
+	}
+	for _, name := range slices.Sorted(maps.Keys(f.Filters)) {
+		if err := validateQuery("filters aggregation", name, f.Filters[name]); err != nil {
+			return err
+		}
+	}
+	return validateAggregations(f.Aggs)
+}
+
+func (f *filtersAggregation) AddFilter(name string, filter QueryType) *filtersAggregation {
+	f.Filters[name] = filter
+	return f
+}
+
+// SetOtherBucketKey adds a bucket with this key for the documents matching none of the filters.
+func (f *filtersAggregation) SetOtherBucketKey(key string) *filtersAggregation {
+	f.OtherBucketKey = key
+	return f
+}
+
+func (f *filtersAggregation) SetSubAggregation(name string, agg Aggregation) *filtersAggregation {
+	f.Aggs = addAggregation(f.Aggs, name, agg)
+	return f
+}
+
+type nestedAggregation struct {
+	Path string                 `json:"path"`
+	Aggs map[string]Aggregation `json:"-"`
+}
+
+func NestedAgg(path string) *nestedAggregation {
+	return &nestedAggregation{Path: path}
+}
+
+func (n *nestedAggregation) MarshalJSON() ([]byte, error) {
+	return marshalAggregation("nested", *n, n.Aggs)
+}
+
+func (n *nestedAggregation) Validate() error {
+	if n.Path == "" {
+		return invalid("nested aggregation", "path is empty")
+	}
+	return validateAggregations(n.Aggs)
+}
+
+func (n *nestedAggregation) SetSubAggregation(name string, agg Aggregation) *nestedAggregation {
+	n.Aggs = addAggregation(n.Aggs, name, agg)
+	return n
+}
+
+type compositeAggregation struct {
+	Size    *int                   `json:"size,omitempty"`
+	Sources []KeyVal               `json:"sources"`
+	After   map[string]interface{} `json:"after,omitempty"`
+	Aggs    map[string]Aggregation `json:"-"`
+}
+
+func CompositeAgg() *compositeAggregation {
+	return &compositeAggregation{}
+}
+
+func (c *compositeAggregation) MarshalJSON() ([]byte, error) {
+	return marshalAggregation("composite", *c, c.Aggs)
+}
+
+func (c *compositeAggregation) Validate() error {
+	if len(c.Sources) == 0 {
+		return invalid("composite aggregation", "no sources")
+	}
+	for _, source := range c.Sources {
+		for name, agg := range source {
+			switch agg.(type) {
+			case *termsAggregation, *histogramAggregation, *dateHistogramAggregation:
+			default:
+				return invalid("composite aggregation", "source %q must be a terms, histogram or date_histogram aggregation", name)
+			}
+			if err := Validate(agg); err != nil {
+				return err
+			}
+		}
+	}
+	return validateAggregations(c.Aggs)
+}
+
+// AddSource adds a terms, histogram or date_histogram value source.
+func (c *compositeAggregation) AddSource(name string, source Aggregation) *compositeAggregation {
+	c.Sources = append(c.Sources, KeyVal{name: source})
+	return c
+}
+
+func (c *compositeAggregation) SetSize(size int) *compositeAggregation {
+	c.Size = &size
+	return c
+}
+
+// SetAfter continues after the after_key of the previous page.
+func (c *compositeAggregation) SetAfter(after map[string]interface{}) *compositeAggregation {
+	c.After = after
+	return c
+}
+
+func (c *compositeAggregation) SetSubAggregation(name string, agg Aggregation) *compositeAggregation {
+	c.Aggs = addAggregation(c.Aggs, name, agg)
+	return c
+}
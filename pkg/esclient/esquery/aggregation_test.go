@@ -0,0 +1,196 @@
+package esquery_test
+
+import (
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregations(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   esquery.Aggregation
+	}{
+		{
+			name: "terms with sub aggregation",
+			expected: `{
+				"terms": {
+					"field": "additionalProperties.Color.keyword",
+					"size": 20,
+					"min_doc_count": 1,
+					"missing": "N/A",
+					"order": [{"_count": "desc"}, {"_key": "asc"}],
+					"exclude": ["unknown"]
+				},
+				"aggs": {
+					"avg_price": {"avg": {"field": "price.priceMajor"}}
+				}
+			}`,
+			actual: esquery.TermsAgg("additionalProperties.Color.keyword").
+				SetSize(20).
+				SetMinDocCount(1).
+				SetMissing("N/A").
+				SetOrder("_count", esquery.OrderDesc).
+				SetOrder("_key", esquery.OrderAsc).
+				SetExclude("unknown").
+				SetSubAggregation("avg_price", esquery.AvgAgg("price.priceMajor")),
+		},
+		{
+			name: "range",
+			expected: `{
+				"range": {
+					"field": "price.priceMajor",
+					"ranges": [
+						{"to": 100},
+						{"key": "mid", "from": 100, "to": 500},
+						{"from": 500}
+					]
+				}
+			}`,
+			actual: esquery.RangeAgg("price.priceMajor").
+				AddRange(nil, 100).
+				AddKeyedRange("mid", 100, 500).
+				AddRange(500, nil),
+		},
+		{
+			name: "histogram",
+			expected: `{
+				"histogram": {
+					"field": "additionalProperties.Ratings",
+					"interval": 0.5,
+					"min_doc_count": 0,
+					"extended_bounds": {"min": 0, "max": 5}
+				}
+			}`,
+			actual: esquery.HistogramAgg("additionalProperties.Ratings", 0.5).
+				SetMinDocCount(0).
+				SetExtendedBounds(0, 5),
+		},
+		{
+			name: "date histogram",
+			expected: `{
+				"date_histogram": {
+					"field": "record.Updated",
+					"calendar_interval": "month",
+					"format": "yyyy-MM",
+					"time_zone": "Asia/Tokyo"
+				},
+				"aggs": {
+					"max_price": {"max": {"field": "price.priceMajor", "missing": 0}}
+				}
+			}`,
+			actual: esquery.DateHistogramAgg("record.Updated").
+				SetCalendarInterval("month").
+				SetFormat("yyyy-MM").
+				SetTimeZone("Asia/Tokyo").
+				SetSubAggregation("max_price", esquery.MaxAgg("price.priceMajor").SetMissing(0)),
+		},
+		{
+			name: "filter",
+			expected: `{
+				"filter": {"term": {"languageCode": {"value": "ja"}}},
+				"aggs": {"total": {"sum": {"field": "price.priceMajor"}}}
+			}`,
+			actual: esquery.FilterAgg(esquery.Term("languageCode", "ja")).
+				SetSubAggregation("total", esquery.SumAgg("price.priceMajor")),
+		},
+		{
+			name: "filters",
+			expected: `{
+				"filters": {
+					"filters": {
+						"new": {"term": {"additionalProperties.Condition": {"value": "new"}}}
+					},
+					"other_bucket_key": "other"
+				}
+			}`,
+			actual: esquery.FiltersAgg().
+				AddFilter("new", esquery.Term("additionalProperties.Condition", "new")).
+				SetOtherBucketKey("other"),
+		},
+		{
+			name: "nested",
+			expected: `{
+				"nested": {"path": "offers"},
+				"aggs": {"min_price": {"min": {"field": "offers.price"}}}
+			}`,
+			actual: esquery.NestedAgg("offers").
+				SetSubAggregation("min_price", esquery.MinAgg("offers.price")),
+		},
+		{
+			name:     "cardinality",
+			expected: `{"cardinality": {"field": "sku", "precision_threshold": 1000}}`,
+			actual:   esquery.CardinalityAgg("sku").SetPrecisionThreshold(1000),
+		},
+		{
+			name:     "stats",
+			expected: `{"stats": {"field": "price.priceMajor"}}`,
+			actual:   esquery.StatsAgg("price.priceMajor"),
+		},
+		{
+			name: "top hits",
+			expected: `{
+				"top_hits": {
+					"size": 1,
+					"sort": [{"record.Updated": {"order": "desc"}}],
+					"_source": {"includes": ["sku", "title"]}
+				}
+			}`,
+			actual: esquery.TopHitsAgg().
+				SetSize(1).
+				SetSort(esquery.Sort("record.Updated", esquery.OrderDesc)).
+				SetSourceIncludes("sku", "title"),
+		},
+		{
+			name: "composite",
+			expected: `{
+				"composite": {
+					"size": 100,
+					"sources": [
+						{"color": {"terms": {"field": "additionalProperties.Color.keyword"}}},
+						{"month": {"date_histogram": {"field": "record.Updated", "calendar_interval": "month"}}}
+					],
+					"after": {"color": "blue", "month": 1704067200000}
+				}
+			}`,
+			actual: esquery.CompositeAgg().
+				SetSize(100).
+				AddSource("color", esquery.TermsAgg("additionalProperties.Color.keyword")).
+				AddSource("month", esquery.DateHistogramAgg("record.Updated").SetCalendarInterval("month")).
+				SetAfter(map[string]interface{}{"color": "blue", "month": 1704067200000}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(test.actual)
+			assert.Nil(t, err)
+
+			assert.JSONEq(t, test.expected, string(jsonData))
+		})
+	}
+}
+
+func TestSearchQueryAggregations(t *testing.T) {
+	expected := `{
+		"size": 0,
+		"query": {"match_all": {}},
+		"aggs": {
+			"currencies": {"terms": {"field": "price.currencyCode"}}
+		}
+	}`
+
+	actual := esquery.NewSearchQueryBuilder().
+		SetSize(0).
+		SetQuery(esquery.MatchAll()).
+		SetAggregation("currencies", esquery.TermsAgg("price.currencyCode")).
+		Build()
+
+	jsonData, err := actual.MarshalJSON()
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, expected, string(jsonData))
+}
@@ -9,6 +9,7 @@ type boolQuery struct {
 	Filter             []QueryType `json:"filter,omitempty"`
 	MinimumShouldMatch int16       `json:"minimum_should_match,omitempty"`
 	Boost              float32     `json:"boost,omitempty"`
+	Name               string      `json:"_name,omitempty"`
 }
 
 func (b *boolQuery) MarshalJSON() ([]byte, error) {
@@ -17,6 +18,26 @@ func (b *boolQuery) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// Validate checks the clauses of the bool query and the queries inside them.
+func (b *boolQuery) Validate() error {
+	if err := validateQueries("bool", "must", b.Must); err != nil {
+		return err
+	}
+	if err := validateQueries("bool", "must_not", b.MustNot); err != nil {
+		return err
+	}
+	if err := validateQueries("bool", "should", b.Should); err != nil {
+		return err
+	}
+	if err := validateQueries("bool", "filter", b.Filter); err != nil {
+		return err
+	}
+	if int(b.MinimumShouldMatch) > len(b.Should) {
+		return invalid("bool", "minimum_should_match %d is more than the %d should clauses", b.MinimumShouldMatch, len(b.Should))
+	}
+	return nil
+}
+
 func (b *boolQuery) SetMust(must ...QueryType) *boolQuery {
 	b.Must = append(b.Must, must...)
 	return b
@@ -47,6 +68,13 @@ func (b *boolQuery) SetBoost(boost float32) *boolQuery {
 	return b
 }
 
+// SetName names the query so that the hits it matches list the name in
+// their matched queries.
+func (b *boolQuery) SetName(name string) *boolQuery {
+	b.Name = name
+	return b
+}
+
 func Bool() *boolQuery {
 	return &boolQuery{}
 }
@@ -0,0 +1,44 @@
+package esquery
+
+// Collapse returns only the top hit of each value of a keyword or numeric
+// field. Inner hits return more hits of each group.
+type Collapse struct {
+	Field                      string       `json:"field"`
+	InnerHits                  []*InnerHits `json:"inner_hits,omitempty"`
+	MaxConcurrentGroupSearches *int         `json:"max_concurrent_group_searches,omitempty"`
+}
+
+func NewCollapse(field string) *Collapse {
+	return &Collapse{Field: field}
+}
+
+// AddInnerHits adds a named group of inner hits, each name must be unique.
+func (c *Collapse) AddInnerHits(innerHits ...*InnerHits) *Collapse {
+	c.InnerHits = append(c.InnerHits, innerHits...)
+	return c
+}
+
+func (c *Collapse) SetMaxConcurrentGroupSearches(max int) *Collapse {
+	c.MaxConcurrentGroupSearches = &max
+	return c
+}
+
+func (c *Collapse) Validate() error {
+	if err := validateField("collapse", c.Field); err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(c.InnerHits))
+	for _, innerHits := range c.InnerHits {
+		if innerHits == nil || innerHits.Name == "" {
+			return invalid("collapse", "inner hits need a name")
+		}
+		if names[innerHits.Name] {
+			return invalid("collapse", "inner hits %q are defined twice", innerHits.Name)
+		}
+		names[innerHits.Name] = true
+		if err := innerHits.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package esquery
+
+import "encoding/json"
+
+type scriptScoreQuery struct {
+	Query    QueryType `json:"query"`
+	Script   *Script   `json:"script"`
+	MinScore *float64  `json:"min_score,omitempty"`
+	Boost    *float64  `json:"boost,omitempty"`
+}
+
+func (s *scriptScoreQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"script_score": *s,
+	})
+}
+
+func (s *scriptScoreQuery) Validate() error {
+	if err := validateQuery("script_score", "query", s.Query); err != nil {
+		return err
+	}
+	if s.Script == nil {
+		return invalid("script_score", "script is missing")
+	}
+	return s.Script.Validate()
+}
+
+func (s *scriptScoreQuery) SetMinScore(minScore float64) *scriptScoreQuery {
+	s.MinScore = &minScore
+	return s
+}
+
+func (s *scriptScoreQuery) SetBoost(boost float64) *scriptScoreQuery {
+	s.Boost = &boost
+	return s
+}
+
+// ScriptScore replaces the score of the documents matched by query with the
+// result of script. The score must not be negative.
+func ScriptScore(query QueryType, script *Script) *scriptScoreQuery {
+	return &scriptScoreQuery{
+		Query:  query,
+		Script: script,
+	}
+}
+
+type disMaxQuery struct {
+	Queries    []QueryType `json:"queries"`
+	TieBreaker *float64    `json:"tie_breaker,omitempty"`
+	Boost      *float64    `json:"boost,omitempty"`
+}
+
+func (d *disMaxQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"dis_max": *d,
+	})
+}
+
+func (d *disMaxQuery) Validate() error {
+	if len(d.Queries) == 0 {
+		return invalid("dis_max", "no queries")
+	}
+	if err := validateFraction("dis_max", "tie_breaker", d.TieBreaker); err != nil {
+		return err
+	}
+	return validateQueries("dis_max", "queries", d.Queries)
+}
+
+func (d *disMaxQuery) SetQueries(queries ...QueryType) *disMaxQuery {
+	d.Queries = append(d.Queries, queries...)
+	return d
+}
+
+// SetTieBreaker adds this fraction of the scores of the other matching queries
+// to the best score.
+func (d *disMaxQuery) SetTieBreaker(tieBreaker float64) *disMaxQuery {
+	d.TieBreaker = &tieBreaker
+	return d
+}
+
+func (d *disMaxQuery) SetBoost(boost float64) *disMaxQuery {
+	d.Boost = &boost
+	return d
+}
+
+// DisMax scores documents with the best matching query.
+func DisMax(queries ...QueryType) *disMaxQuery {
+	return &disMaxQuery{Queries: queries}
+}
+
+type constantScoreQuery struct {
+	Filter QueryType `json:"filter"`
+	Boost  *float64  `json:"boost,omitempty"`
+}
+
+func (c *constantScoreQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"constant_score": *c,
+	})
+}
+
+func (c *constantScoreQuery) Validate() error {
+	return validateQuery("constant_score", "filter", c.Filter)
+}
+
+func (c *constantScoreQuery) SetBoost(boost float64) *constantScoreQuery {
+	c.Boost = &boost
+	return c
+}
+
+// ConstantScore gives every document matching filter a score equal to the boost.
+func ConstantScore(filter QueryType) *constantScoreQuery {
+	return &constantScoreQuery{Filter: filter}
+}
+
+type boostingQuery struct {
+	Positive      QueryType `json:"positive"`
+	Negative      QueryType `json:"negative"`
+	NegativeBoost float64   `json:"negative_boost"`
+}
+
+func (b *boostingQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"boosting": *b,
+	})
+}
+
+func (b *boostingQuery) Validate() error {
+	if err := validateQuery("boosting", "positive", b.Positive); err != nil {
+		return err
+	}
+	if err := validateQuery("boosting", "negative", b.Negative); err != nil {
+		return err
+	}
+	return validateFraction("boosting", "negative_boost", &b.NegativeBoost)
+}
+
+// Boosting matches positive and multiplies the score of the documents that
+// also match negative by negativeBoost, between 0 and 1.
+func Boosting(positive, negative QueryType, negativeBoost float64) *boostingQuery {
+	return &boostingQuery{
+		Positive:      positive,
+		Negative:      negative,
+		NegativeBoost: negativeBoost,
+	}
+}
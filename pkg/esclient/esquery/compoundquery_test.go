@@ -0,0 +1,105 @@
+package esquery_test
+
+import (
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompoundQueries(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   esquery.QueryType
+	}{
+		{
+			name: "script score",
+			expected: `{
+				"script_score": {
+					"query": {"match_all": {}},
+					"script": {
+						"source": "_score + doc['additionalProperties.Ratings'].value * params.weight",
+						"params": {"weight": 0.5}
+					},
+					"min_score": 1
+				}
+			}`,
+			actual: esquery.ScriptScore(
+				esquery.MatchAll(),
+				esquery.NewScript("_score + doc['additionalProperties.Ratings'].value * params.weight").
+					SetParam("weight", 0.5),
+			).SetMinScore(1),
+		},
+		{
+			name: "dis max",
+			expected: `{
+				"dis_max": {
+					"queries": [
+						{"match": {"title": {"query": "シャツ"}}},
+						{"match": {"title.ngram": {"query": "シャツ"}}}
+					],
+					"tie_breaker": 0.7
+				}
+			}`,
+			actual: esquery.DisMax(esquery.Match("title", "シャツ")).
+				SetQueries(esquery.Match("title.ngram", "シャツ")).
+				SetTieBreaker(0.7),
+		},
+		{
+			name: "constant score",
+			expected: `{
+				"constant_score": {
+					"filter": {"term": {"languageCode": {"value": "ja"}}},
+					"boost": 1.2
+				}
+			}`,
+			actual: esquery.ConstantScore(esquery.Term("languageCode", "ja")).SetBoost(1.2),
+		},
+		{
+			name: "boosting",
+			expected: `{
+				"boosting": {
+					"positive": {"match": {"title": {"query": "シャツ"}}},
+					"negative": {"term": {"additionalProperties.Condition": {"value": "used"}}},
+					"negative_boost": 0.5
+				}
+			}`,
+			actual: esquery.Boosting(
+				esquery.Match("title", "シャツ"),
+				esquery.Term("additionalProperties.Condition", "used"),
+				0.5,
+			),
+		},
+		{
+			name: "composed with bool",
+			expected: `{
+				"bool": {
+					"must": [
+						{"function_score": {
+							"query": {"match": {"title": {"query": "シャツ"}}},
+							"functions": [{"field_value_factor": {"field": "additionalProperties.Ratings"}}]
+						}}
+					],
+					"filter": [
+						{"constant_score": {"filter": {"exists": {"field": "price"}}}}
+					]
+				}
+			}`,
+			actual: esquery.Bool().
+				SetMust(esquery.FunctionScore(esquery.Match("title", "シャツ")).
+					AddFunction(esquery.FieldValueFactor("additionalProperties.Ratings"))).
+				SetFilter(esquery.ConstantScore(esquery.Exists("price"))),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(test.actual)
+			assert.Nil(t, err)
+
+			assert.JSONEq(t, test.expected, string(jsonData))
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package esquery
+
+import "encoding/json"
+
+// ScoreFunction is a function of a function_score query.
+type ScoreFunction interface {
+	json.Marshaler
+}
+
+type ScoreMode string
+
+const (
+	ScoreModeMultiply ScoreMode = "multiply"
+	ScoreModeSum      ScoreMode = "sum"
+	ScoreModeAvg      ScoreMode = "avg"
+	ScoreModeFirst    ScoreMode = "first"
+	ScoreModeMax      ScoreMode = "max"
+	ScoreModeMin      ScoreMode = "min"
+)
+
+type BoostMode string
+
+const (
+	BoostModeMultiply BoostMode = "multiply"
+	BoostModeReplace  BoostMode = "replace"
+	BoostModeSum      BoostMode = "sum"
+	BoostModeAvg      BoostMode = "avg"
+	BoostModeMax      BoostMode = "max"
+	BoostModeMin      BoostMode = "min"
+)
+
+type functionScoreQuery struct {
+	Query     QueryType       `json:"query,omitempty"`
+	Functions []ScoreFunction `json:"functions,omitempty"`
+	ScoreMode ScoreMode       `json:"score_mode,omitempty"`
+	BoostMode BoostMode       `json:"boost_mode,omitempty"`
+	MaxBoost  *float64        `json:"max_boost,omitempty"`
+	MinScore  *float64        `json:"min_score,omitempty"`
+	Boost     *float64        `json:"boost,omitempty"`
+}
+
+func (f *functionScoreQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"function_score": *f,
+	})
+}
+
+func (f *functionScoreQuery) Validate() error {
+	if f.Query != nil {
+		if err := Validate(f.Query); err != nil {
+			return err
+		}
+	}
+	switch f.ScoreMode {
+	case "", ScoreModeMultiply, ScoreModeSum, ScoreModeAvg, ScoreModeFirst, ScoreModeMax, ScoreModeMin:
+	default:
+		return invalid("function_score", "unknown score_mode %q", f.ScoreMode)
+	}
+	switch f.BoostMode {
+	case "", BoostModeMultiply, BoostModeReplace, BoostModeSum, BoostModeAvg, BoostModeMax, BoostModeMin:
+	default:
+		return invalid("function_score", "unknown boost_mode %q", f.BoostMode)
+	}
+	for i, function := range f.Functions {
+		if isNil(function) {
+			return invalid("function_score", "functions[%d] is missing", i)
+		}
+		if err := Validate(function); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *functionScoreQuery) AddFunction(functions ...ScoreFunction) *functionScoreQuery {
+	f.Functions = append(f.Functions, functions...)
+	return f
+}
+
+// SetScoreMode sets how the scores of the functions are combined.
+func (f *functionScoreQuery) SetScoreMode(scoreMode ScoreMode) *functionScoreQuery {
+	f.ScoreMode = scoreMode
+	return f
+}
+
+// SetBoostMode sets how the combined function score is applied to the query score.
+func (f *functionScoreQuery) SetBoostMode(boostMode BoostMode) *functionScoreQuery {
+	f.BoostMode = boostMode
+	return f
+}
+
+func (f *functionScoreQuery) SetMaxBoost(maxBoost float64) *functionScoreQuery {
+	f.MaxBoost = &maxBoost
+	return f
+}
+
+func (f *functionScoreQuery) SetMinScore(minScore float64) *functionScoreQuery {
+	f.MinScore = &minScore
+	return f
+}
+
+func (f *functionScoreQuery) SetBoost(boost float64) *functionScoreQuery {
+	f.Boost = &boost
+	return f
+}
+
+// FunctionScore modifies the score of the documents matched by query. A nil
+// query matches all documents.
+func FunctionScore(query QueryType) *functionScoreQuery {
+	return &functionScoreQuery{Query: query}
+}
+
+// scoreFunction holds the options shared by every score function.
+type scoreFunction struct {
+	filter QueryType
+	weight *float64
+}
+
+func (s scoreFunction) marshal(kind string, body interface{}) ([]byte, error) {
+	function := KeyVal{}
+	if kind != "" {
+		function[kind] = body
+	}
+	if s.filter != nil {
+		function["filter"] = s.filter
+	}
+	if s.weight != nil {
+		function["weight"] = *s.weight
+	}
+	return json.Marshal(function)
+}
+
+func (s scoreFunction) validate(kind string) error {
+	if s.weight != nil && *s.weight < 0 {
+		return invalid(kind, "weight must not be negative")
+	}
+	return Validate(s.filter)
+}
+
+type FieldValueFactorModifier string
+
+const (
+	ModifierNone       FieldValueFactorModifier = "none"
+	ModifierLog        FieldValueFactorModifier = "log"
+	ModifierLog1p      FieldValueFactorModifier = "log1p"
+	ModifierLog2p      FieldValueFactorModifier = "log2p"
+	ModifierLn         FieldValueFactorModifier = "ln"
+	ModifierLn1p       FieldValueFactorModifier = "ln1p"
+	ModifierLn2p       FieldValueFactorModifier = "ln2p"
+	ModifierSquare     FieldValueFactorModifier = "square"
+	ModifierSqrt       FieldValueFactorModifier = "sqrt"
+	ModifierReciprocal FieldValueFactorModifier = "reciprocal"
+)
+
+type fieldValueFactorFunction struct {
+	scoreFunction
+	Field    string                   `json:"field"`
+	Factor   *float64                 `json:"factor,omitempty"`
+	Modifier FieldValueFactorModifier `json:"modifier,omitempty"`
+	Missing  *float64                 `json:"missing,omitempty"`
+}
+
+func (f *fieldValueFactorFunction) MarshalJSON() ([]byte, error) {
+	type body fieldValueFactorFunction
+	return f.marshal("field_value_factor", body(*f))
+}
+
+func (f *fieldValueFactorFunction) Validate() error {
+	if err := validateField("field_value_factor", f.Field); err != nil {
+		return err
+	}
+	return f.validate("field_value_factor")
+}
+
+func (f *fieldValueFactorFunction) SetFactor(factor float64) *fieldValueFactorFunction {
+	f.Factor = &factor
+	return f
+}
+
+func (f *fieldValueFactorFunction) SetModifier(modifier FieldValueFactorModifier) *fieldValueFactorFunction {
+	f.Modifier = modifier
+	return f
+}
+
+// SetMissing sets the value used for documents without the field.
+func (f *fieldValueFactorFunction) SetMissing(missing float64) *fieldValueFactorFunction {
+	f.Missing = &missing
+	return f
+}
+
+func (f *fieldValueFactorFunction) SetFilter(filter QueryType) *fieldValueFactorFunction {
+	f.filter = filter
+	return f
+}
+
+func (f *fieldValueFactorFunction) SetWeight(weight float64) *fieldValueFactorFunction {
+	f.weight = &weight
+	return f
+}
+
+// FieldValueFactor scores documents by the value of a numeric field.
+func FieldValueFactor(field string) *fieldValueFactorFunction {
+	return &fieldValueFactorFunction{Field: field}
+}
+
+type MultiValueMode string
+
+const (
+	MultiValueModeMin MultiValueMode = "min"
+	MultiValueModeMax MultiValueMode = "max"
+	MultiValueModeAvg MultiValueMode = "avg"
+	MultiValueModeSum MultiValueMode = "sum"
+)
+
+type decayFunction struct {
+	scoreFunction
+	kind           string
+	field          string
+	Origin         interface{} `json:"origin,omitempty"`
+	Scale          interface{} `json:"scale"`
+	Offset         interface{} `json:"offset,omitempty"`
+	Decay          *float64    `json:"decay,omitempty"`
+	multiValueMode MultiValueMode
+}
+
+func (d *decayFunction) MarshalJSON() ([]byte, error) {
+	type body decayFunction
+	decay := KeyVal{
+		d.field: body(*d),
+	}
+	if d.multiValueMode != "" {
+		decay["multi_value_mode"] = d.multiValueMode
+	}
+	return d.marshal(d.kind, decay)
+}
+
+func (d *decayFunction) Validate() error {
+	if err := validateField(d.kind, d.field); err != nil {
+		return err
+	}
+	if d.Scale == nil || d.Scale == "" {
+		return invalid(d.kind, "scale is missing")
+	}
+	if d.Decay != nil && (*d.Decay <= 0 || *d.Decay >= 1) {
+		return invalid(d.kind, "decay must be between 0 and 1")
+	}
+	return d.validate(d.kind)
+}
+
+// SetOffset sets the distance from origin within which documents are not decayed.
+func (d *decayFunction) SetOffset(offset interface{}) *decayFunction {
+	d.Offset = offset
+	return d
+}
+
+// SetDecay sets the score of documents at scale distance from origin, 0.5 by default.
+func (d *decayFunction) SetDecay(decay float64) *decayFunction {
+	d.Decay = &decay
+	return d
+}
+
+func (d *decayFunction) SetMultiValueMode(mode MultiValueMode) *decayFunction {
+	d.multiValueMode = mode
+	return d
+}
+
+func (d *decayFunction) SetFilter(filter QueryType) *decayFunction {
+	d.filter = filter
+	return d
+}
+
+func (d *decayFunction) SetWeight(weight float64) *decayFunction {
+	d.weight = &weight
+	return d
+}
+
+func newDecayFunction(kind, field string, origin, scale interface{}) *decayFunction {
+	return &decayFunction{
+		kind:   kind,
+		field:  field,
+		Origin: origin,
+		Scale:  scale,
+	}
+}
+
+// Gauss decays the score with a normal distribution around origin. Origin and
+// scale are numbers, dates with date math (e.g. "now", "30d") or geo points.
+func Gauss(field string, origin, scale interface{}) *decayFunction {
+	return newDecayFunction("gauss", field, origin, scale)
+}
+
+func Exp(field string, origin, scale interface{}) *decayFunction {
+	return newDecayFunction("exp", field, origin, scale)
+}
+
+func Linear(field string, origin, scale interface{}) *decayFunction {
+	return newDecayFunction("linear", field, origin, scale)
+}
+
+type randomScoreFunction struct {
+	scoreFunction
+	Seed  interface{} `json:"seed,omitempty"`
+	Field string      `json:"field,omitempty"`
+}
+
+func (r *randomScoreFunction) MarshalJSON() ([]byte, error) {
+	type body randomScoreFunction
+	return r.marshal("random_score", body(*r))
+}
+
+func (r *randomScoreFunction) Validate() error {
+	if r.Seed != nil && r.Field == "" {
+		return invalid("random_score", "seed needs a field")
+	}
+	return r.validate("random_score")
+}
+
+// SetSeed makes the scores reproducible. It requires a field, "_seq_no" is a
+// common choice.
+func (r *randomScoreFunction) SetSeed(seed interface{}, field string) *randomScoreFunction {
+	r.Seed = seed
+	r.Field = field
+	return r
+}
+
+func (r *randomScoreFunction) SetFilter(filter QueryType) *randomScoreFunction {
+	r.filter = filter
+	return r
+}
+
+func (r *randomScoreFunction) SetWeight(weight float64) *randomScoreFunction {
+	r.weight = &weight
+	return r
+}
+
+func RandomScore() *randomScoreFunction {
+	return &randomScoreFunction{}
+}
+
+type weightFunction struct {
+	scoreFunction
+}
+
+func (w *weightFunction) MarshalJSON() ([]byte, error) {
+	return w.marshal("", nil)
+}
+
+func (w *weightFunction) Validate() error {
+	return w.validate("weight")
+}
+
+func (w *weightFunction) SetFilter(filter QueryType) *weightFunction {
+	w.filter = filter
+	return w
+}
+
+// Weight multiplies the score of the documents matching the filter by weight.
+func Weight(weight float64) *weightFunction {
+	return &weightFunction{scoreFunction{weight: &weight}}
+}
+
+type scriptScoreFunction struct {
+	scoreFunction
+	Script *Script `json:"script"`
+}
+
+func (s *scriptScoreFunction) MarshalJSON() ([]byte, error) {
+	type body scriptScoreFunction
+	return s.marshal("script_score", body(*s))
+}
+
+func (s *scriptScoreFunction) Validate() error {
+	if s.Script == nil {
+		return invalid("script_score", "script is missing")
+	}
+	if err := s.Script.Validate(); err != nil {
+		return err
+	}
+	return s.validate("script_score")
+}
+
+func (s *scriptScoreFunction) SetFilter(filter QueryType) *scriptScoreFunction {
+	s.filter = filter
+	return s
+}
+
+func (s *scriptScoreFunction) SetWeight(weight float64) *scriptScoreFunction {
+	s.weight = &weight
+	return s
+}
+
+// ScriptFunction scores documents with a script, which can read the query
+// score as _score.
+func ScriptFunction(script *Script) *scriptScoreFunction {
+	return &scriptScoreFunction{Script: script}
+}
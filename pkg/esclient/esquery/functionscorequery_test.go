@@ -0,0 +1,109 @@
+package esquery_test
+
+import (
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunctionScoreQuery(t *testing.T) {
+	expected := `{
+		"function_score": {
+			"query": {"match": {"title": {"query": "シャツ"}}},
+			"functions": [
+				{
+					"field_value_factor": {
+						"field": "additionalProperties.Ratings",
+						"factor": 1.2,
+						"modifier": "log1p",
+						"missing": 1
+					}
+				},
+				{
+					"gauss": {
+						"record.Updated": {"origin": "now", "scale": "30d", "offset": "7d", "decay": 0.5},
+						"multi_value_mode": "max"
+					},
+					"weight": 2
+				},
+				{
+					"filter": {"term": {"additionalProperties.Condition": {"value": "new"}}},
+					"weight": 1.5
+				},
+				{
+					"random_score": {"seed": 42, "field": "_seq_no"},
+					"weight": 0.1
+				},
+				{
+					"script_score": {
+						"script": {"source": "_score * params.factor", "params": {"factor": 2}}
+					},
+					"filter": {"exists": {"field": "price"}}
+				}
+			],
+			"score_mode": "sum",
+			"boost_mode": "multiply",
+			"max_boost": 10,
+			"min_score": 0.5
+		}
+	}`
+
+	actual := esquery.FunctionScore(esquery.Match("title", "シャツ")).
+		AddFunction(
+			esquery.FieldValueFactor("additionalProperties.Ratings").
+				SetFactor(1.2).
+				SetModifier(esquery.ModifierLog1p).
+				SetMissing(1),
+			esquery.Gauss("record.Updated", "now", "30d").
+				SetOffset("7d").
+				SetDecay(0.5).
+				SetMultiValueMode(esquery.MultiValueModeMax).
+				SetWeight(2),
+			esquery.Weight(1.5).
+				SetFilter(esquery.Term("additionalProperties.Condition", "new")),
+			esquery.RandomScore().
+				SetSeed(42, "_seq_no").
+				SetWeight(0.1),
+			esquery.ScriptFunction(esquery.NewScript("_score * params.factor").SetParam("factor", 2)).
+				SetFilter(esquery.Exists("price")),
+		).
+		SetScoreMode(esquery.ScoreModeSum).
+		SetBoostMode(esquery.BoostModeMultiply).
+		SetMaxBoost(10).
+		SetMinScore(0.5)
+
+	jsonData, err := json.Marshal(actual)
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, expected, string(jsonData))
+}
+
+func TestDecayFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   esquery.ScoreFunction
+	}{
+		{
+			name:     "exp",
+			expected: `{"exp": {"price.priceMajor": {"origin": 1000, "scale": 500}}}`,
+			actual:   esquery.Exp("price.priceMajor", 1000, 500),
+		},
+		{
+			name:     "linear",
+			expected: `{"linear": {"location": {"origin": {"lat": 35.68, "lon": 139.76}, "scale": "10km"}}}`,
+			actual:   esquery.Linear("location", map[string]float64{"lat": 35.68, "lon": 139.76}, "10km"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(test.actual)
+			assert.Nil(t, err)
+
+			assert.JSONEq(t, test.expected, string(jsonData))
+		})
+	}
+}
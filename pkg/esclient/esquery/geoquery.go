@@ -0,0 +1,306 @@
+package esquery
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type GeoValidationMethod string
+
+const (
+	GeoValidationStrict          GeoValidationMethod = "STRICT"
+	GeoValidationIgnoreMalformed GeoValidationMethod = "IGNORE_MALFORMED"
+	GeoValidationCoerce          GeoValidationMethod = "COERCE"
+)
+
+func (p GeoPoint) validate(kind string) error {
+	if p.Lat < -90 || p.Lat > 90 || p.Lon < -180 || p.Lon > 180 {
+		return invalid(kind, "point %v,%v is out of range", p.Lat, p.Lon)
+	}
+	return nil
+}
+
+type geoDistanceQuery struct {
+	Field            string
+	Point            GeoPoint
+	Distance         string
+	DistanceType     string
+	ValidationMethod GeoValidationMethod
+	IgnoreUnmapped   *bool
+	Boost            *float64
+}
+
+func (g *geoDistanceQuery) MarshalJSON() ([]byte, error) {
+	query := KeyVal{
+		"distance": g.Distance,
+		g.Field:    g.Point,
+	}
+	if g.DistanceType != "" {
+		query["distance_type"] = g.DistanceType
+	}
+	if g.ValidationMethod != "" {
+		query["validation_method"] = g.ValidationMethod
+	}
+	if g.IgnoreUnmapped != nil {
+		query["ignore_unmapped"] = *g.IgnoreUnmapped
+	}
+	if g.Boost != nil {
+		query["boost"] = *g.Boost
+	}
+	return json.Marshal(KeyVal{
+		"geo_distance": query,
+	})
+}
+
+func (g *geoDistanceQuery) Validate() error {
+	if err := validateField("geo_distance", g.Field); err != nil {
+		return err
+	}
+	if g.Distance == "" {
+		return invalid("geo_distance", "distance is empty")
+	}
+	return g.Point.validate("geo_distance")
+}
+
+// SetDistanceType sets "arc" (default) or the faster but less accurate "plane".
+func (g *geoDistanceQuery) SetDistanceType(distanceType string) *geoDistanceQuery {
+	g.DistanceType = distanceType
+	return g
+}
+
+func (g *geoDistanceQuery) SetValidationMethod(method GeoValidationMethod) *geoDistanceQuery {
+	g.ValidationMethod = method
+	return g
+}
+
+func (g *geoDistanceQuery) SetIgnoreUnmapped(ignoreUnmapped bool) *geoDistanceQuery {
+	g.IgnoreUnmapped = &ignoreUnmapped
+	return g
+}
+
+func (g *geoDistanceQuery) SetBoost(boost float64) *geoDistanceQuery {
+	g.Boost = &boost
+	return g
+}
+
+// GeoDistance matches geo points within distance of point, e.g. "5km".
+func GeoDistance(field string, point GeoPoint, distance string) *geoDistanceQuery {
+	return &geoDistanceQuery{
+		Field:    field,
+		Point:    point,
+		Distance: distance,
+	}
+}
+
+type geoBoundingBoxQuery struct {
+	Field            string
+	TopLeft          GeoPoint
+	BottomRight      GeoPoint
+	ValidationMethod GeoValidationMethod
+	IgnoreUnmapped   *bool
+	Boost            *float64
+}
+
+func (g *geoBoundingBoxQuery) MarshalJSON() ([]byte, error) {
+	query := KeyVal{
+		g.Field: KeyVal{
+			"top_left":     g.TopLeft,
+			"bottom_right": g.BottomRight,
+		},
+	}
+	if g.ValidationMethod != "" {
+		query["validation_method"] = g.ValidationMethod
+	}
+	if g.IgnoreUnmapped != nil {
+		query["ignore_unmapped"] = *g.IgnoreUnmapped
+	}
+	if g.Boost != nil {
+		query["boost"] = *g.Boost
+	}
+	return json.Marshal(KeyVal{
+		"geo_bounding_box": query,
+	})
+}
+
+func (g *geoBoundingBoxQuery) Validate() error {
+	if err := validateField("geo_bounding_box", g.Field); err != nil {
+		return err
+	}
+	if err := g.TopLeft.validate("geo_bounding_box"); err != nil {
+		return err
+	}
+	if err := g.BottomRight.validate("geo_bounding_box"); err != nil {
+		return err
+	}
+	if g.TopLeft.Lat < g.BottomRight.Lat {
+		return invalid("geo_bounding_box", "top_left is below bottom_right")
+	}
+	return nil
+}
+
+func (g *geoBoundingBoxQuery) SetValidationMethod(method GeoValidationMethod) *geoBoundingBoxQuery {
+	g.ValidationMethod = method
+	return g
+}
+
+func (g *geoBoundingBoxQuery) SetIgnoreUnmapped(ignoreUnmapped bool) *geoBoundingBoxQuery {
+	g.IgnoreUnmapped = &ignoreUnmapped
+	return g
+}
+
+func (g *geoBoundingBoxQuery) SetBoost(boost float64) *geoBoundingBoxQuery {
+	g.Boost = &boost
+	return g
+}
+
+func GeoBoundingBox(field string, topLeft, bottomRight GeoPoint) *geoBoundingBoxQuery {
+	return &geoBoundingBoxQuery{
+		Field:       field,
+		TopLeft:     topLeft,
+		BottomRight: bottomRight,
+	}
+}
+
+// GeoShape is a GeoJSON geometry, e.g. a "polygon" or "envelope". Coordinates
+// are in [lon, lat] order.
+type GeoShape struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// IndexedShape references a shape stored in another document.
+type IndexedShape struct {
+	Index   string `json:"index"`
+	Id      string `json:"id"`
+	Path    string `json:"path,omitempty"`
+	Routing string `json:"routing,omitempty"`
+}
+
+type geoShapeQuery struct {
+	Field          string        `json:"-"`
+	Shape          *GeoShape     `json:"shape,omitempty"`
+	IndexedShape   *IndexedShape `json:"indexed_shape,omitempty"`
+	Relation       Relation      `json:"relation,omitempty"`
+	IgnoreUnmapped *bool         `json:"-"`
+}
+
+func (g *geoShapeQuery) MarshalJSON() ([]byte, error) {
+	type shape geoShapeQuery
+	query := KeyVal{
+		g.Field: shape(*g),
+	}
+	if g.IgnoreUnmapped != nil {
+		query["ignore_unmapped"] = *g.IgnoreUnmapped
+	}
+	return json.Marshal(KeyVal{
+		"geo_shape": query,
+	})
+}
+
+func (g *geoShapeQuery) Validate() error {
+	if err := validateField("geo_shape", g.Field); err != nil {
+		return err
+	}
+	if (g.Shape == nil) == (g.IndexedShape == nil) {
+		return invalid("geo_shape", "needs either a shape or an indexed_shape")
+	}
+	if g.Shape != nil && (g.Shape.Type == "" || g.Shape.Coordinates == nil) {
+		return invalid("geo_shape", "shape needs a type and coordinates")
+	}
+	if g.IndexedShape != nil && (g.IndexedShape.Index == "" || g.IndexedShape.Id == "") {
+		return invalid("geo_shape", "indexed_shape needs an index and an id")
+	}
+	switch strings.ToUpper(string(g.Relation)) {
+	case "", string(INTERSECTS), string(DISJOINT), string(WITHIN), string(CONTAINS):
+		return nil
+	}
+	return invalid("geo_shape", "unknown relation %q", g.Relation)
+}
+
+// SetRelation sets INTERSECTS (default), DISJOINT, WITHIN or CONTAINS.
+func (g *geoShapeQuery) SetRelation(relation Relation) *geoShapeQuery {
+	g.Relation = relation
+	return g
+}
+
+func (g *geoShapeQuery) SetIgnoreUnmapped(ignoreUnmapped bool) *geoShapeQuery {
+	g.IgnoreUnmapped = &ignoreUnmapped
+	return g
+}
+
+func GeoShapeQuery(field string, shape *GeoShape) *geoShapeQuery {
+	return &geoShapeQuery{
+		Field: field,
+		Shape: shape,
+	}
+}
+
+func GeoIndexedShapeQuery(field string, indexedShape *IndexedShape) *geoShapeQuery {
+	return &geoShapeQuery{
+		Field:        field,
+		IndexedShape: indexedShape,
+	}
+}
+
+type geoPolygonQuery struct {
+	Field            string
+	Points           []GeoPoint
+	ValidationMethod GeoValidationMethod
+	IgnoreUnmapped   *bool
+}
+
+func (g *geoPolygonQuery) MarshalJSON() ([]byte, error) {
+	query := KeyVal{
+		g.Field: KeyVal{
+			"points": g.Points,
+		},
+	}
+	if g.ValidationMethod != "" {
+		query["validation_method"] = g.ValidationMethod
+	}
+	if g.IgnoreUnmapped != nil {
+		query["ignore_unmapped"] = *g.IgnoreUnmapped
+	}
+	return json.Marshal(KeyVal{
+		"geo_polygon": query,
+	})
+}
+
+func (g *geoPolygonQuery) Validate() error {
+	if err := validateField("geo_polygon", g.Field); err != nil {
+		return err
+	}
+	if len(g.Points) < 3 {
+		return invalid("geo_polygon", "needs at least 3 points")
+	}
+	for _, point := range g.Points {
+		if err := point.validate("geo_polygon"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *geoPolygonQuery) SetValidationMethod(method GeoValidationMethod) *geoPolygonQuery {
+	g.ValidationMethod = method
+	return g
+}
+
+func (g *geoPolygonQuery) SetIgnoreUnmapped(ignoreUnmapped bool) *geoPolygonQuery {
+	g.IgnoreUnmapped = &ignoreUnmapped
+	return g
+}
+
+// GeoPolygon matches geo points inside the polygon. It is deprecated since
+// Elasticsearch 7.12; prefer GeoShapeQuery with a polygon.
+func GeoPolygon(field string, points ...GeoPoint) *geoPolygonQuery {
+	return &geoPolygonQuery{
+		Field:  field,
+		Points: points,
+	}
+}
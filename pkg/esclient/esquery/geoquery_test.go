@@ -0,0 +1,107 @@
+package esquery_test
+
+import (
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoQueries(t *testing.T) {
+	tokyo := esquery.GeoPoint{Lat: 35.681, Lon: 139.767}
+
+	tests := []struct {
+		name     string
+		expected string
+		actual   esquery.QueryType
+	}{
+		{
+			name: "geo distance",
+			expected: `{
+				"geo_distance": {
+					"distance": "5km",
+					"store.location": {"lat": 35.681, "lon": 139.767},
+					"distance_type": "plane"
+				}
+			}`,
+			actual: esquery.GeoDistance("store.location", tokyo, "5km").SetDistanceType("plane"),
+		},
+		{
+			name: "geo bounding box",
+			expected: `{
+				"geo_bounding_box": {
+					"store.location": {
+						"top_left": {"lat": 35.8, "lon": 139.6},
+						"bottom_right": {"lat": 35.5, "lon": 139.9}
+					},
+					"validation_method": "COERCE"
+				}
+			}`,
+			actual: esquery.GeoBoundingBox("store.location",
+				esquery.GeoPoint{Lat: 35.8, Lon: 139.6},
+				esquery.GeoPoint{Lat: 35.5, Lon: 139.9},
+			).SetValidationMethod(esquery.GeoValidationCoerce),
+		},
+		{
+			name: "geo shape",
+			expected: `{
+				"geo_shape": {
+					"store.area": {
+						"shape": {"type": "envelope", "coordinates": [[139.6, 35.8], [139.9, 35.5]]},
+						"relation": "WITHIN"
+					},
+					"ignore_unmapped": true
+				}
+			}`,
+			actual: esquery.GeoShapeQuery("store.area", &esquery.GeoShape{
+				Type:        "envelope",
+				Coordinates: [][]float64{{139.6, 35.8}, {139.9, 35.5}},
+			}).SetRelation(esquery.WITHIN).SetIgnoreUnmapped(true),
+		},
+		{
+			name: "geo indexed shape",
+			expected: `{
+				"geo_shape": {
+					"store.area": {
+						"indexed_shape": {"index": "regions", "id": "kanto", "path": "area"},
+						"relation": "DISJOINT"
+					}
+				}
+			}`,
+			actual: esquery.GeoIndexedShapeQuery("store.area", &esquery.IndexedShape{
+				Index: "regions",
+				Id:    "kanto",
+				Path:  "area",
+			}).SetRelation(esquery.DISJOINT),
+		},
+		{
+			name: "geo polygon",
+			expected: `{
+				"geo_polygon": {
+					"store.location": {
+						"points": [
+							{"lat": 35.8, "lon": 139.6},
+							{"lat": 35.5, "lon": 139.6},
+							{"lat": 35.5, "lon": 139.9}
+						]
+					}
+				}
+			}`,
+			actual: esquery.GeoPolygon("store.location",
+				esquery.GeoPoint{Lat: 35.8, Lon: 139.6},
+				esquery.GeoPoint{Lat: 35.5, Lon: 139.6},
+				esquery.GeoPoint{Lat: 35.5, Lon: 139.9},
+			),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(test.actual)
+			assert.Nil(t, err)
+
+			assert.JSONEq(t, test.expected, string(jsonData))
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package esquery
+
+type HighlighterType string
+
+const (
+	HighlighterUnified HighlighterType = "unified"
+	HighlighterPlain   HighlighterType = "plain"
+	HighlighterFvh     HighlighterType = "fvh"
+)
+
+// Highlight returns snippets of the fields with the matching terms wrapped in
+// the pre and post tags, <em> by default. The options set on Highlight apply
+// to every field unless the field overrides them.
+type Highlight struct {
+	Fields            map[string]*HighlightField `json:"fields"`
+	Type              HighlighterType            `json:"type,omitempty"`
+	PreTags           []string                   `json:"pre_tags,omitempty"`
+	PostTags          []string                   `json:"post_tags,omitempty"`
+	FragmentSize      *int                       `json:"fragment_size,omitempty"`
+	NumberOfFragments *int                       `json:"number_of_fragments,omitempty"`
+	NoMatchSize       *int                       `json:"no_match_size,omitempty"`
+	Order             string                     `json:"order,omitempty"`
+	Encoder           string                     `json:"encoder,omitempty"`
+	RequireFieldMatch *bool                      `json:"require_field_match,omitempty"`
+	HighlightQuery    QueryType                  `json:"highlight_query,omitempty"`
+}
+
+func NewHighlight() *Highlight {
+	return &Highlight{Fields: make(map[string]*HighlightField)}
+}
+
+// SetFields highlights the fields with the shared options.
+func (h *Highlight) SetFields(fields ...string) *Highlight {
+	for _, field := range fields {
+		h.Fields[field] = &HighlightField{}
+	}
+	return h
+}
+
+// SetField highlights a field with its own options.
+func (h *Highlight) SetField(name string, field *HighlightField) *Highlight {
+	h.Fields[name] = field
+	return h
+}
+
+func (h *Highlight) SetType(highlighterType HighlighterType) *Highlight {
+	h.Type = highlighterType
+	return h
+}
+
+func (h *Highlight) SetTags(preTag, postTag string) *Highlight {
+	h.PreTags = []string{preTag}
+	h.PostTags = []string{postTag}
+	return h
+}
+
+// SetFragmentSize sets the size of the snippets in characters, 100 by default.
+func (h *Highlight) SetFragmentSize(size int) *Highlight {
+	h.FragmentSize = &size
+	return h
+}
+
+// SetNumberOfFragments sets the maximum number of snippets, 0 returns the
+// whole field content.
+func (h *Highlight) SetNumberOfFragments(number int) *Highlight {
+	h.NumberOfFragments = &number
+	return h
+}
+
+// SetNoMatchSize returns a snippet from the start of the field when nothing matches.
+func (h *Highlight) SetNoMatchSize(size int) *Highlight {
+	h.NoMatchSize = &size
+	return h
+}
+
+// SetOrderByScore sorts the snippets by score instead of by position.
+func (h *Highlight) SetOrderByScore() *Highlight {
+	h.Order = "score"
+	return h
+}
+
+// SetEncoder sets "html" to escape the field content before adding the tags.
+func (h *Highlight) SetEncoder(encoder string) *Highlight {
+	h.Encoder = encoder
+	return h
+}
+
+func (h *Highlight) SetRequireFieldMatch(require bool) *Highlight {
+	h.RequireFieldMatch = &require
+	return h
+}
+
+// SetHighlightQuery highlights the matches of query instead of the search query.
+func (h *Highlight) SetHighlightQuery(query QueryType) *Highlight {
+	h.HighlightQuery = query
+	return h
+}
+
+type HighlightField struct {
+	Type              HighlighterType `json:"type,omitempty"`
+	PreTags           []string        `json:"pre_tags,omitempty"`
+	PostTags          []string        `json:"post_tags,omitempty"`
+	FragmentSize      *int            `json:"fragment_size,omitempty"`
+	NumberOfFragments *int            `json:"number_of_fragments,omitempty"`
+	NoMatchSize       *int            `json:"no_match_size,omitempty"`
+	MatchedFields     []string        `json:"matched_fields,omitempty"`
+	HighlightQuery    QueryType       `json:"highlight_query,omitempty"`
+}
+
+func NewHighlightField() *HighlightField {
+	return &HighlightField{}
+}
+
+func (f *HighlightField) SetType(highlighterType HighlighterType) *HighlightField {
+	f.Type = highlighterType
+	return f
+}
+
+func (f *HighlightField) SetTags(preTag, postTag string) *HighlightField {
+	f.PreTags = []string{preTag}
+	f.PostTags = []string{postTag}
+	return f
+}
+
+func (f *HighlightField) SetFragmentSize(size int) *HighlightField {
+	f.FragmentSize = &size
+	return f
+}
+
+func (f *HighlightField) SetNumberOfFragments(number int) *HighlightField {
+	f.NumberOfFragments = &number
+	return f
+}
+
+func (f *HighlightField) SetNoMatchSize(size int) *HighlightField {
+	f.NoMatchSize = &size
+	return f
+}
+
+// SetMatchedFields combines the matches of several subfields, e.g. "title"
+// and "title.ngram", into the snippets of this field. It needs the fvh type.
+func (f *HighlightField) SetMatchedFields(fields ...string) *HighlightField {
+	f.MatchedFields = append(f.MatchedFields, fields...)
+	return f
+}
+
+func (f *HighlightField) SetHighlightQuery(query QueryType) *HighlightField {
+	f.HighlightQuery = query
+	return f
+}
+
+func (h *Highlight) Validate() error {
+	if len(h.Fields) == 0 {
+		return invalid("highlight", "no fields")
+	}
+	if len(h.PreTags) != len(h.PostTags) {
+		return invalid("highlight", "pre_tags and post_tags differ in length")
+	}
+	if h.HighlightQuery != nil {
+		return Validate(h.HighlightQuery)
+	}
+	return nil
+}
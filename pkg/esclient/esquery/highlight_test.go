@@ -0,0 +1,122 @@
+package esquery_test
+
+import (
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchQueryHighlightSuggestCollapse(t *testing.T) {
+	expected := `{
+		"query": {
+			"bool": {
+				"should": [
+					{"match": {"title": {"query": "シャツ", "_name": "title"}}},
+					{"match": {"description": {"query": "シャツ", "_name": "description"}}}
+				]
+			}
+		},
+		"fields": ["sku", "price.*"],
+		"explain": true,
+		"highlight": {
+			"fields": {
+				"title": {"number_of_fragments": 0},
+				"description": {
+					"fragment_size": 80,
+					"number_of_fragments": 2,
+					"no_match_size": 80,
+					"type": "fvh",
+					"matched_fields": ["description", "description.ngram"]
+				}
+			},
+			"pre_tags": ["<mark>"],
+			"post_tags": ["</mark>"],
+			"encoder": "html",
+			"type": "unified"
+		},
+		"suggest": {
+			"spelling": {
+				"text": "シャツ ブル",
+				"term": {"field": "title", "suggest_mode": "popular", "size": 3}
+			},
+			"did_you_mean": {
+				"text": "blu shirt",
+				"phrase": {
+					"field": "title.trigram",
+					"gram_size": 3,
+					"direct_generator": [{"field": "title.trigram", "suggest_mode": "always"}],
+					"highlight": {"pre_tag": "<em>", "post_tag": "</em>"}
+				}
+			},
+			"autocomplete": {
+				"prefix": "シャ",
+				"completion": {"field": "title.suggest", "size": 5, "skip_duplicates": true, "fuzzy": {"fuzziness": "AUTO"}}
+			}
+		},
+		"collapse": {
+			"field": "family.keyword",
+			"inner_hits": [
+				{"name": "variants", "size": 3, "sort": [{"price.priceMajor": {"order": "asc"}}]}
+			],
+			"max_concurrent_group_searches": 4
+		}
+	}`
+
+	actual := esquery.NewSearchQueryBuilder().
+		SetQuery(esquery.Bool().SetShould(
+			esquery.Match("title", "シャツ").SetName("title"),
+			esquery.Match("description", "シャツ").SetName("description"),
+		)).
+		SetFields("sku", "price.*").
+		SetExplain(true).
+		SetHighlight(esquery.NewHighlight().
+			SetType(esquery.HighlighterUnified).
+			SetTags("<mark>", "</mark>").
+			SetEncoder("html").
+			SetField("title", esquery.NewHighlightField().SetNumberOfFragments(0)).
+			SetField("description", esquery.NewHighlightField().
+				SetType(esquery.HighlighterFvh).
+				SetFragmentSize(80).
+				SetNumberOfFragments(2).
+				SetNoMatchSize(80).
+				SetMatchedFields("description", "description.ngram"))).
+		SetSuggester("spelling", esquery.TermSuggester("シャツ ブル", "title").
+			SetSuggestMode(esquery.SuggestModePopular).
+			SetSize(3)).
+		SetSuggester("did_you_mean", esquery.PhraseSuggester("blu shirt", "title.trigram").
+			SetGramSize(3).
+			AddDirectGenerator(&esquery.DirectGenerator{Field: "title.trigram", SuggestMode: esquery.SuggestModeAlways}).
+			SetHighlight("<em>", "</em>")).
+		SetSuggester("autocomplete", esquery.CompletionSuggester("シャ", "title.suggest").
+			SetSize(5).
+			SetSkipDuplicates(true).
+			SetFuzziness(esquery.FuzzinessAuto)).
+		SetCollapse(esquery.NewCollapse("family.keyword").
+			AddInnerHits(esquery.NewInnerHits().
+				SetName("variants").
+				SetSize(3).
+				SetSort(esquery.Sort("price.priceMajor", esquery.OrderAsc))).
+			SetMaxConcurrentGroupSearches(4)).
+		Build()
+
+	jsonData, err := json.Marshal(actual)
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, expected, string(jsonData))
+}
+
+func TestHighlightSetFields(t *testing.T) {
+	expected := `{"fields": {"title": {}, "description": {}}, "fragment_size": 150, "order": "score"}`
+
+	actual := esquery.NewHighlight().
+		SetFields("title", "description").
+		SetFragmentSize(150).
+		SetOrderByScore()
+
+	jsonData, err := json.Marshal(actual)
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, expected, string(jsonData))
+}
@@ -0,0 +1,68 @@
+package esquery
+
+// InnerHits returns the nested objects or child documents that caused each
+// hit to match. The hits are returned under the name, which defaults to the
+// nested path or child type.
+type InnerHits struct {
+	Name      string        `json:"name,omitempty"`
+	From      *int          `json:"from,omitempty"`
+	Size      *int          `json:"size,omitempty"`
+	Sort      []*sort       `json:"sort,omitempty"`
+	Source    *SourceFilter `json:"_source,omitempty"`
+	Highlight *Highlight    `json:"highlight,omitempty"`
+}
+
+func NewInnerHits() *InnerHits {
+	return &InnerHits{}
+}
+
+func (i *InnerHits) SetName(name string) *InnerHits {
+	i.Name = name
+	return i
+}
+
+func (i *InnerHits) SetFrom(from int) *InnerHits {
+	i.From = &from
+	return i
+}
+
+func (i *InnerHits) SetSize(size int) *InnerHits {
+	i.Size = &size
+	return i
+}
+
+func (i *InnerHits) SetSort(sort ...*sort) *InnerHits {
+	i.Sort = append(i.Sort, sort...)
+	return i
+}
+
+func (i *InnerHits) SetSourceIncludes(fields ...string) *InnerHits {
+	if i.Source == nil {
+		i.Source = &SourceFilter{}
+	}
+	i.Source.Includes = append(i.Source.Includes, fields...)
+	return i
+}
+
+func (i *InnerHits) SetHighlight(highlight *Highlight) *InnerHits {
+	i.Highlight = highlight
+	return i
+}
+
+func (i *InnerHits) DisableSource() *InnerHits {
+	i.Source = NoSource()
+	return i
+}
+
+func (i *InnerHits) Validate() error {
+	if err := validateNonNegative("inner_hits", "from", i.From); err != nil {
+		return err
+	}
+	if err := validateNonNegative("inner_hits", "size", i.Size); err != nil {
+		return err
+	}
+	if err := validateSort("inner_hits", i.Sort); err != nil {
+		return err
+	}
+	return Validate(i.Highlight)
+}
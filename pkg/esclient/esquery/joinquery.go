@@ -0,0 +1,212 @@
+package esquery
+
+import "encoding/json"
+
+// NestedScoreMode sets how the scores of the matching nested objects or child
+// documents are combined into the score of the hit.
+type NestedScoreMode string
+
+const (
+	NestedScoreAvg  NestedScoreMode = "avg"
+	NestedScoreMax  NestedScoreMode = "max"
+	NestedScoreMin  NestedScoreMode = "min"
+	NestedScoreSum  NestedScoreMode = "sum"
+	NestedScoreNone NestedScoreMode = "none"
+)
+
+type nestedQuery struct {
+	Path           string          `json:"path"`
+	Query          QueryType       `json:"query"`
+	ScoreMode      NestedScoreMode `json:"score_mode,omitempty"`
+	IgnoreUnmapped *bool           `json:"ignore_unmapped,omitempty"`
+	InnerHits      *InnerHits      `json:"inner_hits,omitempty"`
+}
+
+func (n *nestedQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"nested": *n,
+	})
+}
+
+func (n *nestedQuery) Validate() error {
+	if n.Path == "" {
+		return invalid("nested", "path is empty")
+	}
+	if err := validateNestedScoreMode("nested", n.ScoreMode); err != nil {
+		return err
+	}
+	if err := Validate(n.InnerHits); err != nil {
+		return err
+	}
+	return validateQuery("nested", "query", n.Query)
+}
+
+func (n *nestedQuery) SetScoreMode(scoreMode NestedScoreMode) *nestedQuery {
+	n.ScoreMode = scoreMode
+	return n
+}
+
+// SetIgnoreUnmapped matches nothing instead of failing on indices without the path.
+func (n *nestedQuery) SetIgnoreUnmapped(ignoreUnmapped bool) *nestedQuery {
+	n.IgnoreUnmapped = &ignoreUnmapped
+	return n
+}
+
+func (n *nestedQuery) SetInnerHits(innerHits *InnerHits) *nestedQuery {
+	n.InnerHits = innerHits
+	return n
+}
+
+// Nested matches documents with a nested object at path matching query. The
+// fields in query use the full path, e.g. "offers.price".
+func Nested(path string, query QueryType) *nestedQuery {
+	return &nestedQuery{
+		Path:  path,
+		Query: query,
+	}
+}
+
+type hasChildQuery struct {
+	Type           string          `json:"type"`
+	Query          QueryType       `json:"query"`
+	ScoreMode      NestedScoreMode `json:"score_mode,omitempty"`
+	MinChildren    *int            `json:"min_children,omitempty"`
+	MaxChildren    *int            `json:"max_children,omitempty"`
+	IgnoreUnmapped *bool           `json:"ignore_unmapped,omitempty"`
+	InnerHits      *InnerHits      `json:"inner_hits,omitempty"`
+}
+
+func (h *hasChildQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"has_child": *h,
+	})
+}
+
+func (h *hasChildQuery) Validate() error {
+	if h.Type == "" {
+		return invalid("has_child", "type is empty")
+	}
+	if err := validateNestedScoreMode("has_child", h.ScoreMode); err != nil {
+		return err
+	}
+	if h.MinChildren != nil && h.MaxChildren != nil && *h.MinChildren > *h.MaxChildren {
+		return invalid("has_child", "min_children is more than max_children")
+	}
+	if err := Validate(h.InnerHits); err != nil {
+		return err
+	}
+	return validateQuery("has_child", "query", h.Query)
+}
+
+func (h *hasChildQuery) SetScoreMode(scoreMode NestedScoreMode) *hasChildQuery {
+	h.ScoreMode = scoreMode
+	return h
+}
+
+func (h *hasChildQuery) SetMinChildren(minChildren int) *hasChildQuery {
+	h.MinChildren = &minChildren
+	return h
+}
+
+func (h *hasChildQuery) SetMaxChildren(maxChildren int) *hasChildQuery {
+	h.MaxChildren = &maxChildren
+	return h
+}
+
+func (h *hasChildQuery) SetIgnoreUnmapped(ignoreUnmapped bool) *hasChildQuery {
+	h.IgnoreUnmapped = &ignoreUnmapped
+	return h
+}
+
+func (h *hasChildQuery) SetInnerHits(innerHits *InnerHits) *hasChildQuery {
+	h.InnerHits = innerHits
+	return h
+}
+
+// HasChild matches parent documents with a child of childType matching query.
+func HasChild(childType string, query QueryType) *hasChildQuery {
+	return &hasChildQuery{
+		Type:  childType,
+		Query: query,
+	}
+}
+
+type hasParentQuery struct {
+	ParentType     string     `json:"parent_type"`
+	Query          QueryType  `json:"query"`
+	Score          *bool      `json:"score,omitempty"`
+	IgnoreUnmapped *bool      `json:"ignore_unmapped,omitempty"`
+	InnerHits      *InnerHits `json:"inner_hits,omitempty"`
+}
+
+func (h *hasParentQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"has_parent": *h,
+	})
+}
+
+func (h *hasParentQuery) Validate() error {
+	if h.ParentType == "" {
+		return invalid("has_parent", "parent_type is empty")
+	}
+	if err := Validate(h.InnerHits); err != nil {
+		return err
+	}
+	return validateQuery("has_parent", "query", h.Query)
+}
+
+// SetScore passes the score of the parent on to its children.
+func (h *hasParentQuery) SetScore(score bool) *hasParentQuery {
+	h.Score = &score
+	return h
+}
+
+func (h *hasParentQuery) SetIgnoreUnmapped(ignoreUnmapped bool) *hasParentQuery {
+	h.IgnoreUnmapped = &ignoreUnmapped
+	return h
+}
+
+func (h *hasParentQuery) SetInnerHits(innerHits *InnerHits) *hasParentQuery {
+	h.InnerHits = innerHits
+	return h
+}
+
+// HasParent matches child documents whose parent of parentType matches query.
+func HasParent(parentType string, query QueryType) *hasParentQuery {
+	return &hasParentQuery{
+		ParentType: parentType,
+		Query:      query,
+	}
+}
+
+type parentIdQuery struct {
+	Type           string `json:"type"`
+	Id             string `json:"id"`
+	IgnoreUnmapped *bool  `json:"ignore_unmapped,omitempty"`
+}
+
+func (p *parentIdQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"parent_id": *p,
+	})
+}
+
+func (p *parentIdQuery) Validate() error {
+	if p.Type == "" || p.Id == "" {
+		return invalid("parent_id", "needs a type and an id")
+	}
+	return nil
+}
+
+func (p *parentIdQuery) SetIgnoreUnmapped(ignoreUnmapped bool) *parentIdQuery {
+	p.IgnoreUnmapped = &ignoreUnmapped
+	return p
+}
+
+// ParentId matches the children of childType of the parent document id.
+func ParentId(childType, id string) *parentIdQuery {
+	return &parentIdQuery{
+		Type: childType,
+		Id:   id,
+	}
+}
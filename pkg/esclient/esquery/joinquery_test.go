@@ -0,0 +1,80 @@
+package esquery_test
+
+import (
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinQueries(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   esquery.QueryType
+	}{
+		{
+			name: "nested with inner hits",
+			expected: `{
+				"nested": {
+					"path": "offers",
+					"query": {"range": {"offers.price.priceMajor": {"lte": 1000}}},
+					"score_mode": "min",
+					"inner_hits": {
+						"size": 1,
+						"sort": [{"offers.price.priceMajor": {"order": "asc"}}],
+						"_source": {"includes": ["offers.price"]}
+					}
+				}
+			}`,
+			actual: esquery.Nested("offers", esquery.Range("offers.price.priceMajor").SetLte(1000)).
+				SetScoreMode(esquery.NestedScoreMin).
+				SetInnerHits(esquery.NewInnerHits().
+					SetSize(1).
+					SetSort(esquery.Sort("offers.price.priceMajor", esquery.OrderAsc)).
+					SetSourceIncludes("offers.price")),
+		},
+		{
+			name: "has child",
+			expected: `{
+				"has_child": {
+					"type": "review",
+					"query": {"range": {"rating": {"gte": 4}}},
+					"score_mode": "avg",
+					"min_children": 2,
+					"inner_hits": {"name": "good_reviews"}
+				}
+			}`,
+			actual: esquery.HasChild("review", esquery.Range("rating").SetGte(4)).
+				SetScoreMode(esquery.NestedScoreAvg).
+				SetMinChildren(2).
+				SetInnerHits(esquery.NewInnerHits().SetName("good_reviews")),
+		},
+		{
+			name: "has parent",
+			expected: `{
+				"has_parent": {
+					"parent_type": "product",
+					"query": {"term": {"languageCode": {"value": "ja"}}},
+					"score": true
+				}
+			}`,
+			actual: esquery.HasParent("product", esquery.Term("languageCode", "ja")).SetScore(true),
+		},
+		{
+			name:     "parent id",
+			expected: `{"parent_id": {"type": "review", "id": "sku-1", "ignore_unmapped": true}}`,
+			actual:   esquery.ParentId("review", "sku-1").SetIgnoreUnmapped(true),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(test.actual)
+			assert.Nil(t, err)
+
+			assert.JSONEq(t, test.expected, string(jsonData))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package esquery
+
+import "encoding/json"
+
+type matchPhraseQuery struct {
+	Field          string         `json:"-"`
+	Query          string         `json:"query"`
+	Analyzer       string         `json:"analyzer,omitempty"`
+	Slop           *int           `json:"slop,omitempty"`
+	ZeroTermsQuery ZeroTermsQuery `json:"zero_terms_query,omitempty"`
+	Boost          *float64       `json:"boost,omitempty"`
+}
+
+func (m *matchPhraseQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"match_phrase": KeyVal{
+			m.Field: *m,
+		},
+	})
+}
+
+func (m *matchPhraseQuery) Validate() error {
+	if err := validateField("match_phrase", m.Field); err != nil {
+		return err
+	}
+	if err := validateNonNegative("match_phrase", "slop", m.Slop); err != nil {
+		return err
+	}
+	return validateZeroTermsQuery("match_phrase", m.ZeroTermsQuery)
+}
+
+func (m *matchPhraseQuery) SetAnalyzer(analyzer string) *matchPhraseQuery {
+	m.Analyzer = analyzer
+	return m
+}
+
+// SetSlop sets how many positions the terms may move and still match the phrase.
+func (m *matchPhraseQuery) SetSlop(slop int) *matchPhraseQuery {
+	m.Slop = &slop
+	return m
+}
+
+func (m *matchPhraseQuery) SetZeroTermsQuery(zeroTermsQuery ZeroTermsQuery) *matchPhraseQuery {
+	m.ZeroTermsQuery = zeroTermsQuery
+	return m
+}
+
+func (m *matchPhraseQuery) SetBoost(boost float64) *matchPhraseQuery {
+	m.Boost = &boost
+	return m
+}
+
+func MatchPhrase(field, query string) *matchPhraseQuery {
+	return &matchPhraseQuery{
+		Field: field,
+		Query: query,
+	}
+}
+
+type matchPhrasePrefixQuery struct {
+	Field          string         `json:"-"`
+	Query          string         `json:"query"`
+	Analyzer       string         `json:"analyzer,omitempty"`
+	MaxExpansions  *int           `json:"max_expansions,omitempty"`
+	Slop           *int           `json:"slop,omitempty"`
+	ZeroTermsQuery ZeroTermsQuery `json:"zero_terms_query,omitempty"`
+	Boost          *float64       `json:"boost,omitempty"`
+}
+
+func (m *matchPhrasePrefixQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"match_phrase_prefix": KeyVal{
+			m.Field: *m,
+		},
+	})
+}
+
+func (m *matchPhrasePrefixQuery) Validate() error {
+	if err := validateField("match_phrase_prefix", m.Field); err != nil {
+		return err
+	}
+	if err := validateNonNegative("match_phrase_prefix", "slop", m.Slop); err != nil {
+		return err
+	}
+	if m.MaxExpansions != nil && *m.MaxExpansions <= 0 {
+		return invalid("match_phrase_prefix", "max_expansions must be positive")
+	}
+	return validateZeroTermsQuery("match_phrase_prefix", m.ZeroTermsQuery)
+}
+
+func (m *matchPhrasePrefixQuery) SetAnalyzer(analyzer string) *matchPhrasePrefixQuery {
+	m.Analyzer = analyzer
+	return m
+}
+
+// SetMaxExpansions limits the number of terms the last term is expanded to.
+func (m *matchPhrasePrefixQuery) SetMaxExpansions(maxExpansions int) *matchPhrasePrefixQuery {
+	m.MaxExpansions = &maxExpansions
+	return m
+}
+
+func (m *matchPhrasePrefixQuery) SetSlop(slop int) *matchPhrasePrefixQuery {
+	m.Slop = &slop
+	return m
+}
+
+func (m *matchPhrasePrefixQuery) SetZeroTermsQuery(zeroTermsQuery ZeroTermsQuery) *matchPhrasePrefixQuery {
+	m.ZeroTermsQuery = zeroTermsQuery
+	return m
+}
+
+func (m *matchPhrasePrefixQuery) SetBoost(boost float64) *matchPhrasePrefixQuery {
+	m.Boost = &boost
+	return m
+}
+
+func MatchPhrasePrefix(field, query string) *matchPhrasePrefixQuery {
+	return &matchPhrasePrefixQuery{
+		Field: field,
+		Query: query,
+	}
+}
+
+type matchBoolPrefixQuery struct {
+	Field              string   `json:"-"`
+	Query              string   `json:"query"`
+	Analyzer           string   `json:"analyzer,omitempty"`
+	Operator           Operator `json:"operator,omitempty"`
+	MinimumShouldMatch *int     `json:"minimum_should_match,omitempty"`
+	Fuzziness          string   `json:"fuzziness,omitempty"`
+	PrefixLength       *int     `json:"prefix_length,omitempty"`
+	MaxExpansions      *int     `json:"max_expansions,omitempty"`
+	Boost              *float64 `json:"boost,omitempty"`
+}
+
+func (m *matchBoolPrefixQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"match_bool_prefix": KeyVal{
+			m.Field: *m,
+		},
+	})
+}
+
+func (m *matchBoolPrefixQuery) Validate() error {
+	if err := validateField("match_bool_prefix", m.Field); err != nil {
+		return err
+	}
+	if err := validateOperator("match_bool_prefix", m.Operator); err != nil {
+		return err
+	}
+	return validateFuzziness("match_bool_prefix", m.Fuzziness)
+}
+
+func (m *matchBoolPrefixQuery) SetAnalyzer(analyzer string) *matchBoolPrefixQuery {
+	m.Analyzer = analyzer
+	return m
+}
+
+func (m *matchBoolPrefixQuery) SetOperator(operator Operator) *matchBoolPrefixQuery {
+	m.Operator = operator
+	return m
+}
+
+func (m *matchBoolPrefixQuery) SetMinimumShouldMatch(min int) *matchBoolPrefixQuery {
+	m.MinimumShouldMatch = &min
+	return m
+}
+
+// SetFuzziness applies to every term except the last one, which is a prefix.
+func (m *matchBoolPrefixQuery) SetFuzziness(fuzziness string) *matchBoolPrefixQuery {
+	m.Fuzziness = fuzziness
+	return m
+}
+
+func (m *matchBoolPrefixQuery) SetPrefixLength(prefixLength int) *matchBoolPrefixQuery {
+	m.PrefixLength = &prefixLength
+	return m
+}
+
+func (m *matchBoolPrefixQuery) SetMaxExpansions(maxExpansions int) *matchBoolPrefixQuery {
+	m.MaxExpansions = &maxExpansions
+	return m
+}
+
+func (m *matchBoolPrefixQuery) SetBoost(boost float64) *matchBoolPrefixQuery {
+	m.Boost = &boost
+	return m
+}
+
+func MatchBoolPrefix(field, query string) *matchBoolPrefixQuery {
+	return &matchBoolPrefixQuery{
+		Field: field,
+		Query: query,
+	}
+}
@@ -0,0 +1,61 @@
+package esquery_test
+
+import (
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPhraseQueries(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   esquery.QueryType
+	}{
+		{
+			name: "match phrase",
+			expected: `{
+				"match_phrase": {
+					"title": {"query": "コットン シャツ", "slop": 1, "analyzer": "kuromoji"}
+				}
+			}`,
+			actual: esquery.MatchPhrase("title", "コットン シャツ").
+				SetSlop(1).
+				SetAnalyzer("kuromoji"),
+		},
+		{
+			name: "match phrase prefix",
+			expected: `{
+				"match_phrase_prefix": {
+					"title": {"query": "コットン シャ", "max_expansions": 20, "zero_terms_query": "none"}
+				}
+			}`,
+			actual: esquery.MatchPhrasePrefix("title", "コットン シャ").
+				SetMaxExpansions(20).
+				SetZeroTermsQuery(esquery.ZeroTermsNone),
+		},
+		{
+			name: "match bool prefix",
+			expected: `{
+				"match_bool_prefix": {
+					"title": {"query": "blue cot", "operator": "and", "fuzziness": "1", "boost": 2}
+				}
+			}`,
+			actual: esquery.MatchBoolPrefix("title", "blue cot").
+				SetOperator(esquery.OperatorAnd).
+				SetFuzziness("1").
+				SetBoost(2),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(test.actual)
+			assert.Nil(t, err)
+
+			assert.JSONEq(t, test.expected, string(jsonData))
+		})
+	}
+}
@@ -2,14 +2,38 @@ package esquery
 
 import "encoding/json"
 
+// FuzzinessAuto lets Elasticsearch pick the edit distance from the term length.
+// "AUTO:low,high" sets the length thresholds explicitly.
+const FuzzinessAuto = "AUTO"
+
+type Operator string
+
+const (
+	OperatorOr  Operator = "or"
+	OperatorAnd Operator = "and"
+)
+
+// ZeroTermsQuery decides what a query matches when the analyzer removes all
+// the terms, e.g. when the query consists only of stop words.
+type ZeroTermsQuery string
+
+const (
+	ZeroTermsNone ZeroTermsQuery = "none"
+	ZeroTermsAll  ZeroTermsQuery = "all"
+)
+
 type matchQuery struct {
-	Field              string   `json:"-"`
-	Query              string   `json:"query"`
-	Boost              *float64 `json:"boost,omitempty"`
-	MinimumShouldMatch *int     `json:"minimum_should_match,omitempty"`
-	Fuzziness          *float64 `json:"fuzziness,omitempty"`
-	PrefixLength       *int     `json:"prefix_length,omitempty"`
-	MaxExpansions      *int     `json:"max_expansions,omitempty"`
+	Field              string         `json:"-"`
+	Query              string         `json:"query"`
+	Boost              *float64       `json:"boost,omitempty"`
+	MinimumShouldMatch *int           `json:"minimum_should_match,omitempty"`
+	Fuzziness          string         `json:"fuzziness,omitempty"`
+	PrefixLength       *int           `json:"prefix_length,omitempty"`
+	MaxExpansions      *int           `json:"max_expansions,omitempty"`
+	Operator           Operator       `json:"operator,omitempty"`
+	Analyzer           string         `json:"analyzer,omitempty"`
+	ZeroTermsQuery     ZeroTermsQuery `json:"zero_terms_query,omitempty"`
+	Name               string         `json:"_name,omitempty"`
 }
 
 func (m *matchQuery) MarshalJSON() ([]byte, error) {
@@ -20,6 +44,22 @@ func (m *matchQuery) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (m *matchQuery) Validate() error {
+	if err := validateField("match", m.Field); err != nil {
+		return err
+	}
+	if err := validateOperator("match", m.Operator); err != nil {
+		return err
+	}
+	if err := validateZeroTermsQuery("match", m.ZeroTermsQuery); err != nil {
+		return err
+	}
+	if err := validateNonNegative("match", "prefix_length", m.PrefixLength); err != nil {
+		return err
+	}
+	return validateFuzziness("match", m.Fuzziness)
+}
+
 func (m *matchQuery) SetBoost(boost float64) *matchQuery {
 	m.Boost = &boost
 	return m
@@ -30,8 +70,9 @@ func (m *matchQuery) SetMinimumShouldMatch(min int) *matchQuery {
 	return m
 }
 
-func (m *matchQuery) SetFuzziness(fuzziness float64) *matchQuery {
-	m.Fuzziness = &fuzziness
+// SetFuzziness sets the maximum edit distance, e.g. "1", "2" or FuzzinessAuto.
+func (m *matchQuery) SetFuzziness(fuzziness string) *matchQuery {
+	m.Fuzziness = fuzziness
 	return m
 }
 
@@ -45,6 +86,26 @@ func (m *matchQuery) SetMaxExpansions(maxExpansions int) *matchQuery {
 	return m
 }
 
+func (m *matchQuery) SetOperator(operator Operator) *matchQuery {
+	m.Operator = operator
+	return m
+}
+
+func (m *matchQuery) SetAnalyzer(analyzer string) *matchQuery {
+	m.Analyzer = analyzer
+	return m
+}
+
+func (m *matchQuery) SetZeroTermsQuery(zeroTermsQuery ZeroTermsQuery) *matchQuery {
+	m.ZeroTermsQuery = zeroTermsQuery
+	return m
+}
+
+func (m *matchQuery) SetName(name string) *matchQuery {
+	m.Name = name
+	return m
+}
+
 func Match(field, query string) *matchQuery {
 	return &matchQuery{
 		Field: field,
@@ -66,6 +127,10 @@ func (m *matchAllQuery) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (m *matchAllQuery) Validate() error {
+	return nil
+}
+
 func (m *matchAllQuery) SetBoost(boost float64) *matchAllQuery {
 	m.Boost = &boost
 	return m
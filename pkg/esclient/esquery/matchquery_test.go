@@ -39,3 +39,30 @@ func TestMatchAllQuery(t *testing.T) {
 
 	assert.JSONEq(t, expected, string(jsonData))
 }
+
+func TestMatchQueryWithOptions(t *testing.T) {
+	expected := `{
+		"match": {
+			"title": {
+				"query": "青いシャツ",
+				"operator": "and",
+				"analyzer": "kuromoji",
+				"zero_terms_query": "all",
+				"fuzziness": "AUTO",
+				"prefix_length": 1
+			}
+		}
+	}`
+
+	actual := esquery.Match("title", "青いシャツ").
+		SetOperator(esquery.OperatorAnd).
+		SetAnalyzer("kuromoji").
+		SetZeroTermsQuery(esquery.ZeroTermsAll).
+		SetFuzziness(esquery.FuzzinessAuto).
+		SetPrefixLength(1)
+
+	jsonData, err := json.Marshal(actual)
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, expected, string(jsonData))
+}
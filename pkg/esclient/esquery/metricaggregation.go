@@ -0,0 +1,115 @@
+package esquery
+
+// metricAggregation is a single value or stats aggregation over a numeric field.
+type metricAggregation struct {
+	kind    string
+	Field   string      `json:"field"`
+	Missing interface{} `json:"missing,omitempty"`
+}
+
+func (m *metricAggregation) MarshalJSON() ([]byte, error) {
+	return marshalAggregation(m.kind, *m, nil)
+}
+
+func (m *metricAggregation) Validate() error {
+	return validateField(m.kind+" aggregation", m.Field)
+}
+
+// SetMissing sets the value used for documents without the field.
+func (m *metricAggregation) SetMissing(missing interface{}) *metricAggregation {
+	m.Missing = missing
+	return m
+}
+
+func AvgAgg(field string) *metricAggregation {
+	return &metricAggregation{kind: "avg", Field: field}
+}
+
+func MinAgg(field string) *metricAggregation {
+	return &metricAggregation{kind: "min", Field: field}
+}
+
+func MaxAgg(field string) *metricAggregation {
+	return &metricAggregation{kind: "max", Field: field}
+}
+
+func SumAgg(field string) *metricAggregation {
+	return &metricAggregation{kind: "sum", Field: field}
+}
+
+func StatsAgg(field string) *metricAggregation {
+	return &metricAggregation{kind: "stats", Field: field}
+}
+
+type cardinalityAggregation struct {
+	Field              string `json:"field"`
+	PrecisionThreshold *int   `json:"precision_threshold,omitempty"`
+}
+
+func CardinalityAgg(field string) *cardinalityAggregation {
+	return &cardinalityAggregation{Field: field}
+}
+
+func (c *cardinalityAggregation) MarshalJSON() ([]byte, error) {
+	return marshalAggregation("cardinality", *c, nil)
+}
+
+func (c *cardinalityAggregation) Validate() error {
+	if err := validateField("cardinality aggregation", c.Field); err != nil {
+		return err
+	}
+	return validateNonNegative("cardinality aggregation", "precision_threshold", c.PrecisionThreshold)
+}
+
+func (c *cardinalityAggregation) SetPrecisionThreshold(threshold int) *cardinalityAggregation {
+	c.PrecisionThreshold = &threshold
+	return c
+}
+
+type topHitsAggregation struct {
+	Size   *int          `json:"size,omitempty"`
+	From   *int          `json:"from,omitempty"`
+	Sort   []*sort       `json:"sort,omitempty"`
+	Source *SourceFilter `json:"_source,omitempty"`
+}
+
+func TopHitsAgg() *topHitsAggregation {
+	return &topHitsAggregation{}
+}
+
+func (t *topHitsAggregation) MarshalJSON() ([]byte, error) {
+	return marshalAggregation("top_hits", *t, nil)
+}
+
+func (t *topHitsAggregation) Validate() error {
+	if err := validateNonNegative("top_hits aggregation", "size", t.Size); err != nil {
+		return err
+	}
+	if err := validateNonNegative("top_hits aggregation", "from", t.From); err != nil {
+		return err
+	}
+	return validateSort("top_hits aggregation", t.Sort)
+}
+
+func (t *topHitsAggregation) SetSize(size int) *topHitsAggregation {
+	t.Size = &size
+	return t
+}
+
+func (t *topHitsAggregation) SetFrom(from int) *topHitsAggregation {
+	t.From = &from
+	return t
+}
+
+func (t *topHitsAggregation) SetSort(sort ...*sort) *topHitsAggregation {
+	t.Sort = append(t.Sort, sort...)
+	return t
+}
+
+func (t *topHitsAggregation) SetSourceIncludes(fields ...string) *topHitsAggregation {
+	if t.Source == nil {
+		t.Source = &SourceFilter{}
+	}
+	t.Source.Includes = append(t.Source.Includes, fields...)
+	return t
+}
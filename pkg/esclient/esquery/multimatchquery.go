@@ -0,0 +1,231 @@
+package esquery
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+type MultiMatchType string
+
+const (
+	MultiMatchBestFields   MultiMatchType = "best_fields"
+	MultiMatchMostFields   MultiMatchType = "most_fields"
+	MultiMatchCrossFields  MultiMatchType = "cross_fields"
+	MultiMatchPhrase       MultiMatchType = "phrase"
+	MultiMatchPhrasePrefix MultiMatchType = "phrase_prefix"
+	MultiMatchBoolPrefix   MultiMatchType = "bool_prefix"
+)
+
+// boostedField formats a field with a per-field boost, e.g. "title^3".
+func boostedField(field string, boost float64) string {
+	return field + "^" + strconv.FormatFloat(boost, 'f', -1, 64)
+}
+
+type multiMatchQuery struct {
+	Query              string         `json:"query"`
+	Fields             []string       `json:"fields,omitempty"`
+	Type               MultiMatchType `json:"type,omitempty"`
+	Operator           Operator       `json:"operator,omitempty"`
+	Analyzer           string         `json:"analyzer,omitempty"`
+	MinimumShouldMatch *int           `json:"minimum_should_match,omitempty"`
+	TieBreaker         *float64       `json:"tie_breaker,omitempty"`
+	Fuzziness          string         `json:"fuzziness,omitempty"`
+	PrefixLength       *int           `json:"prefix_length,omitempty"`
+	MaxExpansions      *int           `json:"max_expansions,omitempty"`
+	Slop               *int           `json:"slop,omitempty"`
+	ZeroTermsQuery     ZeroTermsQuery `json:"zero_terms_query,omitempty"`
+	Boost              *float64       `json:"boost,omitempty"`
+	Name               string         `json:"_name,omitempty"`
+}
+
+func (m *multiMatchQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"multi_match": *m,
+	})
+}
+
+func (m *multiMatchQuery) Validate() error {
+	for _, field := range m.Fields {
+		if err := validateField("multi_match", field); err != nil {
+			return err
+		}
+	}
+	switch m.Type {
+	case "", MultiMatchBestFields, MultiMatchMostFields, MultiMatchBoolPrefix:
+	case MultiMatchCrossFields, MultiMatchPhrase, MultiMatchPhrasePrefix:
+		if m.Fuzziness != "" {
+			return invalid("multi_match", "fuzziness is not supported by the %s type", m.Type)
+		}
+	default:
+		return invalid("multi_match", "unknown type %q", m.Type)
+	}
+	if err := validateOperator("multi_match", m.Operator); err != nil {
+		return err
+	}
+	if err := validateFraction("multi_match", "tie_breaker", m.TieBreaker); err != nil {
+		return err
+	}
+	if err := validateNonNegative("multi_match", "slop", m.Slop); err != nil {
+		return err
+	}
+	if err := validateZeroTermsQuery("multi_match", m.ZeroTermsQuery); err != nil {
+		return err
+	}
+	return validateFuzziness("multi_match", m.Fuzziness)
+}
+
+// SetFields adds fields to search. A field may carry its own boost, e.g. "title^3".
+func (m *multiMatchQuery) SetFields(fields ...string) *multiMatchQuery {
+	m.Fields = append(m.Fields, fields...)
+	return m
+}
+
+func (m *multiMatchQuery) SetFieldWithBoost(field string, boost float64) *multiMatchQuery {
+	m.Fields = append(m.Fields, boostedField(field, boost))
+	return m
+}
+
+func (m *multiMatchQuery) SetType(multiMatchType MultiMatchType) *multiMatchQuery {
+	m.Type = multiMatchType
+	return m
+}
+
+func (m *multiMatchQuery) SetOperator(operator Operator) *multiMatchQuery {
+	m.Operator = operator
+	return m
+}
+
+func (m *multiMatchQuery) SetAnalyzer(analyzer string) *multiMatchQuery {
+	m.Analyzer = analyzer
+	return m
+}
+
+func (m *multiMatchQuery) SetMinimumShouldMatch(min int) *multiMatchQuery {
+	m.MinimumShouldMatch = &min
+	return m
+}
+
+// SetTieBreaker adds this fraction of the scores of the other matching fields
+// to the score of the best field.
+func (m *multiMatchQuery) SetTieBreaker(tieBreaker float64) *multiMatchQuery {
+	m.TieBreaker = &tieBreaker
+	return m
+}
+
+func (m *multiMatchQuery) SetFuzziness(fuzziness string) *multiMatchQuery {
+	m.Fuzziness = fuzziness
+	return m
+}
+
+func (m *multiMatchQuery) SetPrefixLength(prefixLength int) *multiMatchQuery {
+	m.PrefixLength = &prefixLength
+	return m
+}
+
+func (m *multiMatchQuery) SetMaxExpansions(maxExpansions int) *multiMatchQuery {
+	m.MaxExpansions = &maxExpansions
+	return m
+}
+
+// SetSlop applies to the phrase and phrase_prefix types.
+func (m *multiMatchQuery) SetSlop(slop int) *multiMatchQuery {
+	m.Slop = &slop
+	return m
+}
+
+func (m *multiMatchQuery) SetZeroTermsQuery(zeroTermsQuery ZeroTermsQuery) *multiMatchQuery {
+	m.ZeroTermsQuery = zeroTermsQuery
+	return m
+}
+
+func (m *multiMatchQuery) SetBoost(boost float64) *multiMatchQuery {
+	m.Boost = &boost
+	return m
+}
+
+func (m *multiMatchQuery) SetName(name string) *multiMatchQuery {
+	m.Name = name
+	return m
+}
+
+func MultiMatch(query string, fields ...string) *multiMatchQuery {
+	return &multiMatchQuery{
+		Query:  query,
+		Fields: fields,
+	}
+}
+
+type combinedFieldsQuery struct {
+	Query                           string         `json:"query"`
+	Fields                          []string       `json:"fields"`
+	Operator                        Operator       `json:"operator,omitempty"`
+	MinimumShouldMatch              *int           `json:"minimum_should_match,omitempty"`
+	ZeroTermsQuery                  ZeroTermsQuery `json:"zero_terms_query,omitempty"`
+	AutoGenerateSynonymsPhraseQuery *bool          `json:"auto_generate_synonyms_phrase_query,omitempty"`
+	Boost                           *float64       `json:"boost,omitempty"`
+}
+
+func (c *combinedFieldsQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"combined_fields": *c,
+	})
+}
+
+func (c *combinedFieldsQuery) Validate() error {
+	if len(c.Fields) == 0 {
+		return invalid("combined_fields", "no fields")
+	}
+	for _, field := range c.Fields {
+		if err := validateField("combined_fields", field); err != nil {
+			return err
+		}
+	}
+	if err := validateOperator("combined_fields", c.Operator); err != nil {
+		return err
+	}
+	return validateZeroTermsQuery("combined_fields", c.ZeroTermsQuery)
+}
+
+func (c *combinedFieldsQuery) SetFields(fields ...string) *combinedFieldsQuery {
+	c.Fields = append(c.Fields, fields...)
+	return c
+}
+
+func (c *combinedFieldsQuery) SetFieldWithBoost(field string, boost float64) *combinedFieldsQuery {
+	c.Fields = append(c.Fields, boostedField(field, boost))
+	return c
+}
+
+func (c *combinedFieldsQuery) SetOperator(operator Operator) *combinedFieldsQuery {
+	c.Operator = operator
+	return c
+}
+
+func (c *combinedFieldsQuery) SetMinimumShouldMatch(min int) *combinedFieldsQuery {
+	c.MinimumShouldMatch = &min
+	return c
+}
+
+func (c *combinedFieldsQuery) SetZeroTermsQuery(zeroTermsQuery ZeroTermsQuery) *combinedFieldsQuery {
+	c.ZeroTermsQuery = zeroTermsQuery
+	return c
+}
+
+func (c *combinedFieldsQuery) SetAutoGenerateSynonymsPhraseQuery(enabled bool) *combinedFieldsQuery {
+	c.AutoGenerateSynonymsPhraseQuery = &enabled
+	return c
+}
+
+func (c *combinedFieldsQuery) SetBoost(boost float64) *combinedFieldsQuery {
+	c.Boost = &boost
+	return c
+}
+
+// CombinedFields searches text fields as if they were one field. All fields
+// must share the same search analyzer.
+func CombinedFields(query string, fields ...string) *combinedFieldsQuery {
+	return &combinedFieldsQuery{
+		Query:  query,
+		Fields: fields,
+	}
+}
@@ -0,0 +1,77 @@
+package esquery_test
+
+import (
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiMatchQuery(t *testing.T) {
+	expected := `{
+		"multi_match": {
+			"query": "ブルー シャツ",
+			"fields": ["title^3", "title.ngram", "description^0.5"],
+			"type": "most_fields",
+			"operator": "or",
+			"minimum_should_match": 2,
+			"tie_breaker": 0.3
+		}
+	}`
+
+	actual := esquery.MultiMatch("ブルー シャツ", "title^3", "title.ngram").
+		SetFieldWithBoost("description", 0.5).
+		SetType(esquery.MultiMatchMostFields).
+		SetOperator(esquery.OperatorOr).
+		SetMinimumShouldMatch(2).
+		SetTieBreaker(0.3)
+
+	jsonData, err := json.Marshal(actual)
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, expected, string(jsonData))
+}
+
+func TestMultiMatchBoolPrefixQuery(t *testing.T) {
+	expected := `{
+		"multi_match": {
+			"query": "ブルー シャ",
+			"fields": ["title", "title.ngram"],
+			"type": "bool_prefix",
+			"fuzziness": "AUTO"
+		}
+	}`
+
+	actual := esquery.MultiMatch("ブルー シャ").
+		SetFields("title", "title.ngram").
+		SetType(esquery.MultiMatchBoolPrefix).
+		SetFuzziness(esquery.FuzzinessAuto)
+
+	jsonData, err := json.Marshal(actual)
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, expected, string(jsonData))
+}
+
+func TestCombinedFieldsQuery(t *testing.T) {
+	expected := `{
+		"combined_fields": {
+			"query": "cotton shirt",
+			"fields": ["title^2", "description"],
+			"operator": "and",
+			"auto_generate_synonyms_phrase_query": false
+		}
+	}`
+
+	actual := esquery.CombinedFields("cotton shirt").
+		SetFieldWithBoost("title", 2).
+		SetFields("description").
+		SetOperator(esquery.OperatorAnd).
+		SetAutoGenerateSynonymsPhraseQuery(false)
+
+	jsonData, err := json.Marshal(actual)
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, expected, string(jsonData))
+}
@@ -0,0 +1,349 @@
+package esquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// RawQuery is a query kept as JSON. ParseQuery returns it for the query types
+// and options esquery does not model, and it can be used to send such a query.
+type RawQuery json.RawMessage
+
+func (r RawQuery) MarshalJSON() ([]byte, error) {
+	if len(r) == 0 {
+		return nil, errors.New("esquery: empty raw query")
+	}
+	return r, nil
+}
+
+// Validate checks that the raw query is a JSON object.
+func (r RawQuery) Validate() error {
+	var query map[string]json.RawMessage
+	if err := json.Unmarshal(r, &query); err != nil || len(query) == 0 {
+		return invalid("raw query", "not a JSON object")
+	}
+	return nil
+}
+
+// ParseQuery rebuilds a query from its JSON, such as `{"bool": {...}}`.
+// Queries that esquery cannot represent exactly, because of their type or one
+// of their options, are returned as a RawQuery, so marshalling the result
+// gives back the same query.
+func ParseQuery(data []byte) (QueryType, error) {
+	kind, body, err := fieldQuery(data)
+	if err != nil {
+		return nil, fmt.Errorf("esquery: parse query: %w", err)
+	}
+
+	query, err := parseQuery(kind, body)
+	if err != nil || query == nil {
+		return RawQuery(bytes.TrimSpace(data)), nil
+	}
+	return query, nil
+}
+
+func parseQuery(kind string, body json.RawMessage) (QueryType, error) {
+	switch kind {
+	case "bool":
+		return parseBoolQuery(body)
+	case "match_all":
+		q := &matchAllQuery{}
+		return q, decodeStrict(body, q)
+	case "match":
+		q := &matchQuery{}
+		field, err := decodeFieldQuery(body, q, &q.Query)
+		q.Field = field
+		return q, err
+	case "match_phrase":
+		q := &matchPhraseQuery{}
+		field, err := decodeFieldQuery(body, q, &q.Query)
+		q.Field = field
+		return q, err
+	case "match_phrase_prefix":
+		q := &matchPhrasePrefixQuery{}
+		field, err := decodeFieldQuery(body, q, &q.Query)
+		q.Field = field
+		return q, err
+	case "match_bool_prefix":
+		q := &matchBoolPrefixQuery{}
+		field, err := decodeFieldQuery(body, q, &q.Query)
+		q.Field = field
+		return q, err
+	case "multi_match":
+		q := &multiMatchQuery{}
+		return q, decodeStrict(body, q)
+	case "combined_fields":
+		q := &combinedFieldsQuery{}
+		return q, decodeStrict(body, q)
+	case "query_string":
+		q := &queryStringQuery{}
+		return q, decodeStrict(body, q)
+	case "simple_query_string":
+		q := &simpleQueryStringQuery{}
+		return q, decodeStrict(body, q)
+	case "term":
+		q := &termQuery{}
+		field, err := decodeFieldQuery(body, q, &q.Value)
+		q.Field = field
+		return q, err
+	case "terms":
+		return parseTermsQuery(body)
+	case "ids":
+		q := &idsQuery{}
+		return q, decodeStrict(body, q)
+	case "exists":
+		q := &existsQuery{}
+		return q, decodeStrict(body, q)
+	case "prefix":
+		q := &prefixQuery{}
+		field, err := decodeFieldQuery(body, q, &q.Value)
+		q.Field = field
+		return q, err
+	case "wildcard":
+		q := &wildcardQuery{}
+		field, err := decodeFieldQuery(body, q, &q.Value)
+		q.Field = field
+		return q, err
+	case "regexp":
+		q := &regexpQuery{}
+		field, err := decodeFieldQuery(body, q, &q.Value)
+		q.Field = field
+		return q, err
+	case "fuzzy":
+		q := &fuzzyQuery{}
+		field, err := decodeFieldQuery(body, q, &q.Value)
+		q.Field = field
+		return q, err
+	case "range":
+		q := &rangeQuery{}
+		field, err := decodeFieldQuery(body, q, nil)
+		q.Field = field
+		return q, err
+	case "nested":
+		return parseNestedQuery(body)
+	case "constant_score":
+		return parseConstantScoreQuery(body)
+	case "dis_max":
+		return parseDisMaxQuery(body)
+	case "boosting":
+		return parseBoostingQuery(body)
+	}
+	return nil, nil
+}
+
+func parseBoolQuery(body json.RawMessage) (QueryType, error) {
+	var clauses struct {
+		Must               json.RawMessage `json:"must"`
+		MustNot            json.RawMessage `json:"must_not"`
+		Should             json.RawMessage `json:"should"`
+		Filter             json.RawMessage `json:"filter"`
+		MinimumShouldMatch int16           `json:"minimum_should_match"`
+		Boost              float32         `json:"boost"`
+		Name               string          `json:"_name"`
+	}
+	if err := decodeStrict(body, &clauses); err != nil {
+		return nil, err
+	}
+
+	q := &boolQuery{
+		MinimumShouldMatch: clauses.MinimumShouldMatch,
+		Boost:              clauses.Boost,
+		Name:               clauses.Name,
+	}
+	var err error
+	if q.Must, err = parseQueries(clauses.Must); err != nil {
+		return nil, err
+	}
+	if q.MustNot, err = parseQueries(clauses.MustNot); err != nil {
+		return nil, err
+	}
+	if q.Should, err = parseQueries(clauses.Should); err != nil {
+		return nil, err
+	}
+	if q.Filter, err = parseQueries(clauses.Filter); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func parseTermsQuery(body json.RawMessage) (QueryType, error) {
+	var fields map[string]json.RawMessage
+	if err := decodeStrict(body, &fields); err != nil {
+		return nil, err
+	}
+
+	q := &termsQuery{}
+	if boost, found := fields["boost"]; found {
+		if err := decodeStrict(boost, &q.Boost); err != nil {
+			return nil, err
+		}
+		delete(fields, "boost")
+	}
+	if len(fields) != 1 {
+		return nil, errors.New("terms query must have one field")
+	}
+	for field, values := range fields {
+		q.Field = field
+		if isObject(values) {
+			q.Lookup = &termsLookup{}
+			return q, decodeStrict(values, q.Lookup)
+		}
+		return q, decodeStrict(values, &q.Values)
+	}
+	return q, nil
+}
+
+func parseNestedQuery(body json.RawMessage) (QueryType, error) {
+	var nested struct {
+		nestedQuery
+		Query json.RawMessage `json:"query"`
+	}
+	if err := decodeStrict(body, &nested); err != nil {
+		return nil, err
+	}
+	q := &nested.nestedQuery
+	query, err := parseQueryValue(nested.Query)
+	q.Query = query
+	return q, err
+}
+
+func parseConstantScoreQuery(body json.RawMessage) (QueryType, error) {
+	var constantScore struct {
+		constantScoreQuery
+		Filter json.RawMessage `json:"filter"`
+	}
+	if err := decodeStrict(body, &constantScore); err != nil {
+		return nil, err
+	}
+	q := &constantScore.constantScoreQuery
+	filter, err := parseQueryValue(constantScore.Filter)
+	q.Filter = filter
+	return q, err
+}
+
+func parseDisMaxQuery(body json.RawMessage) (QueryType, error) {
+	var disMax struct {
+		disMaxQuery
+		Queries json.RawMessage `json:"queries"`
+	}
+	if err := decodeStrict(body, &disMax); err != nil {
+		return nil, err
+	}
+	q := &disMax.disMaxQuery
+	queries, err := parseQueries(disMax.Queries)
+	q.Queries = queries
+	return q, err
+}
+
+func parseBoostingQuery(body json.RawMessage) (QueryType, error) {
+	var boosting struct {
+		boostingQuery
+		Positive json.RawMessage `json:"positive"`
+		Negative json.RawMessage `json:"negative"`
+	}
+	if err := decodeStrict(body, &boosting); err != nil {
+		return nil, err
+	}
+	q := &boosting.boostingQuery
+	var err error
+	if q.Positive, err = parseQueryValue(boosting.Positive); err != nil {
+		return nil, err
+	}
+	if q.Negative, err = parseQueryValue(boosting.Negative); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// parseQueries parses a bool clause, which is either one query or a list.
+func parseQueries(data json.RawMessage) ([]QueryType, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if !isArray(data) {
+		query, err := ParseQuery(data)
+		if err != nil {
+			return nil, err
+		}
+		return []QueryType{query}, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	queries := make([]QueryType, 0, len(items))
+	for _, item := range items {
+		query, err := ParseQuery(item)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+	}
+	return queries, nil
+}
+
+func parseQueryValue(data json.RawMessage) (QueryType, error) {
+	if len(data) == 0 {
+		return nil, errors.New("missing query")
+	}
+	return ParseQuery(data)
+}
+
+// decodeFieldQuery decodes a query keyed by field name into query, or its
+// short form, e.g. {"title": "シャツ"}, into shorthand.
+func decodeFieldQuery(data json.RawMessage, query interface{}, shorthand interface{}) (string, error) {
+	field, body, err := fieldQuery(data)
+	if err != nil {
+		return "", err
+	}
+	if isObject(body) {
+		return field, decodeStrict(body, query)
+	}
+	if shorthand == nil {
+		return "", fmt.Errorf("%s must be an object", field)
+	}
+	return field, decodeStrict(body, shorthand)
+}
+
+// fieldQuery returns the only key of a JSON object and its value.
+func fieldQuery(data []byte) (string, json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", nil, err
+	}
+	if len(fields) != 1 {
+		return "", nil, fmt.Errorf("expected an object with one key, got %d", len(fields))
+	}
+	for key, value := range fields {
+		return key, value, nil
+	}
+	return "", nil, nil
+}
+
+// decodeStrict fails on the fields the value does not have, so that no
+// option is lost, and keeps numbers as json.Number to preserve precision.
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("unexpected data after the value")
+	}
+	return nil
+}
+
+func isObject(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
+
+func isArray(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '['
+}
@@ -0,0 +1,160 @@
+package esquery_test
+
+import (
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQueryRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		query esquery.QueryType
+	}{
+		{name: "match all", query: esquery.MatchAll().SetBoost(1.5)},
+		{name: "match", query: esquery.Match("title", "シャツ").SetOperator(esquery.OperatorAnd).SetFuzziness(esquery.FuzzinessAuto).SetName("title")},
+		{name: "match phrase", query: esquery.MatchPhrase("title", "コットン シャツ").SetSlop(1)},
+		{name: "match phrase prefix", query: esquery.MatchPhrasePrefix("title", "コットン シャ").SetMaxExpansions(10)},
+		{name: "match bool prefix", query: esquery.MatchBoolPrefix("title", "blue cot").SetOperator(esquery.OperatorAnd)},
+		{name: "multi match", query: esquery.MultiMatch("シャツ", "title^3", "title.ngram").SetType(esquery.MultiMatchMostFields).SetTieBreaker(0.3)},
+		{name: "combined fields", query: esquery.CombinedFields("cotton shirt", "title", "description").SetOperator(esquery.OperatorOr)},
+		{name: "query string", query: esquery.QueryString("title:シャツ AND price.priceMajor:>1000").SetDefaultField("title").SetLenient(true)},
+		{name: "simple query string", query: esquery.SimpleQueryString("シャツ -used").SetFlags(esquery.SimpleQueryStringNot)},
+		{name: "term with string", query: esquery.Term("languageCode", "ja").SetBoost(2).SetCaseInsensitive(true)},
+		{name: "term with large number", query: esquery.Term("gtin", json.Number("4901234567894123"))},
+		{name: "term with boolean", query: esquery.Term("isDeleted", false)},
+		{name: "terms", query: esquery.Terms("additionalProperties.Condition", "new", "used").SetBoost(1.2)},
+		{name: "terms lookup", query: esquery.TermsLookup("sku", "wishlists", "user-1", "skus")},
+		{name: "ids", query: esquery.Ids("sku-1", "sku-2")},
+		{name: "exists", query: esquery.Exists("price")},
+		{name: "prefix", query: esquery.Prefix("sku", "JP-").SetCaseInsensitive(true)},
+		{name: "wildcard", query: esquery.Wildcard("link", "*.example.com/*")},
+		{name: "regexp", query: esquery.Regexp("sku", "JP-[0-9]+").SetFlags("ALL")},
+		{name: "fuzzy", query: esquery.Fuzzy("title", "shrit").SetFuzziness("2")},
+		{name: "range", query: esquery.Range("record.Updated").SetGte("now-30d/d").SetLt("now").SetFormat("strict_date_optional_time")},
+		{name: "nested with inner hits", query: esquery.Nested("offers", esquery.Range("offers.price").SetLte(1000)).
+			SetScoreMode(esquery.NestedScoreMin).
+			SetInnerHits(esquery.NewInnerHits().SetSize(1).SetSort(esquery.Sort("offers.price", esquery.OrderAsc)).SetSourceIncludes("offers.price"))},
+		{name: "constant score", query: esquery.ConstantScore(esquery.Term("languageCode", "ja")).SetBoost(1.2)},
+		{name: "dis max", query: esquery.DisMax(esquery.Match("title", "シャツ"), esquery.Match("title.ngram", "シャツ")).SetTieBreaker(0.7)},
+		{name: "boosting", query: esquery.Boosting(esquery.Match("title", "シャツ"), esquery.Term("additionalProperties.Condition", "used"), 0.5)},
+		{name: "bool", query: esquery.Bool().
+			SetMust(esquery.Match("title", "シャツ")).
+			SetMustNot(esquery.Term("isDeleted", true)).
+			SetShould(esquery.Term("additionalProperties.Color", "ブルー"), esquery.Term("additionalProperties.Color", "ネイビー")).
+			SetFilter(esquery.Range("price.priceMajor").SetGte(1000).SetLte(5000)).
+			SetMinimumShouldMatch(1).
+			SetBoost(1.1).
+			SetName("products")},
+		{name: "bool with unmodelled clause", query: esquery.Bool().
+			SetMust(esquery.FunctionScore(esquery.Match("title", "シャツ")).AddFunction(esquery.FieldValueFactor("additionalProperties.Ratings"))).
+			SetFilter(esquery.GeoDistance("store.location", esquery.GeoPoint{Lat: 35.68, Lon: 139.76}, "5km"))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected, err := json.Marshal(test.query)
+			assert.NoError(t, err)
+
+			parsed, err := esquery.ParseQuery(expected)
+			assert.NoError(t, err)
+			_, isRaw := parsed.(esquery.RawQuery)
+			assert.False(t, isRaw, "parsed as a raw query")
+
+			actual, err := json.Marshal(parsed)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(actual))
+		})
+	}
+}
+
+func TestParseQueryShortForms(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "match",
+			data:     `{"match": {"title": "シャツ"}}`,
+			expected: `{"match": {"title": {"query": "シャツ"}}}`,
+		},
+		{
+			name:     "term",
+			data:     `{"term": {"price.priceMajor": 1299}}`,
+			expected: `{"term": {"price.priceMajor": {"value": 1299}}}`,
+		},
+		{
+			name:     "bool with single clause",
+			data:     `{"bool": {"filter": {"exists": {"field": "price"}}}}`,
+			expected: `{"bool": {"filter": [{"exists": {"field": "price"}}]}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := esquery.ParseQuery([]byte(test.data))
+			assert.NoError(t, err)
+
+			actual, err := json.Marshal(parsed)
+			assert.NoError(t, err)
+			assert.JSONEq(t, test.expected, string(actual))
+		})
+	}
+}
+
+func TestParseQueryRawPassthrough(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "unknown type", data: `{"percolate": {"field": "query", "document": {"title": "シャツ"}}}`},
+		{name: "unknown option", data: `{"match": {"title": {"query": "シャツ", "auto_generate_synonyms_phrase_query": false}}}`},
+		{name: "percentage minimum should match", data: `{"bool": {"should": [{"term": {"a": {"value": "b"}}}], "minimum_should_match": "75%"}}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := esquery.ParseQuery([]byte(test.data))
+			assert.NoError(t, err)
+			assert.IsType(t, esquery.RawQuery{}, parsed)
+
+			actual, err := json.Marshal(parsed)
+			assert.NoError(t, err)
+			assert.JSONEq(t, test.data, string(actual))
+		})
+	}
+}
+
+func TestParseQueryAndModify(t *testing.T) {
+	saved := `{"bool": {"must": [{"match": {"title": {"query": "シャツ"}}}], "filter": [{"percolate": {"field": "q"}}]}}`
+
+	parsed, err := esquery.ParseQuery([]byte(saved))
+	assert.NoError(t, err)
+
+	query := esquery.NewSearchQueryBuilder().
+		SetQuery(esquery.Bool().SetMust(parsed).SetFilter(esquery.Term("languageCode", "ja"))).
+		Build()
+
+	actual, err := json.Marshal(query)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"query": {
+			"bool": {
+				"must": [{"bool": {"must": [{"match": {"title": {"query": "シャツ"}}}], "filter": [{"percolate": {"field": "q"}}]}}],
+				"filter": [{"term": {"languageCode": {"value": "ja"}}}]
+			}
+		}
+	}`, string(actual))
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, data := range []string{``, `null`, `[]`, `"match"`, `{}`, `{"match": {}, "term": {}}`, `{"match":`} {
+		t.Run(data, func(t *testing.T) {
+			_, err := esquery.ParseQuery([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
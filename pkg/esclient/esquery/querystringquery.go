@@ -0,0 +1,292 @@
+package esquery
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type queryStringQuery struct {
+	Query                string   `json:"query"`
+	DefaultField         string   `json:"default_field,omitempty"`
+	Fields               []string `json:"fields,omitempty"`
+	DefaultOperator      Operator `json:"default_operator,omitempty"`
+	Analyzer             string   `json:"analyzer,omitempty"`
+	AllowLeadingWildcard *bool    `json:"allow_leading_wildcard,omitempty"`
+	AnalyzeWildcard      *bool    `json:"analyze_wildcard,omitempty"`
+	Fuzziness            string   `json:"fuzziness,omitempty"`
+	Lenient              *bool    `json:"lenient,omitempty"`
+	MinimumShouldMatch   *int     `json:"minimum_should_match,omitempty"`
+	PhraseSlop           *int     `json:"phrase_slop,omitempty"`
+	TimeZone             string   `json:"time_zone,omitempty"`
+	Boost                *float64 `json:"boost,omitempty"`
+}
+
+func (q *queryStringQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"query_string": *q,
+	})
+}
+
+func (q *queryStringQuery) Validate() error {
+	if strings.TrimSpace(q.Query) == "" {
+		return invalid("query_string", "query is empty")
+	}
+	if err := validateOperator("query_string", q.DefaultOperator); err != nil {
+		return err
+	}
+	if err := validateNonNegative("query_string", "phrase_slop", q.PhraseSlop); err != nil {
+		return err
+	}
+	return validateFuzziness("query_string", q.Fuzziness)
+}
+
+// SetDefaultField sets the field searched when the query names none.
+func (q *queryStringQuery) SetDefaultField(field string) *queryStringQuery {
+	q.DefaultField = field
+	return q
+}
+
+// SetFields adds fields to search. A field may carry its own boost, e.g. "title^3".
+func (q *queryStringQuery) SetFields(fields ...string) *queryStringQuery {
+	q.Fields = append(q.Fields, fields...)
+	return q
+}
+
+func (q *queryStringQuery) SetDefaultOperator(operator Operator) *queryStringQuery {
+	q.DefaultOperator = operator
+	return q
+}
+
+func (q *queryStringQuery) SetAnalyzer(analyzer string) *queryStringQuery {
+	q.Analyzer = analyzer
+	return q
+}
+
+func (q *queryStringQuery) SetAllowLeadingWildcard(allow bool) *queryStringQuery {
+	q.AllowLeadingWildcard = &allow
+	return q
+}
+
+func (q *queryStringQuery) SetAnalyzeWildcard(analyze bool) *queryStringQuery {
+	q.AnalyzeWildcard = &analyze
+	return q
+}
+
+func (q *queryStringQuery) SetFuzziness(fuzziness string) *queryStringQuery {
+	q.Fuzziness = fuzziness
+	return q
+}
+
+// SetLenient ignores format errors, such as text in a numeric field.
+func (q *queryStringQuery) SetLenient(lenient bool) *queryStringQuery {
+	q.Lenient = &lenient
+	return q
+}
+
+func (q *queryStringQuery) SetMinimumShouldMatch(min int) *queryStringQuery {
+	q.MinimumShouldMatch = &min
+	return q
+}
+
+func (q *queryStringQuery) SetPhraseSlop(slop int) *queryStringQuery {
+	q.PhraseSlop = &slop
+	return q
+}
+
+func (q *queryStringQuery) SetTimeZone(timeZone string) *queryStringQuery {
+	q.TimeZone = timeZone
+	return q
+}
+
+func (q *queryStringQuery) SetBoost(boost float64) *queryStringQuery {
+	q.Boost = &boost
+	return q
+}
+
+// QueryString parses query with the Lucene query syntax. Invalid syntax fails
+// the search, so pass user input through EscapeQueryString first or use
+// SimpleQueryString.
+func QueryString(query string) *queryStringQuery {
+	return &queryStringQuery{Query: query}
+}
+
+type SimpleQueryStringFlag string
+
+const (
+	SimpleQueryStringAll        SimpleQueryStringFlag = "ALL"
+	SimpleQueryStringNone       SimpleQueryStringFlag = "NONE"
+	SimpleQueryStringAnd        SimpleQueryStringFlag = "AND"
+	SimpleQueryStringOr         SimpleQueryStringFlag = "OR"
+	SimpleQueryStringNot        SimpleQueryStringFlag = "NOT"
+	SimpleQueryStringPrefix     SimpleQueryStringFlag = "PREFIX"
+	SimpleQueryStringPhrase     SimpleQueryStringFlag = "PHRASE"
+	SimpleQueryStringPrecedence SimpleQueryStringFlag = "PRECEDENCE"
+	SimpleQueryStringEscape     SimpleQueryStringFlag = "ESCAPE"
+	SimpleQueryStringWhitespace SimpleQueryStringFlag = "WHITESPACE"
+	SimpleQueryStringFuzzy      SimpleQueryStringFlag = "FUZZY"
+	SimpleQueryStringNear       SimpleQueryStringFlag = "NEAR"
+	SimpleQueryStringSlop       SimpleQueryStringFlag = "SLOP"
+)
+
+type simpleQueryStringQuery struct {
+	Query                           string   `json:"query"`
+	Fields                          []string `json:"fields,omitempty"`
+	DefaultOperator                 Operator `json:"default_operator,omitempty"`
+	Analyzer                        string   `json:"analyzer,omitempty"`
+	Flags                           string   `json:"flags,omitempty"`
+	Lenient                         *bool    `json:"lenient,omitempty"`
+	AnalyzeWildcard                 *bool    `json:"analyze_wildcard,omitempty"`
+	MinimumShouldMatch              *int     `json:"minimum_should_match,omitempty"`
+	QuoteFieldSuffix                string   `json:"quote_field_suffix,omitempty"`
+	AutoGenerateSynonymsPhraseQuery *bool    `json:"auto_generate_synonyms_phrase_query,omitempty"`
+	Boost                           *float64 `json:"boost,omitempty"`
+}
+
+func (s *simpleQueryStringQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"simple_query_string": *s,
+	})
+}
+
+func (s *simpleQueryStringQuery) Validate() error {
+	if err := validateOperator("simple_query_string", s.DefaultOperator); err != nil {
+		return err
+	}
+	if s.Flags == "" {
+		return nil
+	}
+	for _, flag := range strings.Split(s.Flags, "|") {
+		switch SimpleQueryStringFlag(flag) {
+		case SimpleQueryStringAll, SimpleQueryStringNone, SimpleQueryStringAnd, SimpleQueryStringOr,
+			SimpleQueryStringNot, SimpleQueryStringPrefix, SimpleQueryStringPhrase, SimpleQueryStringPrecedence,
+			SimpleQueryStringEscape, SimpleQueryStringWhitespace, SimpleQueryStringFuzzy, SimpleQueryStringNear,
+			SimpleQueryStringSlop:
+		default:
+			return invalid("simple_query_string", "unknown flag %q", flag)
+		}
+	}
+	return nil
+}
+
+func (s *simpleQueryStringQuery) SetFields(fields ...string) *simpleQueryStringQuery {
+	s.Fields = append(s.Fields, fields...)
+	return s
+}
+
+func (s *simpleQueryStringQuery) SetDefaultOperator(operator Operator) *simpleQueryStringQuery {
+	s.DefaultOperator = operator
+	return s
+}
+
+func (s *simpleQueryStringQuery) SetAnalyzer(analyzer string) *simpleQueryStringQuery {
+	s.Analyzer = analyzer
+	return s
+}
+
+// SetFlags enables only the given operators of the simple query syntax.
+func (s *simpleQueryStringQuery) SetFlags(flags ...SimpleQueryStringFlag) *simpleQueryStringQuery {
+	names := make([]string, 0, len(flags))
+	for _, flag := range flags {
+		names = append(names, string(flag))
+	}
+	s.Flags = strings.Join(names, "|")
+	return s
+}
+
+func (s *simpleQueryStringQuery) SetLenient(lenient bool) *simpleQueryStringQuery {
+	s.Lenient = &lenient
+	return s
+}
+
+func (s *simpleQueryStringQuery) SetAnalyzeWildcard(analyze bool) *simpleQueryStringQuery {
+	s.AnalyzeWildcard = &analyze
+	return s
+}
+
+func (s *simpleQueryStringQuery) SetMinimumShouldMatch(min int) *simpleQueryStringQuery {
+	s.MinimumShouldMatch = &min
+	return s
+}
+
+// SetQuoteFieldSuffix searches quoted text in the fields with this suffix,
+// e.g. ".exact".
+func (s *simpleQueryStringQuery) SetQuoteFieldSuffix(suffix string) *simpleQueryStringQuery {
+	s.QuoteFieldSuffix = suffix
+	return s
+}
+
+func (s *simpleQueryStringQuery) SetAutoGenerateSynonymsPhraseQuery(enabled bool) *simpleQueryStringQuery {
+	s.AutoGenerateSynonymsPhraseQuery = &enabled
+	return s
+}
+
+func (s *simpleQueryStringQuery) SetBoost(boost float64) *simpleQueryStringQuery {
+	s.Boost = &boost
+	return s
+}
+
+// SimpleQueryString parses query with the simple query syntax, which never
+// fails on invalid syntax.
+func SimpleQueryString(query string) *simpleQueryStringQuery {
+	return &simpleQueryStringQuery{Query: query}
+}
+
+// EscapeQueryString makes text match literally in QueryString and
+// SimpleQueryString. It escapes the reserved characters with a backslash,
+// escapes the AND, OR and NOT operators and removes "<" and ">", which cannot
+// be escaped.
+func EscapeQueryString(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	wordStart := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if isQueryStringOperator(text, wordStart, i) {
+			b.WriteByte('\\')
+		}
+		if isQueryStringBoundary(r) {
+			wordStart = i + size
+		}
+		switch r {
+		case '<', '>':
+			i += size
+			continue
+		case '+', '-', '=', '&', '|', '!', '(', ')', '{', '}', '[', ']', '^', '"', '~', '*', '?', ':', '\\', '/':
+			b.WriteByte('\\')
+		}
+		// Copy the bytes rather than r to keep invalid UTF-8 unchanged.
+		b.WriteString(text[i : i+size])
+		i += size
+	}
+	return b.String()
+}
+
+// isQueryStringOperator reports whether an AND, OR or NOT operator, that is a
+// whole word, starts at i.
+func isQueryStringOperator(text string, wordStart, i int) bool {
+	if i != wordStart {
+		return false
+	}
+	for _, operator := range []string{"AND", "OR", "NOT"} {
+		if !strings.HasPrefix(text[i:], operator) {
+			continue
+		}
+		end := i + len(operator)
+		if end == len(text) {
+			return true
+		}
+		if r, _ := utf8.DecodeRuneInString(text[end:]); isQueryStringBoundary(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isQueryStringBoundary reports whether r separates words in the query
+// syntax, that is any Unicode whitespace, such as the ideographic space, or a
+// reserved character.
+func isQueryStringBoundary(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("<>+-=&|!(){}[]^\"~*?:\\/", r)
+}
@@ -0,0 +1,136 @@
+package esquery_test
+
+import (
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryStringQuery(t *testing.T) {
+	expected := `{
+		"query_string": {
+			"query": "title:(シャツ OR ブラウス) AND price.priceMajor:[1000 TO 5000]",
+			"fields": ["title^2", "description"],
+			"default_operator": "and",
+			"analyzer": "kuromoji",
+			"lenient": true,
+			"time_zone": "Asia/Tokyo"
+		}
+	}`
+
+	actual := esquery.QueryString("title:(シャツ OR ブラウス) AND price.priceMajor:[1000 TO 5000]").
+		SetFields("title^2", "description").
+		SetDefaultOperator(esquery.OperatorAnd).
+		SetAnalyzer("kuromoji").
+		SetLenient(true).
+		SetTimeZone("Asia/Tokyo")
+
+	jsonData, err := json.Marshal(actual)
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, expected, string(jsonData))
+}
+
+func TestSimpleQueryStringQuery(t *testing.T) {
+	expected := `{
+		"simple_query_string": {
+			"query": "\"cotton shirt\" +blue -used",
+			"fields": ["title", "description"],
+			"default_operator": "or",
+			"flags": "PHRASE|AND|NOT",
+			"lenient": false,
+			"quote_field_suffix": ".exact"
+		}
+	}`
+
+	actual := esquery.SimpleQueryString(`"cotton shirt" +blue -used`).
+		SetFields("title", "description").
+		SetDefaultOperator(esquery.OperatorOr).
+		SetFlags(esquery.SimpleQueryStringPhrase, esquery.SimpleQueryStringAnd, esquery.SimpleQueryStringNot).
+		SetLenient(false).
+		SetQuoteFieldSuffix(".exact")
+
+	jsonData, err := json.Marshal(actual)
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, expected, string(jsonData))
+}
+
+func TestEscapeQueryString(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "シャツ", expected: "シャツ"},
+		{text: "T-shirt (L)", expected: `T\-shirt \(L\)`},
+		{text: "price:100", expected: `price\:100`},
+		{text: `a\b/c`, expected: `a\\b\/c`},
+		{text: "a && b || !c", expected: `a \&\& b \|\| \!c`},
+		{text: "<script>", expected: "script"},
+		{text: "blue AND red", expected: `blue \AND red`},
+		{text: "NOT used", expected: `\NOT used`},
+		{text: "(OR)", expected: `\(\OR\)`},
+		{text: "ANDROID ORANGE", expected: "ANDROID ORANGE"},
+		{text: "シャツ\u3000AND\u3000ブラウス", expected: "シャツ\u3000\\AND\u3000ブラウス"},
+		{text: "a\u3000NOT\u3000b", expected: "a\u3000\\NOT\u3000b"},
+		{text: "OR\u3000", expected: "\\OR\u3000"},
+		{text: "*?~^\"", expected: `\*\?\~\^\"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			assert.Equal(t, test.expected, esquery.EscapeQueryString(test.text))
+		})
+	}
+}
+
+// unescapeQueryString removes the escaping backslashes.
+func unescapeQueryString(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func FuzzEscapeQueryString(f *testing.F) {
+	for _, seed := range []string{"", "シャツ", "a && b", `\`, "<>", "AND", "x OR y", "title:(a b)", "a\u3000AND\u3000b", "\xff\xfe"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		escaped := esquery.EscapeQueryString(text)
+
+		removed := strings.NewReplacer("<", "", ">", "").Replace(text)
+		if got := unescapeQueryString(escaped); got != removed {
+			t.Fatalf("unescape(%q) = %q, want %q", escaped, got, removed)
+		}
+
+		for i := 0; i < len(escaped); i++ {
+			c := escaped[i]
+			if c == '\\' {
+				i++
+				continue
+			}
+			if strings.IndexByte(`<>+-=&|!(){}[]^"~*?:/`, c) >= 0 {
+				t.Fatalf("unescaped %q at %d in %q", c, i, escaped)
+			}
+		}
+
+		for _, word := range strings.Fields(escaped) {
+			if word == "AND" || word == "OR" || word == "NOT" {
+				t.Fatalf("unescaped operator %q in %q", word, escaped)
+			}
+		}
+
+		if _, err := json.Marshal(esquery.QueryString(escaped)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
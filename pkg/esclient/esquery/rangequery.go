@@ -12,6 +12,7 @@ type rangeQuery struct {
 	Format   string      `json:"format,omitempty"`
 	TimeZone string      `json:"time_zone,omitempty"`
 	Relation Relation    `json:"relation,omitempty"`
+	Name     string      `json:"_name,omitempty"`
 }
 
 type Relation string
@@ -20,6 +21,7 @@ const (
 	INTERSECTS Relation = "INTERSECTS"
 	CONTAINS   Relation = "CONTAINS"
 	WITHIN     Relation = "WITHIN"
+	DISJOINT   Relation = "DISJOINT"
 )
 
 func (r *rangeQuery) MarshalJSON() ([]byte, error) {
@@ -30,6 +32,26 @@ func (r *rangeQuery) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (r *rangeQuery) Validate() error {
+	if err := validateField("range", r.Field); err != nil {
+		return err
+	}
+	if r.Gt == nil && r.Gte == nil && r.Lt == nil && r.Lte == nil {
+		return invalid("range", "no bounds on %s", r.Field)
+	}
+	if r.Gt != nil && r.Gte != nil {
+		return invalid("range", "both gt and gte on %s", r.Field)
+	}
+	if r.Lt != nil && r.Lte != nil {
+		return invalid("range", "both lt and lte on %s", r.Field)
+	}
+	switch r.Relation {
+	case "", INTERSECTS, CONTAINS, WITHIN:
+		return nil
+	}
+	return invalid("range", "unknown relation %q", r.Relation)
+}
+
 func (r *rangeQuery) SetGt(gt interface{}) *rangeQuery {
 	r.Gt = gt
 	return r
@@ -70,6 +92,11 @@ func (r *rangeQuery) SetRelation(relation Relation) *rangeQuery {
 	return r
 }
 
+func (r *rangeQuery) SetName(name string) *rangeQuery {
+	r.Name = name
+	return r
+}
+
 func Range(field string) *rangeQuery {
 	return &rangeQuery{Field: field}
 }
@@ -0,0 +1,46 @@
+package esquery
+
+// Script is an inline or stored script, used by script queries, scripted
+// updates and scoring functions.
+type Script struct {
+	Source string                 `json:"source,omitempty"`
+	Id     string                 `json:"id,omitempty"`
+	Lang   string                 `json:"lang,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// NewScript creates an inline script, painless by default.
+func NewScript(source string) *Script {
+	return &Script{Source: source}
+}
+
+// StoredScript references a script stored with the put stored script API.
+func StoredScript(id string) *Script {
+	return &Script{Id: id}
+}
+
+func (s *Script) SetLang(lang string) *Script {
+	s.Lang = lang
+	return s
+}
+
+func (s *Script) SetParam(name string, value interface{}) *Script {
+	if s.Params == nil {
+		s.Params = make(map[string]interface{})
+	}
+	s.Params[name] = value
+	return s
+}
+
+func (s *Script) SetParams(params map[string]interface{}) *Script {
+	s.Params = params
+	return s
+}
+
+// Validate checks that the script is either inline or stored.
+func (s *Script) Validate() error {
+	if (s.Source == "") == (s.Id == "") {
+		return invalid("script", "needs either a source or an id")
+	}
+	return nil
+}
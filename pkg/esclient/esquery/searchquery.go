@@ -1,6 +1,12 @@
 package esquery
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
 
 type KeyVal map[string]interface{}
 
@@ -8,15 +14,152 @@ type QueryType interface {
 	json.Marshaler
 }
 
+// SearchQuery is the body of a search. A Size of 0 is left out, so that
+// elasticsearch returns 10 hits, unless it is set with
+// SearchQueryBuilder.SetSize to only return aggregations or the total.
 type SearchQuery struct {
-	Size  uint32    `json:"size,omitempty"`
-	Query QueryType `json:"query,omitempty"`
-	From  uint32    `json:"from,omitempty"`
-	Sort  []*sort   `json:"sort,omitempty"`
+	Size           uint32                 `json:"size,omitempty"`
+	Query          QueryType              `json:"query,omitempty"`
+	From           uint32                 `json:"from,omitempty"`
+	Sort           []*sort                `json:"sort,omitempty"`
+	SearchAfter    []interface{}          `json:"search_after,omitempty"`
+	Pit            *PointInTime           `json:"pit,omitempty"`
+	Source         *SourceFilter          `json:"_source,omitempty"`
+	StoredFields   []string               `json:"stored_fields,omitempty"`
+	DocvalueFields []*DocvalueField       `json:"docvalue_fields,omitempty"`
+	TrackTotalHits interface{}            `json:"track_total_hits,omitempty"` // bool or the number of hits to count accurately
+	Timeout        string                 `json:"timeout,omitempty"`
+	TerminateAfter uint32                 `json:"terminate_after,omitempty"`
+	MinScore       *float64               `json:"min_score,omitempty"`
+	Aggs           map[string]Aggregation `json:"aggs,omitempty"`
+	Fields         []string               `json:"fields,omitempty"`
+	Highlight      *Highlight             `json:"highlight,omitempty"`
+	Suggest        map[string]Suggester   `json:"suggest,omitempty"`
+	Collapse       *Collapse              `json:"collapse,omitempty"`
+	Explain        bool                   `json:"explain,omitempty"`
+	Preference     string                 `json:"-"` // sent as a url parameter
+	Routing        string                 `json:"-"` // sent as a url parameter
+	sizeSet        bool
+}
+
+// UrlParams returns the options of the search that are not part of the request body.
+func (s *SearchQuery) UrlParams() url.Values {
+	q := url.Values{}
+	if s.Preference != "" {
+		q.Set("preference", s.Preference)
+	}
+	if s.Routing != "" {
+		q.Set("routing", s.Routing)
+	}
+	return q
+}
+
+// SourceFilter selects the fields of the _source returned with each hit.
+type SourceFilter struct {
+	Includes []string `json:"includes,omitempty"`
+	Excludes []string `json:"excludes,omitempty"`
+	disabled bool
+}
+
+// NoSource disables the _source in the response.
+func NoSource() *SourceFilter {
+	return &SourceFilter{disabled: true}
+}
+
+func (s *SourceFilter) MarshalJSON() ([]byte, error) {
+	if s.disabled {
+		return []byte("false"), nil
+	}
+	type filter SourceFilter
+	return json.Marshal((*filter)(s))
+}
+
+// UnmarshalJSON accepts false, a field, a list of fields or the includes and
+// excludes object.
+func (s *SourceFilter) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		*s = SourceFilter{disabled: !enabled}
+		return nil
+	}
+	var field string
+	if err := json.Unmarshal(data, &field); err == nil {
+		*s = SourceFilter{Includes: []string{field}}
+		return nil
+	}
+	var fields []string
+	if err := json.Unmarshal(data, &fields); err == nil {
+		*s = SourceFilter{Includes: fields}
+		return nil
+	}
+	type filter SourceFilter
+	*s = SourceFilter{}
+	return decodeStrict(data, (*filter)(s))
+}
+
+type DocvalueField struct {
+	Field  string `json:"field"`
+	Format string `json:"format,omitempty"`
+}
+
+func DocvalueFieldWithFormat(field, format string) *DocvalueField {
+	return &DocvalueField{Field: field, Format: format}
+}
+
+type PointInTime struct {
+	Id        string `json:"id"`
+	KeepAlive string `json:"keep_alive,omitempty"`
 }
 
 func (s *SearchQuery) MarshalJSON() ([]byte, error) {
-	return json.Marshal(*s)
+	type query SearchQuery
+	var size *uint32
+	if s.Size > 0 || s.sizeSet {
+		size = &s.Size
+	}
+	return json.Marshal(struct {
+		Size *uint32 `json:"size,omitempty"`
+		*query
+	}{size, (*query)(s)})
+}
+
+// Validate checks the query, aggregations and search options.
+func (s *SearchQuery) Validate() error {
+	if s.Query != nil {
+		if err := Validate(s.Query); err != nil {
+			return err
+		}
+	}
+	if err := validateSort("search", s.Sort); err != nil {
+		return err
+	}
+	if len(s.SearchAfter) > 0 && len(s.Sort) == 0 {
+		return invalid("search", "search_after needs a sort")
+	}
+	if len(s.SearchAfter) > 0 && s.From > 0 {
+		return invalid("search", "search_after cannot be used with from")
+	}
+	if s.Pit != nil && s.Pit.Id == "" {
+		return invalid("search", "point in time id is empty")
+	}
+	if err := validateAggregations(s.Aggs); err != nil {
+		return err
+	}
+	if err := Validate(s.Highlight); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Suggest)) {
+		if isNil(s.Suggest[name]) {
+			return invalid("search", "suggester %q is missing", name)
+		}
+		if err := Validate(s.Suggest[name]); err != nil {
+			return err
+		}
+	}
+	if s.Collapse != nil && len(s.SearchAfter) > 0 {
+		return invalid("search", "collapse cannot be used with search_after")
+	}
+	return Validate(s.Collapse)
 }
 
 type sort struct {
@@ -36,6 +179,27 @@ func (s *sort) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// UnmarshalJSON accepts {"field": {"order": "asc"}} and {"field": "asc"}.
+func (s *sort) UnmarshalJSON(data []byte) error {
+	field, body, err := fieldQuery(data)
+	if err != nil {
+		return err
+	}
+	var options struct {
+		Order Order `json:"order"`
+	}
+	if isObject(body) {
+		err = decodeStrict(body, &options)
+	} else {
+		err = decodeStrict(body, &options.Order)
+	}
+	if err != nil {
+		return err
+	}
+	*s = sort{Field: field, Order: options.Order}
+	return nil
+}
+
 type Order string
 
 const (
@@ -53,6 +217,7 @@ func NewSearchQueryBuilder() *SearchQueryBuilder {
 
 func (s *SearchQueryBuilder) SetSize(size uint32) *SearchQueryBuilder {
 	s.searchQuery.Size = size
+	s.searchQuery.sizeSet = true
 	return s
 }
 
@@ -71,6 +236,129 @@ func (s *SearchQueryBuilder) SetSort(sort ...*sort) *SearchQueryBuilder {
 	return s
 }
 
+// SetSearchAfter continues the search after the sort values of the last hit of the previous page.
+func (s *SearchQueryBuilder) SetSearchAfter(values ...interface{}) *SearchQueryBuilder {
+	s.searchQuery.SearchAfter = values
+	return s
+}
+
+func (s *SearchQueryBuilder) SetPointInTime(id string, keepAlive string) *SearchQueryBuilder {
+	s.searchQuery.Pit = &PointInTime{Id: id, KeepAlive: keepAlive}
+	return s
+}
+
+func (s *SearchQueryBuilder) SetSourceIncludes(fields ...string) *SearchQueryBuilder {
+	if s.searchQuery.Source == nil || s.searchQuery.Source.disabled {
+		s.searchQuery.Source = &SourceFilter{}
+	}
+	s.searchQuery.Source.Includes = append(s.searchQuery.Source.Includes, fields...)
+	return s
+}
+
+func (s *SearchQueryBuilder) SetSourceExcludes(fields ...string) *SearchQueryBuilder {
+	if s.searchQuery.Source == nil || s.searchQuery.Source.disabled {
+		s.searchQuery.Source = &SourceFilter{}
+	}
+	s.searchQuery.Source.Excludes = append(s.searchQuery.Source.Excludes, fields...)
+	return s
+}
+
+func (s *SearchQueryBuilder) DisableSource() *SearchQueryBuilder {
+	s.searchQuery.Source = NoSource()
+	return s
+}
+
+func (s *SearchQueryBuilder) SetStoredFields(fields ...string) *SearchQueryBuilder {
+	s.searchQuery.StoredFields = append(s.searchQuery.StoredFields, fields...)
+	return s
+}
+
+func (s *SearchQueryBuilder) SetDocvalueFields(fields ...string) *SearchQueryBuilder {
+	for _, field := range fields {
+		s.searchQuery.DocvalueFields = append(s.searchQuery.DocvalueFields, &DocvalueField{Field: field})
+	}
+	return s
+}
+
+func (s *SearchQueryBuilder) SetDocvalueFieldsWithFormat(fields ...*DocvalueField) *SearchQueryBuilder {
+	s.searchQuery.DocvalueFields = append(s.searchQuery.DocvalueFields, fields...)
+	return s
+}
+
+func (s *SearchQueryBuilder) SetTrackTotalHits(track bool) *SearchQueryBuilder {
+	s.searchQuery.TrackTotalHits = track
+	return s
+}
+
+// SetTrackTotalHitsUpTo counts the total hits accurately up to the given number.
+func (s *SearchQueryBuilder) SetTrackTotalHitsUpTo(upTo int) *SearchQueryBuilder {
+	s.searchQuery.TrackTotalHits = upTo
+	return s
+}
+
+// SetTimeout sets the time to wait for each shard, e.g. "500ms".
+func (s *SearchQueryBuilder) SetTimeout(timeout string) *SearchQueryBuilder {
+	s.searchQuery.Timeout = timeout
+	return s
+}
+
+func (s *SearchQueryBuilder) SetTerminateAfter(terminateAfter uint32) *SearchQueryBuilder {
+	s.searchQuery.TerminateAfter = terminateAfter
+	return s
+}
+
+func (s *SearchQueryBuilder) SetMinScore(minScore float64) *SearchQueryBuilder {
+	s.searchQuery.MinScore = &minScore
+	return s
+}
+
+// SetPreference selects the shard copies to search, e.g. "_local" or a session id.
+func (s *SearchQueryBuilder) SetPreference(preference string) *SearchQueryBuilder {
+	s.searchQuery.Preference = preference
+	return s
+}
+
+func (s *SearchQueryBuilder) SetRouting(routing ...string) *SearchQueryBuilder {
+	s.searchQuery.Routing = strings.Join(routing, ",")
+	return s
+}
+
+func (s *SearchQueryBuilder) SetAggregation(name string, agg Aggregation) *SearchQueryBuilder {
+	s.searchQuery.Aggs = addAggregation(s.searchQuery.Aggs, name, agg)
+	return s
+}
+
+// SetFields returns the values of the fields, as indexed, with each hit.
+// Wildcards such as "price.*" are allowed.
+func (s *SearchQueryBuilder) SetFields(fields ...string) *SearchQueryBuilder {
+	s.searchQuery.Fields = append(s.searchQuery.Fields, fields...)
+	return s
+}
+
+func (s *SearchQueryBuilder) SetHighlight(highlight *Highlight) *SearchQueryBuilder {
+	s.searchQuery.Highlight = highlight
+	return s
+}
+
+func (s *SearchQueryBuilder) SetSuggester(name string, suggester Suggester) *SearchQueryBuilder {
+	if s.searchQuery.Suggest == nil {
+		s.searchQuery.Suggest = make(map[string]Suggester)
+	}
+	s.searchQuery.Suggest[name] = suggester
+	return s
+}
+
+func (s *SearchQueryBuilder) SetCollapse(collapse *Collapse) *SearchQueryBuilder {
+	s.searchQuery.Collapse = collapse
+	return s
+}
+
+// SetExplain returns how the score of each hit was computed.
+func (s *SearchQueryBuilder) SetExplain(explain bool) *SearchQueryBuilder {
+	s.searchQuery.Explain = explain
+	return s
+}
+
 func (s *SearchQueryBuilder) Build() *SearchQuery {
 	return s.searchQuery
 }
@@ -81,6 +81,73 @@ func TestSearchQuery(t *testing.T) {
 				).
 				Build(),
 		},
+		{
+			expected: `{
+				"size": 1000,
+				"query": {"match_all": {}},
+				"sort": [
+					{"record.Updated": {"order": "desc"}},
+					{"_shard_doc": {"order": "asc"}}
+				],
+				"search_after": ["2024-01-01T00:00:00Z", 42],
+				"pit": {"id": "46ToAwMDaWR5BXV1aWQy", "keep_alive": "1m"}
+			}`,
+			actual: esquery.NewSearchQueryBuilder().
+				SetSize(1000).
+				SetQuery(esquery.MatchAll()).
+				SetSort(
+					esquery.Sort("record.Updated", esquery.OrderDesc),
+					esquery.Sort("_shard_doc", esquery.OrderAsc),
+				).
+				SetSearchAfter("2024-01-01T00:00:00Z", 42).
+				SetPointInTime("46ToAwMDaWR5BXV1aWQy", "1m").
+				Build(),
+		},
+		{
+			expected: `{
+				"size": 20,
+				"query": {"match": {"title": {"query": "シャツ"}}},
+				"search_after": [1.5, "sku-42"],
+				"_source": {"includes": ["sku", "title"], "excludes": ["description"]},
+				"stored_fields": ["_id"],
+				"docvalue_fields": [
+					{"field": "price.priceMajor"},
+					{"field": "record.Updated", "format": "epoch_millis"}
+				],
+				"track_total_hits": 10000,
+				"timeout": "500ms",
+				"terminate_after": 100,
+				"min_score": 0.5
+			}`,
+			actual: esquery.NewSearchQueryBuilder().
+				SetSize(20).
+				SetQuery(esquery.Match("title", "シャツ")).
+				SetSearchAfter(1.5, "sku-42").
+				SetSourceIncludes("sku", "title").
+				SetSourceExcludes("description").
+				SetStoredFields("_id").
+				SetDocvalueFields("price.priceMajor").
+				SetDocvalueFieldsWithFormat(esquery.DocvalueFieldWithFormat("record.Updated", "epoch_millis")).
+				SetTrackTotalHitsUpTo(10000).
+				SetTimeout("500ms").
+				SetTerminateAfter(100).
+				SetMinScore(0.5).
+				SetPreference("_local").
+				SetRouting("shop1", "shop2").
+				Build(),
+		},
+		{
+			expected: `{
+				"query": {"match_all": {}},
+				"_source": false,
+				"track_total_hits": false
+			}`,
+			actual: esquery.NewSearchQueryBuilder().
+				SetQuery(esquery.MatchAll()).
+				DisableSource().
+				SetTrackTotalHits(false).
+				Build(),
+		},
 	}
 
 	for _, test := range tests {
@@ -90,3 +157,36 @@ func TestSearchQuery(t *testing.T) {
 		assert.JSONEq(t, test.expected, string(jsonData))
 	}
 }
+
+func TestSearchQueryUrlParams(t *testing.T) {
+	query := esquery.NewSearchQueryBuilder().
+		SetQuery(esquery.MatchAll()).
+		SetPreference("_local").
+		SetRouting("shop1", "shop2").
+		Build()
+
+	assert.Equal(t, "preference=_local&routing=shop1%2Cshop2", query.UrlParams().Encode())
+	assert.Empty(t, esquery.NewSearchQueryBuilder().Build().UrlParams())
+}
+
+func TestSearchQuerySize(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    *esquery.SearchQuery
+		expected string
+	}{
+		{name: "struct size", query: &esquery.SearchQuery{Size: 20}, expected: `{"size":20}`},
+		{name: "no size", query: &esquery.SearchQuery{}, expected: `{}`},
+		{name: "set size", query: esquery.NewSearchQueryBuilder().SetSize(5).Build(), expected: `{"size":5}`},
+		{name: "set size 0", query: esquery.NewSearchQueryBuilder().SetSize(0).Build(), expected: `{"size":0}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jsonData, err := test.query.MarshalJSON()
+			assert.Nil(t, err)
+
+			assert.JSONEq(t, test.expected, string(jsonData))
+		})
+	}
+}
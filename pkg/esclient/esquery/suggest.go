@@ -0,0 +1,251 @@
+package esquery
+
+import "encoding/json"
+
+// Suggester is a term, phrase or completion suggester of a search.
+type Suggester interface {
+	json.Marshaler
+}
+
+type SuggestMode string
+
+const (
+	SuggestModeMissing SuggestMode = "missing"
+	SuggestModePopular SuggestMode = "popular"
+	SuggestModeAlways  SuggestMode = "always"
+)
+
+type termSuggester struct {
+	text          string
+	Field         string      `json:"field"`
+	Analyzer      string      `json:"analyzer,omitempty"`
+	Size          *int        `json:"size,omitempty"`
+	SuggestMode   SuggestMode `json:"suggest_mode,omitempty"`
+	Sort          string      `json:"sort,omitempty"`
+	MaxEdits      *int        `json:"max_edits,omitempty"`
+	PrefixLength  *int        `json:"prefix_length,omitempty"`
+	MinWordLength *int        `json:"min_word_length,omitempty"`
+}
+
+func (t *termSuggester) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"text": t.text,
+		"term": *t,
+	})
+}
+
+func (t *termSuggester) Validate() error {
+	if err := validateField("term suggester", t.Field); err != nil {
+		return err
+	}
+	if t.MaxEdits != nil && (*t.MaxEdits < 1 || *t.MaxEdits > 2) {
+		return invalid("term suggester", "max_edits must be 1 or 2")
+	}
+	return validateSuggestMode("term suggester", t.SuggestMode)
+}
+
+func (t *termSuggester) SetAnalyzer(analyzer string) *termSuggester {
+	t.Analyzer = analyzer
+	return t
+}
+
+func (t *termSuggester) SetSize(size int) *termSuggester {
+	t.Size = &size
+	return t
+}
+
+func (t *termSuggester) SetSuggestMode(mode SuggestMode) *termSuggester {
+	t.SuggestMode = mode
+	return t
+}
+
+// SetSortByFrequency sorts the suggestions by document frequency instead of score.
+func (t *termSuggester) SetSortByFrequency() *termSuggester {
+	t.Sort = "frequency"
+	return t
+}
+
+func (t *termSuggester) SetMaxEdits(maxEdits int) *termSuggester {
+	t.MaxEdits = &maxEdits
+	return t
+}
+
+func (t *termSuggester) SetPrefixLength(prefixLength int) *termSuggester {
+	t.PrefixLength = &prefixLength
+	return t
+}
+
+func (t *termSuggester) SetMinWordLength(minWordLength int) *termSuggester {
+	t.MinWordLength = &minWordLength
+	return t
+}
+
+// TermSuggester suggests corrections for each term of text.
+func TermSuggester(text, field string) *termSuggester {
+	return &termSuggester{
+		text:  text,
+		Field: field,
+	}
+}
+
+type DirectGenerator struct {
+	Field         string      `json:"field"`
+	SuggestMode   SuggestMode `json:"suggest_mode,omitempty"`
+	MinWordLength *int        `json:"min_word_length,omitempty"`
+	PreFilter     string      `json:"pre_filter,omitempty"`
+	PostFilter    string      `json:"post_filter,omitempty"`
+}
+
+type phraseSuggestHighlight struct {
+	PreTag  string `json:"pre_tag"`
+	PostTag string `json:"post_tag"`
+}
+
+type phraseSuggester struct {
+	text                    string
+	Field                   string                  `json:"field"`
+	Analyzer                string                  `json:"analyzer,omitempty"`
+	Size                    *int                    `json:"size,omitempty"`
+	GramSize                *int                    `json:"gram_size,omitempty"`
+	Confidence              *float64                `json:"confidence,omitempty"`
+	MaxErrors               *float64                `json:"max_errors,omitempty"`
+	RealWordErrorLikelihood *float64                `json:"real_word_error_likelihood,omitempty"`
+	DirectGenerators        []*DirectGenerator      `json:"direct_generator,omitempty"`
+	Highlight               *phraseSuggestHighlight `json:"highlight,omitempty"`
+}
+
+func (p *phraseSuggester) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"text":   p.text,
+		"phrase": *p,
+	})
+}
+
+func (p *phraseSuggester) Validate() error {
+	if err := validateField("phrase suggester", p.Field); err != nil {
+		return err
+	}
+	for _, generator := range p.DirectGenerators {
+		if err := validateField("phrase suggester direct_generator", generator.Field); err != nil {
+			return err
+		}
+		if err := validateSuggestMode("phrase suggester direct_generator", generator.SuggestMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *phraseSuggester) SetAnalyzer(analyzer string) *phraseSuggester {
+	p.Analyzer = analyzer
+	return p
+}
+
+func (p *phraseSuggester) SetSize(size int) *phraseSuggester {
+	p.Size = &size
+	return p
+}
+
+// SetGramSize sets the shingle size of the field, e.g. 3 for a trigram field.
+func (p *phraseSuggester) SetGramSize(gramSize int) *phraseSuggester {
+	p.GramSize = &gramSize
+	return p
+}
+
+func (p *phraseSuggester) SetConfidence(confidence float64) *phraseSuggester {
+	p.Confidence = &confidence
+	return p
+}
+
+// SetMaxErrors sets the maximum number of misspelled terms, or a fraction of
+// the terms when below 1.
+func (p *phraseSuggester) SetMaxErrors(maxErrors float64) *phraseSuggester {
+	p.MaxErrors = &maxErrors
+	return p
+}
+
+func (p *phraseSuggester) SetRealWordErrorLikelihood(likelihood float64) *phraseSuggester {
+	p.RealWordErrorLikelihood = &likelihood
+	return p
+}
+
+func (p *phraseSuggester) AddDirectGenerator(generators ...*DirectGenerator) *phraseSuggester {
+	p.DirectGenerators = append(p.DirectGenerators, generators...)
+	return p
+}
+
+// SetHighlight wraps the corrected terms of the suggestions in the tags.
+func (p *phraseSuggester) SetHighlight(preTag, postTag string) *phraseSuggester {
+	p.Highlight = &phraseSuggestHighlight{PreTag: preTag, PostTag: postTag}
+	return p
+}
+
+// PhraseSuggester suggests corrections for the whole of text, "did you mean".
+func PhraseSuggester(text, field string) *phraseSuggester {
+	return &phraseSuggester{
+		text:  text,
+		Field: field,
+	}
+}
+
+type completionFuzzy struct {
+	Fuzziness string `json:"fuzziness,omitempty"`
+}
+
+type completionSuggester struct {
+	prefix         string
+	Field          string                 `json:"field"`
+	Size           *int                   `json:"size,omitempty"`
+	SkipDuplicates *bool                  `json:"skip_duplicates,omitempty"`
+	Fuzzy          *completionFuzzy       `json:"fuzzy,omitempty"`
+	Contexts       map[string]interface{} `json:"contexts,omitempty"`
+}
+
+func (c *completionSuggester) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"prefix":     c.prefix,
+		"completion": *c,
+	})
+}
+
+func (c *completionSuggester) Validate() error {
+	if err := validateField("completion suggester", c.Field); err != nil {
+		return err
+	}
+	if c.Fuzzy != nil {
+		return validateFuzziness("completion suggester", c.Fuzzy.Fuzziness)
+	}
+	return nil
+}
+
+func (c *completionSuggester) SetSize(size int) *completionSuggester {
+	c.Size = &size
+	return c
+}
+
+func (c *completionSuggester) SetSkipDuplicates(skip bool) *completionSuggester {
+	c.SkipDuplicates = &skip
+	return c
+}
+
+func (c *completionSuggester) SetFuzziness(fuzziness string) *completionSuggester {
+	c.Fuzzy = &completionFuzzy{Fuzziness: fuzziness}
+	return c
+}
+
+// SetContext filters the suggestions by a category or geo context of the field.
+func (c *completionSuggester) SetContext(name string, values interface{}) *completionSuggester {
+	if c.Contexts == nil {
+		c.Contexts = make(map[string]interface{})
+	}
+	c.Contexts[name] = values
+	return c
+}
+
+// CompletionSuggester suggests values of a completion field starting with prefix.
+func CompletionSuggester(prefix, field string) *completionSuggester {
+	return &completionSuggester{
+		prefix: prefix,
+		Field:  field,
+	}
+}
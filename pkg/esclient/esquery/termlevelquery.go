@@ -0,0 +1,298 @@
+package esquery
+
+import "encoding/json"
+
+type idsQuery struct {
+	Values []string `json:"values"`
+	Boost  *float32 `json:"boost,omitempty"`
+}
+
+func (i *idsQuery) MarshalJSON() ([]byte, error) {
+	type ids idsQuery
+	body := ids(*i)
+	if body.Values == nil {
+		body.Values = []string{}
+	}
+	return json.Marshal(KeyVal{
+		"ids": body,
+	})
+}
+
+func (i *idsQuery) Validate() error {
+	for _, id := range i.Values {
+		if id == "" {
+			return invalid("ids", "id is empty")
+		}
+	}
+	return nil
+}
+
+func (i *idsQuery) SetValues(ids ...string) *idsQuery {
+	i.Values = append(i.Values, ids...)
+	return i
+}
+
+func (i *idsQuery) SetBoost(boost float32) *idsQuery {
+	i.Boost = &boost
+	return i
+}
+
+// Ids matches documents by _id. Unlike a bool of term queries it counts as a
+// single clause towards indices.query.bool.max_clause_count.
+func Ids(ids ...string) *idsQuery {
+	return &idsQuery{Values: ids}
+}
+
+type existsQuery struct {
+	Field string   `json:"field"`
+	Boost *float32 `json:"boost,omitempty"`
+}
+
+func (e *existsQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"exists": *e,
+	})
+}
+
+func (e *existsQuery) Validate() error {
+	return validateField("exists", e.Field)
+}
+
+func (e *existsQuery) SetBoost(boost float32) *existsQuery {
+	e.Boost = &boost
+	return e
+}
+
+// Exists matches documents with an indexed value for field.
+func Exists(field string) *existsQuery {
+	return &existsQuery{Field: field}
+}
+
+type prefixQuery struct {
+	Field           string   `json:"-"`
+	Value           string   `json:"value"`
+	Rewrite         string   `json:"rewrite,omitempty"`
+	CaseInsensitive *bool    `json:"case_insensitive,omitempty"`
+	Boost           *float32 `json:"boost,omitempty"`
+}
+
+func (p *prefixQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"prefix": KeyVal{
+			p.Field: *p,
+		},
+	})
+}
+
+func (p *prefixQuery) Validate() error {
+	if err := validateField("prefix", p.Field); err != nil {
+		return err
+	}
+	if p.Value == "" {
+		return invalid("prefix", "value of %s is empty", p.Field)
+	}
+	return nil
+}
+
+func (p *prefixQuery) SetRewrite(rewrite string) *prefixQuery {
+	p.Rewrite = rewrite
+	return p
+}
+
+func (p *prefixQuery) SetCaseInsensitive(caseInsensitive bool) *prefixQuery {
+	p.CaseInsensitive = &caseInsensitive
+	return p
+}
+
+func (p *prefixQuery) SetBoost(boost float32) *prefixQuery {
+	p.Boost = &boost
+	return p
+}
+
+func Prefix(field, value string) *prefixQuery {
+	return &prefixQuery{
+		Field: field,
+		Value: value,
+	}
+}
+
+type wildcardQuery struct {
+	Field           string   `json:"-"`
+	Value           string   `json:"value"`
+	Rewrite         string   `json:"rewrite,omitempty"`
+	CaseInsensitive *bool    `json:"case_insensitive,omitempty"`
+	Boost           *float32 `json:"boost,omitempty"`
+}
+
+func (w *wildcardQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"wildcard": KeyVal{
+			w.Field: *w,
+		},
+	})
+}
+
+func (w *wildcardQuery) Validate() error {
+	if err := validateField("wildcard", w.Field); err != nil {
+		return err
+	}
+	if w.Value == "" {
+		return invalid("wildcard", "value of %s is empty", w.Field)
+	}
+	return nil
+}
+
+func (w *wildcardQuery) SetRewrite(rewrite string) *wildcardQuery {
+	w.Rewrite = rewrite
+	return w
+}
+
+func (w *wildcardQuery) SetCaseInsensitive(caseInsensitive bool) *wildcardQuery {
+	w.CaseInsensitive = &caseInsensitive
+	return w
+}
+
+func (w *wildcardQuery) SetBoost(boost float32) *wildcardQuery {
+	w.Boost = &boost
+	return w
+}
+
+// Wildcard matches value as a pattern where "?" matches one character and
+// "*" matches zero or more.
+func Wildcard(field, value string) *wildcardQuery {
+	return &wildcardQuery{
+		Field: field,
+		Value: value,
+	}
+}
+
+type regexpQuery struct {
+	Field                 string   `json:"-"`
+	Value                 string   `json:"value"`
+	Flags                 string   `json:"flags,omitempty"`
+	MaxDeterminizedStates *int     `json:"max_determinized_states,omitempty"`
+	Rewrite               string   `json:"rewrite,omitempty"`
+	CaseInsensitive       *bool    `json:"case_insensitive,omitempty"`
+	Boost                 *float32 `json:"boost,omitempty"`
+}
+
+func (r *regexpQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"regexp": KeyVal{
+			r.Field: *r,
+		},
+	})
+}
+
+func (r *regexpQuery) Validate() error {
+	if err := validateField("regexp", r.Field); err != nil {
+		return err
+	}
+	if r.Value == "" {
+		return invalid("regexp", "value of %s is empty", r.Field)
+	}
+	return nil
+}
+
+// SetFlags enables optional operators, e.g. "ALL" or "COMPLEMENT|INTERVAL".
+func (r *regexpQuery) SetFlags(flags string) *regexpQuery {
+	r.Flags = flags
+	return r
+}
+
+func (r *regexpQuery) SetMaxDeterminizedStates(maxStates int) *regexpQuery {
+	r.MaxDeterminizedStates = &maxStates
+	return r
+}
+
+func (r *regexpQuery) SetRewrite(rewrite string) *regexpQuery {
+	r.Rewrite = rewrite
+	return r
+}
+
+func (r *regexpQuery) SetCaseInsensitive(caseInsensitive bool) *regexpQuery {
+	r.CaseInsensitive = &caseInsensitive
+	return r
+}
+
+func (r *regexpQuery) SetBoost(boost float32) *regexpQuery {
+	r.Boost = &boost
+	return r
+}
+
+func Regexp(field, value string) *regexpQuery {
+	return &regexpQuery{
+		Field: field,
+		Value: value,
+	}
+}
+
+type fuzzyQuery struct {
+	Field          string   `json:"-"`
+	Value          string   `json:"value"`
+	Fuzziness      string   `json:"fuzziness,omitempty"`
+	MaxExpansions  *int     `json:"max_expansions,omitempty"`
+	PrefixLength   *int     `json:"prefix_length,omitempty"`
+	Transpositions *bool    `json:"transpositions,omitempty"`
+	Rewrite        string   `json:"rewrite,omitempty"`
+	Boost          *float32 `json:"boost,omitempty"`
+}
+
+func (f *fuzzyQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"fuzzy": KeyVal{
+			f.Field: *f,
+		},
+	})
+}
+
+func (f *fuzzyQuery) Validate() error {
+	if err := validateField("fuzzy", f.Field); err != nil {
+		return err
+	}
+	if f.Value == "" {
+		return invalid("fuzzy", "value of %s is empty", f.Field)
+	}
+	if err := validateNonNegative("fuzzy", "prefix_length", f.PrefixLength); err != nil {
+		return err
+	}
+	return validateFuzziness("fuzzy", f.Fuzziness)
+}
+
+// SetFuzziness sets the maximum edit distance, e.g. "1", "2" or FuzzinessAuto.
+func (f *fuzzyQuery) SetFuzziness(fuzziness string) *fuzzyQuery {
+	f.Fuzziness = fuzziness
+	return f
+}
+
+func (f *fuzzyQuery) SetMaxExpansions(maxExpansions int) *fuzzyQuery {
+	f.MaxExpansions = &maxExpansions
+	return f
+}
+
+func (f *fuzzyQuery) SetPrefixLength(prefixLength int) *fuzzyQuery {
+	f.PrefixLength = &prefixLength
+	return f
+}
+
+func (f *fuzzyQuery) SetTranspositions(transpositions bool) *fuzzyQuery {
+	f.Transpositions = &transpositions
+	return f
+}
+
+func (f *fuzzyQuery) SetRewrite(rewrite string) *fuzzyQuery {
+	f.Rewrite = rewrite
+	return f
+}
+
+func (f *fuzzyQuery) SetBoost(boost float32) *fuzzyQuery {
+	f.Boost = &boost
+	return f
+}
+
+func Fuzzy(field, value string) *fuzzyQuery {
+	return &fuzzyQuery{
+		Field: field,
+		Value: value,
+	}
+}
@@ -0,0 +1,126 @@
+package esquery_test
+
+import (
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTermLevelQueries(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   esquery.QueryType
+	}{
+		{
+			name:     "term with number",
+			expected: `{"term": {"price.priceMajor": {"value": 1299}}}`,
+			actual:   esquery.Term("price.priceMajor", 1299),
+		},
+		{
+			name:     "term with boolean",
+			expected: `{"term": {"isDeleted": {"value": false}}}`,
+			actual:   esquery.Term("isDeleted", false),
+		},
+		{
+			name:     "terms",
+			expected: `{"terms": {"additionalProperties.Condition": ["new", "used"], "boost": 2}}`,
+			actual:   esquery.Terms("additionalProperties.Condition", "new", "used").SetBoost(2),
+		},
+		{
+			name:     "terms with strings",
+			expected: `{"terms": {"sku": ["sku-1", "sku-2"]}}`,
+			actual:   esquery.TermsStrings("sku", []string{"sku-1", "sku-2"}...),
+		},
+		{
+			name:     "terms without values",
+			expected: `{"terms": {"sku": []}}`,
+			actual:   esquery.Terms("sku"),
+		},
+		{
+			name: "terms lookup",
+			expected: `{
+				"terms": {
+					"sku": {"index": "wishlists", "id": "user-1", "path": "skus", "routing": "user-1"}
+				}
+			}`,
+			actual: esquery.TermsLookup("sku", "wishlists", "user-1", "skus").SetLookupRouting("user-1"),
+		},
+		{
+			name:     "ids",
+			expected: `{"ids": {"values": ["sku-1", "sku-2"]}}`,
+			actual:   esquery.Ids("sku-1").SetValues("sku-2"),
+		},
+		{
+			name:     "ids without values",
+			expected: `{"ids": {"values": []}}`,
+			actual:   esquery.Ids(),
+		},
+		{
+			name:     "exists",
+			expected: `{"exists": {"field": "price", "boost": 1.5}}`,
+			actual:   esquery.Exists("price").SetBoost(1.5),
+		},
+		{
+			name:     "prefix",
+			expected: `{"prefix": {"sku": {"value": "JP-", "case_insensitive": true}}}`,
+			actual:   esquery.Prefix("sku", "JP-").SetCaseInsensitive(true),
+		},
+		{
+			name:     "wildcard",
+			expected: `{"wildcard": {"link": {"value": "https://*.example.com/*", "rewrite": "constant_score"}}}`,
+			actual:   esquery.Wildcard("link", "https://*.example.com/*").SetRewrite("constant_score"),
+		},
+		{
+			name:     "regexp",
+			expected: `{"regexp": {"sku": {"value": "JP-[0-9]{4}", "flags": "ALL", "max_determinized_states": 10000}}}`,
+			actual:   esquery.Regexp("sku", "JP-[0-9]{4}").SetFlags("ALL").SetMaxDeterminizedStates(10000),
+		},
+		{
+			name: "fuzzy",
+			expected: `{
+				"fuzzy": {
+					"title": {"value": "shrit", "fuzziness": "AUTO", "prefix_length": 1, "transpositions": true}
+				}
+			}`,
+			actual: esquery.Fuzzy("title", "shrit").
+				SetFuzziness(esquery.FuzzinessAuto).
+				SetPrefixLength(1).
+				SetTranspositions(true),
+		},
+		{
+			name: "terms set with field",
+			expected: `{
+				"terms_set": {
+					"tags": {"terms": ["cotton", "blue", "shirt"], "minimum_should_match_field": "required_matches"}
+				}
+			}`,
+			actual: esquery.TermsSet("tags", "cotton", "blue", "shirt").
+				SetMinimumShouldMatchField("required_matches"),
+		},
+		{
+			name: "terms set with script",
+			expected: `{
+				"terms_set": {
+					"tags": {
+						"terms": ["cotton", "blue"],
+						"minimum_should_match_script": {"source": "Math.min(params.num_terms, 2)"}
+					}
+				}
+			}`,
+			actual: esquery.TermsSet("tags", "cotton", "blue").
+				SetMinimumShouldMatchScript(esquery.NewScript("Math.min(params.num_terms, 2)")),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(test.actual)
+			assert.Nil(t, err)
+
+			assert.JSONEq(t, test.expected, string(jsonData))
+		})
+	}
+}
@@ -3,10 +3,11 @@ package esquery
 import "encoding/json"
 
 type termQuery struct {
-	Field           string   `json:"-"`
-	Value           string   `json:"value"`
-	Boost           *float32 `json:"boost,omitempty"`
-	CaseInsensitive *bool    `json:"case_insensitive,omitempty"`
+	Field           string      `json:"-"`
+	Value           interface{} `json:"value"`
+	Boost           *float32    `json:"boost,omitempty"`
+	CaseInsensitive *bool       `json:"case_insensitive,omitempty"`
+	Name            string      `json:"_name,omitempty"`
 }
 
 func (t *termQuery) MarshalJSON() ([]byte, error) {
@@ -17,6 +18,16 @@ func (t *termQuery) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (t *termQuery) Validate() error {
+	if err := validateField("term", t.Field); err != nil {
+		return err
+	}
+	if t.Value == nil {
+		return invalid("term", "value of %s is missing", t.Field)
+	}
+	return nil
+}
+
 func (t *termQuery) SetBoost(boost float32) *termQuery {
 	t.Boost = &boost
 	return t
@@ -27,7 +38,14 @@ func (t *termQuery) SetCaseInsensitive(isCaseSensitive bool) *termQuery {
 	return t
 }
 
-func Term(field string, value string) *termQuery {
+func (t *termQuery) SetName(name string) *termQuery {
+	t.Name = name
+	return t
+}
+
+// Term matches documents whose field holds exactly value, which may be a
+// string, number or boolean.
+func Term(field string, value interface{}) *termQuery {
 	return &termQuery{
 		Field: field,
 		Value: value,
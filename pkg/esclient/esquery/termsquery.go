@@ -0,0 +1,155 @@
+package esquery
+
+import "encoding/json"
+
+type termsQuery struct {
+	Field  string
+	Values []interface{}
+	Lookup *termsLookup
+	Boost  *float32
+}
+
+// termsLookup fetches the terms from a field of an indexed document.
+type termsLookup struct {
+	Index   string `json:"index"`
+	Id      string `json:"id"`
+	Path    string `json:"path"`
+	Routing string `json:"routing,omitempty"`
+}
+
+func (t *termsQuery) MarshalJSON() ([]byte, error) {
+	terms := KeyVal{}
+	if t.Lookup != nil {
+		terms[t.Field] = t.Lookup
+	} else {
+		values := t.Values
+		if values == nil {
+			values = []interface{}{}
+		}
+		terms[t.Field] = values
+	}
+	if t.Boost != nil {
+		terms["boost"] = *t.Boost
+	}
+	return json.Marshal(KeyVal{
+		"terms": terms,
+	})
+}
+
+func (t *termsQuery) Validate() error {
+	if err := validateField("terms", t.Field); err != nil {
+		return err
+	}
+	if t.Lookup != nil && (t.Lookup.Index == "" || t.Lookup.Id == "" || t.Lookup.Path == "") {
+		return invalid("terms", "lookup needs an index, id and path")
+	}
+	for _, value := range t.Values {
+		if value == nil {
+			return invalid("terms", "value of %s is missing", t.Field)
+		}
+	}
+	return nil
+}
+
+func (t *termsQuery) SetValues(values ...interface{}) *termsQuery {
+	t.Values = append(t.Values, values...)
+	return t
+}
+
+func (t *termsQuery) SetBoost(boost float32) *termsQuery {
+	t.Boost = &boost
+	return t
+}
+
+// SetLookupRouting sets the routing of the document the terms are looked up from.
+func (t *termsQuery) SetLookupRouting(routing string) *termsQuery {
+	if t.Lookup != nil {
+		t.Lookup.Routing = routing
+	}
+	return t
+}
+
+// Terms matches documents whose field holds one or more of values.
+func Terms(field string, values ...interface{}) *termsQuery {
+	return &termsQuery{
+		Field:  field,
+		Values: values,
+	}
+}
+
+// TermsLookup matches documents whose field holds one of the values stored
+// at path in the document index/id.
+func TermsLookup(field, index, id, path string) *termsQuery {
+	return &termsQuery{
+		Field: field,
+		Lookup: &termsLookup{
+			Index: index,
+			Id:    id,
+			Path:  path,
+		},
+	}
+}
+
+// TermsStrings is a convenience for Terms with string values.
+func TermsStrings(field string, values ...string) *termsQuery {
+	terms := Terms(field)
+	for _, value := range values {
+		terms.Values = append(terms.Values, value)
+	}
+	return terms
+}
+
+type termsSetQuery struct {
+	Field                    string        `json:"-"`
+	Terms                    []interface{} `json:"terms"`
+	MinimumShouldMatchField  string        `json:"minimum_should_match_field,omitempty"`
+	MinimumShouldMatchScript *Script       `json:"minimum_should_match_script,omitempty"`
+	Boost                    *float32      `json:"boost,omitempty"`
+}
+
+func (t *termsSetQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(KeyVal{
+		"terms_set": KeyVal{
+			t.Field: *t,
+		},
+	})
+}
+
+func (t *termsSetQuery) Validate() error {
+	if err := validateField("terms_set", t.Field); err != nil {
+		return err
+	}
+	if len(t.Terms) == 0 {
+		return invalid("terms_set", "no terms")
+	}
+	if (t.MinimumShouldMatchField == "") == (t.MinimumShouldMatchScript == nil) {
+		return invalid("terms_set", "needs either minimum_should_match_field or minimum_should_match_script")
+	}
+	return Validate(t.MinimumShouldMatchScript)
+}
+
+// SetMinimumShouldMatchField reads the number of terms that must match from a
+// numeric field of each document.
+func (t *termsSetQuery) SetMinimumShouldMatchField(field string) *termsSetQuery {
+	t.MinimumShouldMatchField = field
+	return t
+}
+
+// SetMinimumShouldMatchScript computes the number of terms that must match,
+// e.g. with "Math.min(params.num_terms, doc['required_matches'].value)".
+func (t *termsSetQuery) SetMinimumShouldMatchScript(script *Script) *termsSetQuery {
+	t.MinimumShouldMatchScript = script
+	return t
+}
+
+func (t *termsSetQuery) SetBoost(boost float32) *termsSetQuery {
+	t.Boost = &boost
+	return t
+}
+
+func TermsSet(field string, terms ...interface{}) *termsSetQuery {
+	return &termsSetQuery{
+		Field: field,
+		Terms: terms,
+	}
+}
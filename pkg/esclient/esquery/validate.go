@@ -0,0 +1,168 @@
+package esquery
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalid is wrapped by the errors returned by Validate.
+var ErrInvalid = errors.New("esquery: invalid")
+
+// Validator is implemented by the builders that can check their structure
+// before the request is sent.
+type Validator interface {
+	Validate() error
+}
+
+func invalid(kind, format string, args ...interface{}) error {
+	return fmt.Errorf("%w %s: %s", ErrInvalid, kind, fmt.Sprintf(format, args...))
+}
+
+// Validate checks v when it is a Validator. Values built outside this package
+// are assumed to be valid.
+func Validate(v interface{}) error {
+	if isNil(v) {
+		return nil
+	}
+	if validator, ok := v.(Validator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return value.IsNil()
+	}
+	return false
+}
+
+// validateQuery checks a query that kind requires under the given name.
+func validateQuery(kind, name string, query QueryType) error {
+	if isNil(query) {
+		return invalid(kind, "%s is missing", name)
+	}
+	return Validate(query)
+}
+
+func validateQueries(kind, name string, queries []QueryType) error {
+	for i, query := range queries {
+		if err := validateQuery(kind, fmt.Sprintf("%s[%d]", name, i), query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateAggregations(aggs map[string]Aggregation) error {
+	for _, name := range slices.Sorted(maps.Keys(aggs)) {
+		if name == "" {
+			return invalid("aggs", "aggregation name is empty")
+		}
+		if isNil(aggs[name]) {
+			return invalid("aggs", "aggregation %q is missing", name)
+		}
+		if err := Validate(aggs[name]); err != nil {
+			return fmt.Errorf("aggregation %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func validateField(kind, field string) error {
+	if strings.TrimSpace(field) == "" {
+		return invalid(kind, "field is empty")
+	}
+	return nil
+}
+
+func validateOperator(kind string, operator Operator) error {
+	switch strings.ToLower(string(operator)) {
+	case "", string(OperatorAnd), string(OperatorOr):
+		return nil
+	}
+	return invalid(kind, "unknown operator %q", operator)
+}
+
+func validateZeroTermsQuery(kind string, zeroTermsQuery ZeroTermsQuery) error {
+	switch zeroTermsQuery {
+	case "", ZeroTermsNone, ZeroTermsAll:
+		return nil
+	}
+	return invalid(kind, "unknown zero_terms_query %q", zeroTermsQuery)
+}
+
+// validateFuzziness accepts an edit distance, AUTO and AUTO:low,high.
+func validateFuzziness(kind, fuzziness string) error {
+	if fuzziness == "" || fuzziness == FuzzinessAuto {
+		return nil
+	}
+	if bounds, found := strings.CutPrefix(fuzziness, FuzzinessAuto+":"); found {
+		low, high, found := strings.Cut(bounds, ",")
+		lowValue, lowErr := strconv.Atoi(low)
+		highValue, highErr := strconv.Atoi(high)
+		if found && lowErr == nil && highErr == nil && 0 <= lowValue && lowValue <= highValue {
+			return nil
+		}
+	} else if distance, err := strconv.ParseFloat(fuzziness, 64); err == nil && distance >= 0 {
+		return nil
+	}
+	return invalid(kind, "invalid fuzziness %q", fuzziness)
+}
+
+func validateNonNegative(kind, name string, value *int) error {
+	if value != nil && *value < 0 {
+		return invalid(kind, "%s must not be negative", name)
+	}
+	return nil
+}
+
+func validateFraction(kind, name string, value *float64) error {
+	if value != nil && (*value < 0 || *value > 1) {
+		return invalid(kind, "%s must be between 0 and 1", name)
+	}
+	return nil
+}
+
+func validateNestedScoreMode(kind string, scoreMode NestedScoreMode) error {
+	switch scoreMode {
+	case "", NestedScoreAvg, NestedScoreMax, NestedScoreMin, NestedScoreSum, NestedScoreNone:
+		return nil
+	}
+	return invalid(kind, "unknown score_mode %q", scoreMode)
+}
+
+func validateSuggestMode(kind string, mode SuggestMode) error {
+	switch mode {
+	case "", SuggestModeMissing, SuggestModePopular, SuggestModeAlways:
+		return nil
+	}
+	return invalid(kind, "unknown suggest_mode %q", mode)
+}
+
+func validateSort(kind string, sorts []*sort) error {
+	for _, s := range sorts {
+		if s == nil {
+			return invalid(kind, "sort is missing")
+		}
+		if err := validateField(kind+" sort", s.Field); err != nil {
+			return err
+		}
+		switch strings.ToLower(string(s.Order)) {
+		case "", string(OrderAsc), string(OrderDesc):
+		default:
+			return invalid(kind, "unknown sort order %q on %s", s.Order, s.Field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package esquery_test
+
+import (
+	"errors"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value esquery.Validator
+	}{
+		{"match", esquery.Match("title", "シャツ").SetFuzziness("AUTO:3,6").SetOperator(esquery.OperatorAnd)},
+		{"multi match", esquery.MultiMatch("シャツ", "title^2", "description").SetType(esquery.MultiMatchBestFields).SetTieBreaker(0.3)},
+		{"range", esquery.Range("price.priceMajor").SetGte(1000).SetLt(2000)},
+		{"bool", esquery.Bool().
+			SetMust(esquery.Match("title", "シャツ")).
+			SetFilter(esquery.Term("brand", "kakashi"), esquery.Exists("image")).
+			SetShould(esquery.Ids("SKU-1", "SKU-2")).
+			SetMinimumShouldMatch(1)},
+		{"nested", esquery.Nested("offers", esquery.Range("offers.price").SetLte(100))},
+		{"function score", esquery.FunctionScore(esquery.MatchAll()).
+			AddFunction(esquery.Gauss("updatedAt", "now", "10d").SetDecay(0.5)).
+			AddFunction(esquery.ScriptFunction(esquery.NewScript("doc['rank'].value")))},
+		{"geo bounding box", esquery.GeoBoundingBox("location",
+			esquery.GeoPoint{Lat: 35.7, Lon: 139.6}, esquery.GeoPoint{Lat: 35.6, Lon: 139.8})},
+		{"search", esquery.NewSearchQueryBuilder().
+			SetQuery(esquery.Match("title", "シャツ")).
+			SetSort(esquery.Sort("price.priceMajor", esquery.OrderAsc)).
+			SetSearchAfter(1000).
+			SetAggregation("brands", esquery.TermsAgg("brand").SetSubAggregation("price", esquery.AvgAgg("price.priceMajor"))).
+			SetHighlight(esquery.NewHighlight().SetFields("title")).
+			Build()},
+		{"raw", esquery.RawQuery(`{"more_like_this": {"like": "シャツ"}}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, tt.value.Validate())
+		})
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value esquery.Validator
+		err   string
+	}{
+		{"match without field", esquery.Match("", "シャツ"), "match: field is empty"},
+		{"match with bad fuzziness", esquery.Match("title", "シャツ").SetFuzziness("AUTO:6,3"), `invalid fuzziness "AUTO:6,3"`},
+		{"match with bad operator", esquery.Match("title", "シャツ").SetOperator("xor"), `unknown operator "xor"`},
+		{"multi match fuzziness with phrase", esquery.MultiMatch("シャツ", "title").SetType(esquery.MultiMatchPhrase).SetFuzziness("AUTO"), "fuzziness is not supported by the phrase type"},
+		{"term without value", esquery.Term("sku", nil), "value of sku is missing"},
+		{"range without bounds", esquery.Range("price"), "no bounds on price"},
+		{"range with gt and gte", esquery.Range("price").SetGt(1).SetGte(1), "both gt and gte on price"},
+		{"nested clause", esquery.Bool().SetFilter(esquery.Bool().SetMust(esquery.Range("price"))), "no bounds on price"},
+		{"nil clause", esquery.Bool().SetMust(nil), "must[0] is missing"},
+		{"minimum should match", esquery.Bool().SetMinimumShouldMatch(1), "minimum_should_match 1 is more than the 0 should clauses"},
+		{"terms set without minimum should match", esquery.TermsSet("tags", "a", "b"), "needs either minimum_should_match_field or minimum_should_match_script"},
+		{"nested without query", esquery.Nested("offers", nil), "query is missing"},
+		{"has child min over max", esquery.HasChild("offer", esquery.MatchAll()).SetMinChildren(3).SetMaxChildren(1), "min_children is more than max_children"},
+		{"dis max without queries", esquery.DisMax(), "no queries"},
+		{"boosting negative boost", esquery.Boosting(esquery.MatchAll(), esquery.Exists("sale"), 2), "negative_boost must be between 0 and 1"},
+		{"script without source", esquery.ScriptScore(esquery.MatchAll(), &esquery.Script{}), "needs either a source or an id"},
+		{"decay without scale", esquery.FunctionScore(nil).AddFunction(esquery.Linear("updatedAt", "now", nil)), "scale is missing"},
+		{"geo point out of range", esquery.GeoDistance("location", esquery.GeoPoint{Lat: 139.6, Lon: 35.7}, "5km"), "out of range"},
+		{"geo polygon", esquery.GeoPolygon("location", esquery.GeoPoint{}, esquery.GeoPoint{}), "needs at least 3 points"},
+		{"geo shape without shape", esquery.GeoShapeQuery("area", nil), "needs either a shape or an indexed_shape"},
+		{"simple query string flag", esquery.SimpleQueryString("シャツ").SetFlags("AND", "LIKE"), `unknown flag "LIKE"`},
+		{"raw", esquery.RawQuery(`[]`), "not a JSON object"},
+		{"date histogram interval", esquery.DateHistogramAgg("createdAt"), "needs either calendar_interval or fixed_interval"},
+		{"sub aggregation", esquery.TermsAgg("brand").SetSubAggregation("price", esquery.AvgAgg("")), `aggregation "price": esquery: invalid avg aggregation: field is empty`},
+		{"search after without sort", esquery.NewSearchQueryBuilder().SetSearchAfter(1).Build(), "search_after needs a sort"},
+		{"search aggregation", esquery.NewSearchQueryBuilder().SetAggregation("buckets", esquery.HistogramAgg("price", 0)).Build(), "interval must be positive"},
+		{"highlight without fields", esquery.NewSearchQueryBuilder().SetHighlight(esquery.NewHighlight()).Build(), "highlight: no fields"},
+		{"collapse inner hits without name", esquery.NewSearchQueryBuilder().SetCollapse(esquery.NewCollapse("brand").AddInnerHits(esquery.NewInnerHits())).Build(), "inner hits need a name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.value.Validate()
+			assert.True(t, errors.Is(err, esquery.ErrInvalid), "got %v", err)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestValidateNil(t *testing.T) {
+	assert.NoError(t, esquery.Validate(nil))
+	assert.NoError(t, esquery.Validate((*esquery.Highlight)(nil)))
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github/shaolim/kakashi/pkg/esclient"
 	"github/shaolim/kakashi/pkg/esclient/esquery"
 	"github/shaolim/kakashi/utils/middleware"
@@ -37,12 +38,14 @@ func main() {
 		),
 	}
 
+	ctx := context.Background()
+
 	client := esclient.NewClient("http://localhost:9200", esclient.WithHttpClient(httpClient))
-	if err := ping(client); err != nil {
+	if err := ping(ctx, client); err != nil {
 		return
 	}
 
-	deleteResponse, err := client.DeleteIndeces([]string{"products"}, esclient.DeleteIndecesWithIgnoreUnavailable())
+	deleteResponse, err := client.DeleteIndeces(ctx, []string{"products"}, esclient.DeleteIndecesWithIgnoreUnavailable())
 	if err != nil {
 		logger.Error("error", slog.Any("error", err))
 		return
@@ -50,7 +53,7 @@ func main() {
 
 	logger.Info("index deleted", slog.Any("body", deleteResponse.Result))
 
-	if err := createIndexIfNotExists(client); err != nil {
+	if err := createIndexIfNotExists(ctx, client); err != nil {
 		return
 	}
 
@@ -77,7 +80,7 @@ func main() {
 		Add(esclient.NewBulkIndexRequest().SetId("10").
 			SetDoc(product{Name: "Smart Home Hub", Price: 99, InStock: boolPtr(true)}))
 
-	bulkResponse, err := client.Bulk("products", bulkRequest)
+	bulkResponse, err := client.Bulk(ctx, "products", bulkRequest)
 	if err != nil {
 		logger.Error("bulk", slog.Any("error", err))
 		return
@@ -91,7 +94,7 @@ func main() {
 		SetQuery(esquery.MatchAll()).
 		Build()
 
-	searchResponse, err := client.Search("products", *searchRequest)
+	searchResponse, err := client.Search(ctx, "products", *searchRequest)
 	if err != nil {
 		logger.Error("search", slog.Any("error", err))
 		return
@@ -99,7 +102,7 @@ func main() {
 
 	logger.Info("search", slog.Any("result", searchResponse.Result))
 
-	countRes, err := client.Count("products", esquery.MatchAll())
+	countRes, err := client.Count(ctx, "products", esquery.MatchAll())
 	if err != nil {
 		logger.Error("search", slog.Any("error", err))
 		return
@@ -108,8 +111,8 @@ func main() {
 	logger.Info("count", slog.Any("result", countRes.Result))
 }
 
-func ping(client esclient.Client) error {
-	res, err := client.Ping(esclient.PingWithHttpHeadOnly())
+func ping(ctx context.Context, client esclient.Client) error {
+	res, err := client.Ping(ctx, esclient.PingWithHttpHeadOnly())
 	if err != nil {
 		logger.Error("ping", slog.Any("error", err))
 		return err
@@ -122,7 +125,7 @@ func ping(client esclient.Client) error {
 	return nil
 }
 
-func createIndexIfNotExists(client esclient.Client) error {
+func createIndexIfNotExists(ctx context.Context, client esclient.Client) error {
 	indexName := "products"
 	payload := `{
 		"settings":{
@@ -144,24 +147,22 @@ func createIndexIfNotExists(client esclient.Client) error {
 		}
 	}`
 
-	indexResponse, err := client.GetIndeces([]string{indexName}, esclient.GetIndecesWithHttpHeadOnly())
-	if err != nil {
-		if indexResponse.StatusCode != 404 {
-			logger.Error("error", slog.Any("error", err))
-			return err
-		}
+	indexResponse, err := client.GetIndeces(ctx, []string{indexName}, esclient.GetIndecesWithHttpHeadOnly())
+	if err == nil {
+		logger.Info("index exists", slog.Any("body", indexResponse.Result))
+		return nil
+	}
+	if !esclient.IsNotFound(err) {
 		logger.Error("error", slog.Any("error", err))
+		return err
 	}
-	logger.Info("index exists", slog.Any("body", indexResponse.Result))
 
-	if indexResponse.StatusCode == 404 {
-		res, err := client.CreateIndex(indexName, strings.NewReader(payload))
-		if err != nil {
-			logger.Error("error", slog.Any("error", err))
-			return err
-		}
-		logger.Info("index created", slog.Any("result", res))
+	res, err := client.CreateIndex(ctx, indexName, strings.NewReader(payload))
+	if err != nil {
+		logger.Error("error", slog.Any("error", err))
+		return err
 	}
+	logger.Info("index created", slog.Any("result", res.Result))
 
 	return nil
 }
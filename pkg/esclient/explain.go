@@ -0,0 +1,173 @@
+package esclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type ValidateQuery interface {
+	ValidateQuery(ctx context.Context, index string, query esquery.QueryType, options ...validateQueryOptions) (*Response[ValidateQueryResult], error)
+}
+
+type Explain interface {
+	Explain(ctx context.Context, index, id string, query esquery.QueryType, options ...explainOptions) (*Response[ExplainResult], error)
+}
+
+type validateQueryOptions func(url.Values)
+
+// ValidateQueryWithExplain returns the reason when the query is invalid.
+func ValidateQueryWithExplain() validateQueryOptions {
+	return func(q url.Values) {
+		q.Set("explain", "true")
+	}
+}
+
+// ValidateQueryWithRewrite returns the Lucene query the query is rewritten
+// into, e.g. the terms produced by the analyzer of the field.
+func ValidateQueryWithRewrite() validateQueryOptions {
+	return func(q url.Values) {
+		q.Set("rewrite", "true")
+	}
+}
+
+// ValidateQueryWithAllShards rewrites the query on every shard instead of a
+// random one, as the rewrite of some queries depends on the shard data.
+func ValidateQueryWithAllShards() validateQueryOptions {
+	return func(q url.Values) {
+		q.Set("all_shards", "true")
+	}
+}
+
+type explainOptions func(url.Values)
+
+// ExplainWithRouting explains the document stored with a custom routing.
+func ExplainWithRouting(routing string) explainOptions {
+	return func(q url.Values) {
+		q.Set("routing", routing)
+	}
+}
+
+// ExplainWithPreference selects the shard copies, e.g. "_local".
+func ExplainWithPreference(preference string) explainOptions {
+	return func(q url.Values) {
+		q.Set("preference", preference)
+	}
+}
+
+// ExplainWithSource returns the _source of the document with the explanation.
+func ExplainWithSource() explainOptions {
+	return func(q url.Values) {
+		q.Set("_source", "true")
+	}
+}
+
+func ExplainWithSourceIncludes(fields ...string) explainOptions {
+	return func(q url.Values) {
+		q.Set("_source_includes", strings.Join(fields, ","))
+	}
+}
+
+func ExplainWithSourceExcludes(fields ...string) explainOptions {
+	return func(q url.Values) {
+		q.Set("_source_excludes", strings.Join(fields, ","))
+	}
+}
+
+// ExplainWithStoredFields returns the stored fields of the document with the
+// explanation.
+func ExplainWithStoredFields(fields ...string) explainOptions {
+	return func(q url.Values) {
+		q.Set("stored_fields", strings.Join(fields, ","))
+	}
+}
+
+// ValidateQuery asks elasticsearch whether query is valid for index, or for
+// every index when it is empty, without running it. The query is first
+// checked with esquery.Validate.
+func (c *client) ValidateQuery(ctx context.Context, index string, query esquery.QueryType, options ...validateQueryOptions) (*Response[ValidateQueryResult], error) {
+	if err := esquery.Validate(query); err != nil {
+		return nil, err
+	}
+
+	r, err := json.Marshal(esquery.KeyVal{
+		"query": query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	for _, option := range options {
+		option(q)
+	}
+	uri := "/_validate/query"
+	if index != "" {
+		uri = "/" + index + "/_validate/query"
+	}
+	if len(q) > 0 {
+		uri += "?" + q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", uri, bytes.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[ValidateQueryResult](c, req)
+}
+
+// Explain computes the score of the document id for query. Matched is
+// false when the document does not match, and the explanation says which
+// clause failed.
+func (c *client) Explain(ctx context.Context, index, id string, query esquery.QueryType, options ...explainOptions) (*Response[ExplainResult], error) {
+	if err := esquery.Validate(query); err != nil {
+		return nil, err
+	}
+
+	r, err := json.Marshal(esquery.KeyVal{
+		"query": query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	for _, option := range options {
+		option(q)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", documentUrl(index, "_explain", id, q), bytes.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[ExplainResult](c, req)
+}
+
+type ValidateQueryResult struct {
+	Valid        bool                       `json:"valid"`
+	Shards       *ShardsInfo                `json:"_shards,omitempty"`
+	Explanations []ValidateQueryExplanation `json:"explanations,omitempty"`
+	Error        string                     `json:"error,omitempty"` // without explain, the reason of an invalid query
+}
+
+type ValidateQueryExplanation struct {
+	Index       string `json:"index"`
+	Shard       *int   `json:"shard,omitempty"` // only with all shards
+	Valid       bool   `json:"valid"`
+	Explanation string `json:"explanation,omitempty"` // the rewritten query
+	Error       string `json:"error,omitempty"`
+}
+
+type ExplainResult struct {
+	Index       string             `json:"_index"`
+	Id          string             `json:"_id"`
+	Matched     bool               `json:"matched"`
+	Explanation *SearchExplanation `json:"explanation,omitempty"`
+	Get         *GetResult         `json:"get,omitempty"` // the _source or stored fields when requested
+}
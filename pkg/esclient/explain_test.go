@@ -0,0 +1,92 @@
+package esclient_test
+
+import (
+	"context"
+	"errors"
+	"github/shaolim/kakashi/pkg/esclient"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateQuery(t *testing.T) {
+	server, recorded := newRecordingServer(t, 200, `{
+		"_shards": {"total": 1, "successful": 1, "failed": 0},
+		"valid": true,
+		"explanations": [{
+			"index": "item_index_ja",
+			"valid": true,
+			"explanation": "+title:シャツ #sku:SKU-1"
+		}]
+	}`)
+
+	client := esclient.NewClient(server.URL)
+	query := esquery.Bool().
+		SetMust(esquery.Match("title", "シャツ")).
+		SetFilter(esquery.Term("sku", "SKU-1"))
+	res, err := client.ValidateQuery(context.Background(), "item_index_ja", query,
+		esclient.ValidateQueryWithExplain(),
+		esclient.ValidateQueryWithRewrite(),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", recorded.method)
+	assert.Equal(t, "/item_index_ja/_validate/query?explain=true&rewrite=true", recorded.uri)
+	assert.JSONEq(t, `{"query": {"bool": {
+		"must": [{"match": {"title": {"query": "シャツ"}}}],
+		"filter": [{"term": {"sku": {"value": "SKU-1"}}}]
+	}}}`, recorded.body)
+	assert.True(t, res.Result.Valid)
+	assert.Equal(t, "+title:シャツ #sku:SKU-1", res.Result.Explanations[0].Explanation)
+}
+
+func TestValidateQueryWithoutIndex(t *testing.T) {
+	server, recorded := newRecordingServer(t, 200, `{"valid": true}`)
+
+	client := esclient.NewClient(server.URL)
+	res, err := client.ValidateQuery(context.Background(), "", esquery.MatchAll(),
+		esclient.ValidateQueryWithAllShards(),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "/_validate/query?all_shards=true", recorded.uri)
+	assert.True(t, res.Result.Valid)
+}
+
+func TestValidateQueryInvalid(t *testing.T) {
+	server, recorded := newRecordingServer(t, 200, `{}`)
+
+	client := esclient.NewClient(server.URL)
+	_, err := client.ValidateQuery(context.Background(), "item_index_ja", esquery.Range("price"))
+	assert.True(t, errors.Is(err, esquery.ErrInvalid))
+	assert.Empty(t, recorded.method)
+}
+
+func TestExplain(t *testing.T) {
+	server, recorded := newRecordingServer(t, 200, `{
+		"_index": "item_index_ja",
+		"_id": "SKU/1",
+		"matched": false,
+		"explanation": {
+			"value": 0,
+			"description": "no matching term",
+			"details": []
+		},
+		"get": {"found": true, "_source": {"sku": "SKU/1"}}
+	}`)
+
+	client := esclient.NewClient(server.URL)
+	res, err := client.Explain(context.Background(), "item_index_ja", "SKU/1",
+		esquery.Term("sku", "SKU/1"),
+		esclient.ExplainWithRouting("shop1"),
+		esclient.ExplainWithPreference("_local"),
+		esclient.ExplainWithSourceIncludes("sku", "title"),
+		esclient.ExplainWithStoredFields("price"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", recorded.method)
+	assert.Equal(t, "/item_index_ja/_explain/SKU%2F1?_source_includes=sku%2Ctitle&preference=_local&routing=shop1&stored_fields=price", recorded.uri)
+	assert.JSONEq(t, `{"query": {"term": {"sku": {"value": "SKU/1"}}}}`, recorded.body)
+	assert.False(t, res.Result.Matched)
+	assert.Equal(t, "no matching term", res.Result.Explanation.Description)
+	assert.JSONEq(t, `{"sku": "SKU/1"}`, string(res.Result.Get.Source))
+}
@@ -1,6 +1,7 @@
 package esclient
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/url"
@@ -8,9 +9,9 @@ import (
 )
 
 type Index interface {
-	CreateIndex(index string, body io.Reader) (*Response[IndexCreationResult], error)
-	GetIndeces(index []string, options ...getIndecesOptions) (*Response[map[string]*IndexGetResult], error)
-	DeleteIndeces(index []string, options ...deleteIndecesOptions) (*Response[IndexDeletionResult], error)
+	CreateIndex(ctx context.Context, index string, body io.Reader) (*Response[IndexCreationResult], error)
+	GetIndeces(ctx context.Context, index []string, options ...getIndecesOptions) (*Response[map[string]*IndexGetResult], error)
+	DeleteIndeces(ctx context.Context, index []string, options ...deleteIndecesOptions) (*Response[IndexDeletionResult], error)
 }
 
 type IndexCreationResult struct {
@@ -19,8 +20,8 @@ type IndexCreationResult struct {
 	Index        string `json:"index,omitempty"`
 }
 
-func (c *client) CreateIndex(index string, body io.Reader) (*Response[IndexCreationResult], error) {
-	req, err := http.NewRequest("PUT", c.baseUrl+"/"+index, body)
+func (c *client) CreateIndex(ctx context.Context, index string, body io.Reader) (*Response[IndexCreationResult], error) {
+	req, err := http.NewRequestWithContext(ctx, "PUT", "/"+index, body)
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +35,9 @@ func (c *client) CreateIndex(index string, body io.Reader) (*Response[IndexCreat
 	response := &Response[IndexCreationResult]{
 		StatusCode: res.StatusCode,
 	}
-	response.SetBody(res.Body)
+	if err := response.SetBody(res.Body); err != nil {
+		return response, err
+	}
 
 	return response, nil
 }
@@ -69,13 +72,13 @@ func GetIndecesWithHttpHeadOnly() getIndecesOptions {
 // Response codes `200`, `404`
 // `404` is returned if index does not exist
 // `200` is returned if index exists
-func (c *client) GetIndeces(index []string, options ...getIndecesOptions) (*Response[map[string]*IndexGetResult], error) {
+func (c *client) GetIndeces(ctx context.Context, index []string, options ...getIndecesOptions) (*Response[map[string]*IndexGetResult], error) {
 	params := &getIndecesParams{}
 	for _, option := range options {
 		option(params)
 	}
 
-	uri, err := url.Parse(c.baseUrl + "/" + strings.Join(index, ","))
+	uri, err := url.Parse("/" + strings.Join(index, ","))
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +94,7 @@ func (c *client) GetIndeces(index []string, options ...getIndecesOptions) (*Resp
 		method = "HEAD"
 	}
 
-	req, err := http.NewRequest(method, uri.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, method, uri.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +108,9 @@ func (c *client) GetIndeces(index []string, options ...getIndecesOptions) (*Resp
 	response := &Response[map[string]*IndexGetResult]{
 		StatusCode: res.StatusCode,
 	}
-	response.SetBody(res.Body)
+	if err := response.SetBody(res.Body); err != nil {
+		return response, err
+	}
 
 	return response, nil
 }
@@ -122,13 +127,13 @@ func DeleteIndecesWithIgnoreUnavailable() deleteIndecesOptions {
 	}
 }
 
-func (c *client) DeleteIndeces(index []string, options ...deleteIndecesOptions) (*Response[IndexDeletionResult], error) {
+func (c *client) DeleteIndeces(ctx context.Context, index []string, options ...deleteIndecesOptions) (*Response[IndexDeletionResult], error) {
 	params := &deleteIndecesParams{}
 	for _, option := range options {
 		option(params)
 	}
 
-	uri, err := url.Parse(c.baseUrl + "/" + strings.Join(index, ","))
+	uri, err := url.Parse("/" + strings.Join(index, ","))
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +144,7 @@ func (c *client) DeleteIndeces(index []string, options ...deleteIndecesOptions)
 		uri.RawQuery = q.Encode()
 	}
 
-	req, err := http.NewRequest("DELETE", uri.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", uri.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +158,9 @@ func (c *client) DeleteIndeces(index []string, options ...deleteIndecesOptions)
 	response := &Response[IndexDeletionResult]{
 		StatusCode: res.StatusCode,
 	}
-	response.SetBody(res.Body)
+	if err := response.SetBody(res.Body); err != nil {
+		return response, err
+	}
 
 	return response, nil
 }
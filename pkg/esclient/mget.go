@@ -0,0 +1,215 @@
+package esclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type MultiGet interface {
+	MultiGet(ctx context.Context, index string, request *multiGetRequest, options ...documentOptions) (*Response[MultiGetResult], error)
+}
+
+type multiGetRequest struct {
+	Docs []*multiGetItem `json:"docs"`
+}
+
+func NewMultiGetRequest() *multiGetRequest {
+	return &multiGetRequest{}
+}
+
+// AddIds adds documents of the index given to MultiGet.
+func (m *multiGetRequest) AddIds(ids ...string) *multiGetRequest {
+	for _, id := range ids {
+		m.Docs = append(m.Docs, NewMultiGetItem(id))
+	}
+	return m
+}
+
+// AddDoc adds a document of another index.
+func (m *multiGetRequest) AddDoc(index, id string) *multiGetRequest {
+	m.Docs = append(m.Docs, NewMultiGetItem(id).SetIndex(index))
+	return m
+}
+
+func (m *multiGetRequest) AddItem(items ...*multiGetItem) *multiGetRequest {
+	m.Docs = append(m.Docs, items...)
+	return m
+}
+
+func (m *multiGetRequest) Length() int {
+	return len(m.Docs)
+}
+
+type multiGetItem struct {
+	Index   string                `json:"_index,omitempty"`
+	Id      string                `json:"_id"`
+	Routing string                `json:"routing,omitempty"`
+	Source  *esquery.SourceFilter `json:"_source,omitempty"`
+	Stored  []string              `json:"stored_fields,omitempty"`
+}
+
+func NewMultiGetItem(id string) *multiGetItem {
+	return &multiGetItem{Id: id}
+}
+
+func (m *multiGetItem) SetIndex(index string) *multiGetItem {
+	m.Index = index
+	return m
+}
+
+func (m *multiGetItem) SetRouting(routing string) *multiGetItem {
+	m.Routing = routing
+	return m
+}
+
+func (m *multiGetItem) SetSourceIncludes(fields ...string) *multiGetItem {
+	if m.Source == nil {
+		m.Source = &esquery.SourceFilter{}
+	}
+	m.Source.Includes = append(m.Source.Includes, fields...)
+	return m
+}
+
+func (m *multiGetItem) SetSourceExcludes(fields ...string) *multiGetItem {
+	if m.Source == nil {
+		m.Source = &esquery.SourceFilter{}
+	}
+	m.Source.Excludes = append(m.Source.Excludes, fields...)
+	return m
+}
+
+// DisableSource only returns the metadata of the document.
+func (m *multiGetItem) DisableSource() *multiGetItem {
+	m.Source = esquery.NoSource()
+	return m
+}
+
+func (m *multiGetItem) SetStoredFields(fields ...string) *multiGetItem {
+	m.Stored = append(m.Stored, fields...)
+	return m
+}
+
+type MultiGetResult struct {
+	Docs []*MultiGetDoc `json:"docs"`
+}
+
+type MultiGetDoc struct {
+	GetResult
+	Error *ErrorDetails `json:"error,omitempty"`
+}
+
+// MultiGet fetches several documents by id. The index may be empty when every
+// document names its own index. Only the routing and source options apply.
+func (c *client) MultiGet(ctx context.Context, index string, request *multiGetRequest, options ...documentOptions) (*Response[MultiGetResult], error) {
+	params := newDocumentParams(options)
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := "/_mget"
+	if index != "" {
+		uri = "/" + index + "/_mget"
+	}
+
+	q := url.Values{}
+	if params.routing != "" {
+		q.Set("routing", params.routing)
+	}
+	if len(params.sourceIncludes) > 0 {
+		q.Set("_source_includes", strings.Join(params.sourceIncludes, ","))
+	}
+	if len(params.sourceExcludes) > 0 {
+		q.Set("_source_excludes", strings.Join(params.sourceExcludes, ","))
+	}
+	if len(q) > 0 {
+		uri += "?" + q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", uri, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[MultiGetResult](c, req)
+}
+
+type MGetDoc[T any] struct {
+	Index       string
+	Id          string
+	Found       bool
+	Version     int64
+	SeqNo       int64
+	PrimaryTerm int64
+	Source      *T
+	Error       *ErrorDetails
+}
+
+type MGetResult[T any] struct {
+	Docs []*MGetDoc[T]
+}
+
+// Found returns the documents that exist.
+func (r *MGetResult[T]) Found() []*MGetDoc[T] {
+	var found []*MGetDoc[T]
+	for _, doc := range r.Docs {
+		if doc.Found {
+			found = append(found, doc)
+		}
+	}
+	return found
+}
+
+// Missing returns the ids of the documents that do not exist or failed.
+func (r *MGetResult[T]) Missing() []string {
+	var missing []string
+	for _, doc := range r.Docs {
+		if !doc.Found {
+			missing = append(missing, doc.Id)
+		}
+	}
+	return missing
+}
+
+// MGet runs a MultiGet and decodes the source of every found document into T.
+func MGet[T any](ctx context.Context, c MultiGet, index string, request *multiGetRequest, options ...documentOptions) (*Response[MGetResult[T]], error) {
+	res, err := c.MultiGet(ctx, index, request, options...)
+	if err != nil {
+		if res == nil {
+			return nil, err
+		}
+		return &Response[MGetResult[T]]{StatusCode: res.StatusCode, Error: res.Error}, err
+	}
+
+	result := &MGetResult[T]{}
+	if res.Result != nil {
+		result.Docs = make([]*MGetDoc[T], 0, len(res.Result.Docs))
+		for _, doc := range res.Result.Docs {
+			d := &MGetDoc[T]{
+				Index:       doc.Index,
+				Id:          doc.Id,
+				Found:       doc.Found,
+				Version:     doc.Version,
+				SeqNo:       doc.SeqNo,
+				PrimaryTerm: doc.PrimaryTerm,
+				Error:       doc.Error,
+			}
+			if doc.Found && len(doc.Source) > 0 {
+				var source T
+				if err := json.Unmarshal(doc.Source, &source); err != nil {
+					return nil, err
+				}
+				d.Source = &source
+			}
+			result.Docs = append(result.Docs, d)
+		}
+	}
+
+	return &Response[MGetResult[T]]{StatusCode: res.StatusCode, Result: result}, nil
+}
@@ -0,0 +1,62 @@
+package esclient_test
+
+import (
+	"context"
+	"github/shaolim/kakashi/pkg/esclient"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiGetRequest(t *testing.T) {
+	server, recorded := newRecordingServer(t, 200, `{"docs":[]}`)
+
+	req := esclient.NewMultiGetRequest().
+		AddIds("1", "2").
+		AddDoc("item_index_en", "3").
+		AddItem(esclient.NewMultiGetItem("4").SetRouting("shop1").SetSourceIncludes("sku").SetSourceExcludes("description"),
+			esclient.NewMultiGetItem("5").DisableSource())
+
+	_, err := esclient.NewClient(server.URL).MultiGet(context.Background(), "item_index_ja", req,
+		esclient.DocumentWithSourceIncludes("sku", "title"))
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", recorded.method)
+	assert.Equal(t, "/item_index_ja/_mget?_source_includes=sku%2Ctitle", recorded.uri)
+	assert.JSONEq(t, `{
+		"docs": [
+			{"_id": "1"},
+			{"_id": "2"},
+			{"_index": "item_index_en", "_id": "3"},
+			{"_id": "4", "routing": "shop1", "_source": {"includes": ["sku"], "excludes": ["description"]}},
+			{"_id": "5", "_source": false}
+		]
+	}`, recorded.body)
+}
+
+func TestMGet(t *testing.T) {
+	server, _ := newRecordingServer(t, 200, `{
+		"docs": [
+			{"_index": "item_index_ja", "_id": "1", "_version": 1, "_seq_no": 3, "_primary_term": 1, "found": true,
+				"_source": {"sku": "1", "title": "シャツ"}},
+			{"_index": "item_index_ja", "_id": "2", "found": false},
+			{"_index": "missing_index", "_id": "3",
+				"error": {"type": "index_not_found_exception", "reason": "no such index [missing_index]"}}
+		]
+	}`)
+
+	type item struct {
+		Sku   string `json:"sku"`
+		Title string `json:"title"`
+	}
+
+	res, err := esclient.MGet[item](context.Background(), esclient.NewClient(server.URL), "item_index_ja",
+		esclient.NewMultiGetRequest().AddIds("1", "2").AddDoc("missing_index", "3"))
+	assert.NoError(t, err)
+
+	found := res.Result.Found()
+	assert.Len(t, found, 1)
+	assert.Equal(t, "シャツ", found[0].Source.Title)
+	assert.Equal(t, int64(3), found[0].SeqNo)
+	assert.Equal(t, []string{"2", "3"}, res.Result.Missing())
+	assert.Equal(t, "index_not_found_exception", res.Result.Docs[2].Error.Type)
+}
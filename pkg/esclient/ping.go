@@ -1,11 +1,12 @@
 package esclient
 
 import (
+	"context"
 	"net/http"
 )
 
 type Ping interface {
-	Ping(options ...pingParamsOptions) (*Response[PingResult], error)
+	Ping(ctx context.Context, options ...pingParamsOptions) (*Response[PingResult], error)
 }
 
 type PingResult struct {
@@ -38,7 +39,7 @@ func PingWithHttpHeadOnly() func(*pingParams) {
 	}
 }
 
-func (c *client) Ping(options ...pingParamsOptions) (*Response[PingResult], error) {
+func (c *client) Ping(ctx context.Context, options ...pingParamsOptions) (*Response[PingResult], error) {
 	params := &pingParams{}
 	for _, option := range options {
 		option(params)
@@ -49,7 +50,7 @@ func (c *client) Ping(options ...pingParamsOptions) (*Response[PingResult], erro
 		method = "HEAD"
 	}
 
-	req, err := http.NewRequest(method, c.baseUrl, nil)
+	req, err := http.NewRequestWithContext(ctx, method, "/", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +64,9 @@ func (c *client) Ping(options ...pingParamsOptions) (*Response[PingResult], erro
 	response := &Response[PingResult]{
 		StatusCode: res.StatusCode,
 	}
-	response.SetBody(res.Body)
+	if err := response.SetBody(res.Body); err != nil {
+		return response, err
+	}
 
 	return response, nil
 }
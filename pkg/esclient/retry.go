@@ -0,0 +1,152 @@
+package esclient
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 5 * time.Second
+	defaultRetryMaxElapsed     = 30 * time.Second
+)
+
+type retryPolicy struct {
+	maxAttempts    int
+	maxElapsed     time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+type RetryOption func(*retryPolicy)
+
+// RetryWithBackoff sets the initial and the maximum delay between attempts.
+// The delay doubles on every attempt and a random jitter is applied to it.
+func RetryWithBackoff(initial, max time.Duration) RetryOption {
+	return func(p *retryPolicy) {
+		p.initialBackoff = initial
+		p.maxBackoff = max
+	}
+}
+
+// RetryWithMaxElapsed sets the total time budget for all attempts of a single request.
+func RetryWithMaxElapsed(maxElapsed time.Duration) RetryOption {
+	return func(p *retryPolicy) {
+		p.maxElapsed = maxElapsed
+	}
+}
+
+// WithRetry retries requests failing with a connection error or with one of
+// the 429, 502, 503 and 504 status codes, up to maxAttempts attempts in total.
+func WithRetry(maxAttempts int, options ...RetryOption) ClientOption {
+	return func(c *client) {
+		p := &retryPolicy{
+			maxAttempts:    maxAttempts,
+			maxElapsed:     defaultRetryMaxElapsed,
+			initialBackoff: defaultRetryInitialBackoff,
+			maxBackoff:     defaultRetryMaxBackoff,
+		}
+		for _, option := range options {
+			option(p)
+		}
+		c.retry = p
+	}
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before the given attempt (starting at 1) using full jitter.
+func (p *retryPolicy) backoff(attempt int) time.Duration {
+	d := p.initialBackoff
+	for i := 1; i < attempt && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(d) + 1))
+}
+
+func (p *retryPolicy) do(send func(*http.Request) (*http.Response, error), req *http.Request) (*http.Response, error) {
+	if err := rewindableBody(req); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		res, err := send(req)
+		if err == nil && !isRetryableStatus(res.StatusCode) {
+			return res, nil
+		}
+		if err != nil && (req.Context().Err() != nil || isBodyError(err)) {
+			return nil, err
+		}
+		if attempt >= p.maxAttempts {
+			return res, err
+		}
+
+		wait := p.backoff(attempt)
+		if p.maxElapsed > 0 && time.Since(start)+wait > p.maxElapsed {
+			return res, err
+		}
+
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// rewindableBody makes sure the request body can be sent more than once.
+// Bodies created from bytes or strings readers are already rewindable,
+// anything else is buffered in memory.
+func rewindableBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+	req.ContentLength = int64(len(data))
+
+	return nil
+}
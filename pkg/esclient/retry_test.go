@@ -0,0 +1,66 @@
+package esclient_test
+
+import (
+	"context"
+	"github/shaolim/kakashi/pkg/esclient"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientRetry(t *testing.T) {
+	tests := []struct {
+		name             string
+		maxAttempts      int
+		failures         int32
+		expectedAttempts int32
+		expectedStatus   int
+		expectError      bool
+	}{
+		{name: "succeeds after retryable statuses", maxAttempts: 3, failures: 2, expectedAttempts: 3, expectedStatus: 200},
+		{name: "gives up after max attempts", maxAttempts: 2, failures: 5, expectedAttempts: 2, expectedStatus: 503, expectError: true},
+		{name: "no retry without failures", maxAttempts: 3, failures: 0, expectedAttempts: 1, expectedStatus: 200},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				if attempts.Add(1) <= test.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte(`{"took":1,"errors":false,"items":[]}`))
+			}))
+			defer server.Close()
+
+			client := esclient.NewClient(server.URL,
+				esclient.WithRetry(test.maxAttempts, esclient.RetryWithBackoff(time.Millisecond, 5*time.Millisecond)))
+
+			req := &esclient.BulkRequests{}
+			req.Add(esclient.NewBulkIndexRequest().SetId("1").SetDoc(map[string]string{"name": "Laptop"}))
+			expectedBody, err := req.String()
+			assert.NoError(t, err)
+
+			res, err := client.Bulk(context.Background(), "products", req)
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+			assert.Equal(t, test.expectedAttempts, attempts.Load())
+			for _, body := range bodies {
+				assert.Equal(t, expectedBody, body)
+			}
+		})
+	}
+}
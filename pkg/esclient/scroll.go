@@ -0,0 +1,267 @@
+package esclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultKeepAlive = "1m"
+	defaultPageSize  = 1000
+	cleanupTimeout   = 5 * time.Second
+)
+
+type Scroll interface {
+	OpenPointInTime(ctx context.Context, index string, keepAlive string) (*Response[PointInTimeResult], error)
+	ClosePointInTime(ctx context.Context, id string) (*Response[ClearResult], error)
+	Scroll(ctx context.Context, index string, query esquery.SearchQuery, keepAlive string) (*Response[SearchResult], error)
+	ScrollNext(ctx context.Context, scrollId string, keepAlive string) (*Response[SearchResult], error)
+	ClearScroll(ctx context.Context, scrollIds ...string) (*Response[ClearResult], error)
+	SearchAll(ctx context.Context, index string, query esquery.SearchQuery, options ...SearchAllOption) iter.Seq2[*SearchHit, error]
+}
+
+type PointInTimeResult struct {
+	Id string `json:"id"`
+}
+
+type ClearResult struct {
+	Succeeded bool `json:"succeeded"`
+	NumFreed  int  `json:"num_freed"`
+}
+
+func (c *client) OpenPointInTime(ctx context.Context, index string, keepAlive string) (*Response[PointInTimeResult], error) {
+	q := url.Values{}
+	q.Set("keep_alive", keepAlive)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "/"+index+"/_pit?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[PointInTimeResult](c, req)
+}
+
+func (c *client) ClosePointInTime(ctx context.Context, id string) (*Response[ClearResult], error) {
+	body, err := json.Marshal(esquery.KeyVal{"id": id})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", "/_pit", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[ClearResult](c, req)
+}
+
+// Scroll runs the first search of a scroll, the next pages are fetched with ScrollNext.
+func (c *client) Scroll(ctx context.Context, index string, query esquery.SearchQuery, keepAlive string) (*Response[SearchResult], error) {
+	body, err := query.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	q := query.UrlParams()
+	q.Set("scroll", keepAlive)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "/"+index+"/_search?"+q.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[SearchResult](c, req)
+}
+
+func (c *client) ScrollNext(ctx context.Context, scrollId string, keepAlive string) (*Response[SearchResult], error) {
+	body, err := json.Marshal(esquery.KeyVal{
+		"scroll":    keepAlive,
+		"scroll_id": scrollId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "/_search/scroll", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[SearchResult](c, req)
+}
+
+func (c *client) ClearScroll(ctx context.Context, scrollIds ...string) (*Response[ClearResult], error) {
+	body, err := json.Marshal(esquery.KeyVal{"scroll_id": scrollIds})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", "/_search/scroll", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return execute[ClearResult](c, req)
+}
+
+type SearchAllOption func(*searchAllParams)
+
+type searchAllParams struct {
+	keepAlive string
+	scroll    bool
+}
+
+// SearchAllWithKeepAlive sets how long the point in time or the scroll is
+// kept between two pages, e.g. "5m".
+func SearchAllWithKeepAlive(keepAlive string) SearchAllOption {
+	return func(params *searchAllParams) {
+		params.keepAlive = keepAlive
+	}
+}
+
+// SearchAllWithScroll uses the scroll API instead of a point in time.
+func SearchAllWithScroll() SearchAllOption {
+	return func(params *searchAllParams) {
+		params.scroll = true
+	}
+}
+
+// SearchAll iterates over every hit matching the query, one page of
+// query.Size hits (1000 by default) at a time. It opens a point in time and
+// pages with search_after, falling back to the scroll API on clusters
+// without point in time support. The point in time or the scroll is released
+// when the iteration ends, including when the caller stops early.
+func (c *client) SearchAll(ctx context.Context, index string, query esquery.SearchQuery, options ...SearchAllOption) iter.Seq2[*SearchHit, error] {
+	params := &searchAllParams{keepAlive: defaultKeepAlive}
+	for _, option := range options {
+		option(params)
+	}
+
+	query.From = 0
+	if query.Size == 0 {
+		query.Size = defaultPageSize
+	}
+
+	return func(yield func(*SearchHit, error) bool) {
+		query := query
+		if params.scroll {
+			c.scrollAll(ctx, index, query, params.keepAlive, yield)
+			return
+		}
+
+		pit, err := c.OpenPointInTime(ctx, index, params.keepAlive)
+		if err != nil {
+			if isPointInTimeUnsupported(err) {
+				c.scrollAll(ctx, index, query, params.keepAlive, yield)
+				return
+			}
+			yield(nil, err)
+			return
+		}
+
+		pitId := pit.Result.Id
+		defer func() {
+			cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+			defer cancel()
+			c.ClosePointInTime(cleanupCtx, pitId)
+		}()
+
+		if len(query.Sort) == 0 {
+			query.Sort = append(query.Sort, esquery.Sort("_shard_doc", esquery.OrderAsc))
+		}
+
+		for {
+			query.Pit = &esquery.PointInTime{Id: pitId, KeepAlive: params.keepAlive}
+			res, err := c.Search(ctx, "", query)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if res.Result.PitId != "" {
+				pitId = res.Result.PitId
+			}
+
+			hits := res.Result.hits()
+			for _, hit := range hits {
+				if !yield(hit, nil) {
+					return
+				}
+			}
+			if len(hits) < int(query.Size) {
+				return
+			}
+
+			query.SearchAfter = hits[len(hits)-1].Sort
+		}
+	}
+}
+
+func (c *client) scrollAll(ctx context.Context, index string, query esquery.SearchQuery, keepAlive string, yield func(*SearchHit, error) bool) {
+	if len(query.Sort) == 0 {
+		query.Sort = append(query.Sort, esquery.Sort("_doc", esquery.OrderAsc))
+	}
+
+	res, err := c.Scroll(ctx, index, query, keepAlive)
+	if err != nil {
+		yield(nil, err)
+		return
+	}
+
+	scrollId := res.Result.ScrollId
+	defer func() {
+		if scrollId == "" {
+			return
+		}
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+		defer cancel()
+		c.ClearScroll(cleanupCtx, scrollId)
+	}()
+
+	for {
+		hits := res.Result.hits()
+		if len(hits) == 0 {
+			return
+		}
+		for _, hit := range hits {
+			if !yield(hit, nil) {
+				return
+			}
+		}
+
+		res, err = c.ScrollNext(ctx, scrollId, keepAlive)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if res.Result.ScrollId != "" {
+			scrollId = res.Result.ScrollId
+		}
+	}
+}
+
+// isPointInTimeUnsupported reports whether the cluster predates the point in time API (7.10).
+func isPointInTimeUnsupported(err error) bool {
+	e, ok := asError(err)
+	if !ok {
+		return false
+	}
+	if e.Status == http.StatusMethodNotAllowed {
+		return true
+	}
+	return e.Status == http.StatusBadRequest && e.Details != nil &&
+		strings.Contains(e.Details.Reason, "no handler found")
+}
+
+func (r *SearchResult) hits() []*SearchHit {
+	if r == nil || r.Hits == nil {
+		return nil
+	}
+	return r.Hits.Hits
+}
@@ -0,0 +1,122 @@
+package esclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github/shaolim/kakashi/pkg/esclient"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeIndex serves the point in time, search and scroll APIs over n documents.
+type fakeIndex struct {
+	docs         int
+	supportsPit  bool
+	mu           sync.Mutex
+	openPits     int
+	openScrolls  int
+	searchBodies []map[string]interface{}
+}
+
+func (f *fakeIndex) page(from, size int) string {
+	var hits []string
+	for i := from; i < from+size && i < f.docs; i++ {
+		hits = append(hits, fmt.Sprintf(`{"_index":"products","_id":"%d","_source":{"n":%d},"sort":[%d]}`, i, i, i))
+	}
+	return fmt.Sprintf(`{"pit_id":"pit-1","_scroll_id":"scroll-1","hits":{"hits":[%s]}}`, strings.Join(hits, ","))
+}
+
+func (f *fakeIndex) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	switch {
+	case r.URL.Path == "/products/_pit" && r.Method == "POST":
+		if !f.supportsPit {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"no handler found for uri [/products/_pit] and method [POST]","status":400}`))
+			return
+		}
+		f.openPits++
+		w.Write([]byte(`{"id":"pit-1"}`))
+	case r.URL.Path == "/_pit" && r.Method == "DELETE":
+		f.openPits--
+		w.Write([]byte(`{"succeeded":true,"num_freed":1}`))
+	case r.URL.Path == "/_search":
+		f.searchBodies = append(f.searchBodies, body)
+		from := 0
+		if after, ok := body["search_after"].([]interface{}); ok {
+			from = int(after[0].(float64)) + 1
+		}
+		w.Write([]byte(f.page(from, int(body["size"].(float64)))))
+	case r.URL.Path == "/products/_search" && r.URL.Query().Get("scroll") != "":
+		f.openScrolls++
+		f.searchBodies = append(f.searchBodies, body)
+		w.Write([]byte(f.page(0, int(body["size"].(float64)))))
+	case r.URL.Path == "/_search/scroll" && r.Method == "POST":
+		seen := 0
+		for _, b := range f.searchBodies {
+			seen += int(b["size"].(float64))
+		}
+		f.searchBodies = append(f.searchBodies, f.searchBodies[0])
+		w.Write([]byte(f.page(seen, int(f.searchBodies[0]["size"].(float64)))))
+	case r.URL.Path == "/_search/scroll" && r.Method == "DELETE":
+		f.openScrolls--
+		w.Write([]byte(`{"succeeded":true,"num_freed":1}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSearchAll(t *testing.T) {
+	tests := []struct {
+		name        string
+		supportsPit bool
+		options     []esclient.SearchAllOption
+		stopAfter   int
+		expected    int
+	}{
+		{name: "point in time", supportsPit: true, expected: 5},
+		{name: "point in time stopped early", supportsPit: true, stopAfter: 3, expected: 3},
+		{name: "scroll fallback", supportsPit: false, expected: 5},
+		{name: "scroll", supportsPit: true, options: []esclient.SearchAllOption{esclient.SearchAllWithScroll()}, expected: 5},
+		{name: "scroll stopped early", supportsPit: false, stopAfter: 1, expected: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			index := &fakeIndex{docs: 5, supportsPit: test.supportsPit}
+			server := httptest.NewServer(index)
+			defer server.Close()
+
+			client := esclient.NewClient(server.URL)
+			query := esquery.NewSearchQueryBuilder().SetSize(2).SetQuery(esquery.MatchAll()).Build()
+
+			var ids []string
+			for hit, err := range client.SearchAll(context.Background(), "products", *query, test.options...) {
+				assert.NoError(t, err)
+				ids = append(ids, hit.Id)
+				if len(ids) == test.stopAfter {
+					break
+				}
+			}
+
+			assert.Len(t, ids, test.expected)
+			for i, id := range ids {
+				assert.Equal(t, fmt.Sprint(i), id)
+			}
+			assert.Equal(t, 0, index.openPits)
+			assert.Equal(t, 0, index.openScrolls)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package esclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"github/shaolim/kakashi/pkg/esclient/esquery"
 	"net/http"
@@ -9,16 +10,28 @@ import (
 )
 
 type Search interface {
-	Search(index string, query esquery.SearchQuery) (*Response[SearchResult], error)
+	Search(ctx context.Context, index string, query esquery.SearchQuery) (*Response[SearchResult], error)
 }
 
-func (c *client) Search(index string, query esquery.SearchQuery) (*Response[SearchResult], error) {
+func (c *client) Search(ctx context.Context, index string, query esquery.SearchQuery) (*Response[SearchResult], error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
 	r, err := query.MarshalJSON()
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", c.baseUrl+"/"+index+"/_search", bytes.NewReader(r))
+	uri := "/_search"
+	if index != "" {
+		uri = "/" + index + "/_search"
+	}
+	if q := query.UrlParams(); len(q) > 0 {
+		uri += "?" + q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", uri, bytes.NewReader(r))
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +45,9 @@ func (c *client) Search(index string, query esquery.SearchQuery) (*Response[Sear
 	response := &Response[SearchResult]{
 		StatusCode: res.StatusCode,
 	}
-	response.SetBody(res.Body)
+	if err := response.SetBody(res.Body); err != nil {
+		return response, err
+	}
 
 	return response, nil
 }
@@ -47,6 +62,8 @@ type SearchResult struct {
 	Shards          *ShardsInfo   `json:"_shards,omitempty"`          // shard information
 	Status          int           `json:"status,omitempty"`           // used in MultiSearch
 	PitId           string        `json:"pit_id,omitempty"`           // Point In Time ID
+	Aggregations    Aggregations  `json:"aggregations,omitempty"`     // results of the aggregations by name
+	Suggest         SearchSuggest `json:"suggest,omitempty"`          // results of the suggesters by name
 }
 
 func (r *SearchResult) TotalHits() int64 {
@@ -86,9 +103,54 @@ type TotalHits struct {
 }
 
 type SearchHit struct {
-	Score  *float64        `json:"_score,omitempty"`  // computed score
-	Index  string          `json:"_index,omitempty"`  // index name
-	Id     string          `json:"_id,omitempty"`     // external or internal
-	Sort   []interface{}   `json:"sort,omitempty"`    // sort information
-	Source json.RawMessage `json:"_source,omitempty"` // stored document source
+	Score          *float64                 `json:"_score,omitempty"`          // computed score
+	Index          string                   `json:"_index,omitempty"`          // index name
+	Id             string                   `json:"_id,omitempty"`             // external or internal
+	Sort           []interface{}            `json:"sort,omitempty"`            // sort information
+	Source         json.RawMessage          `json:"_source,omitempty"`         // stored document source
+	Nested         *NestedHit               `json:"_nested,omitempty"`         // position of an inner hit in its nested field
+	InnerHits      map[string]*SearchResult `json:"inner_hits,omitempty"`      // matching nested objects, children or collapsed hits by name
+	Highlight      map[string][]string      `json:"highlight,omitempty"`       // highlighted snippets by field
+	Fields         map[string][]interface{} `json:"fields,omitempty"`          // values of the requested fields
+	MatchedQueries []string                 `json:"matched_queries,omitempty"` // names of the named queries that matched
+	Explanation    *SearchExplanation       `json:"_explanation,omitempty"`    // how the score was computed
+}
+
+// SearchExplanation is a node of the tree explaining the score of a hit.
+type SearchExplanation struct {
+	Value       float64             `json:"value"`
+	Description string              `json:"description"`
+	Details     []SearchExplanation `json:"details,omitempty"`
+}
+
+// NestedHit identifies the nested object of an inner hit. Nested is set for
+// multi level nested objects.
+type NestedHit struct {
+	Field  string     `json:"field"`
+	Offset int        `json:"offset"`
+	Nested *NestedHit `json:"_nested,omitempty"`
+}
+
+// SearchSuggest holds the results of the suggesters by name. Each suggester
+// returns one suggestion per term of the text, or one for a phrase or prefix.
+type SearchSuggest map[string][]SearchSuggestion
+
+type SearchSuggestion struct {
+	Text    string                   `json:"text"`
+	Offset  int                      `json:"offset"`
+	Length  int                      `json:"length"`
+	Options []SearchSuggestionOption `json:"options"`
+}
+
+type SearchSuggestionOption struct {
+	Text         string              `json:"text"`
+	Score        float64             `json:"score,omitempty"`         // term and phrase suggesters
+	Freq         int64               `json:"freq,omitempty"`          // term suggester
+	Highlighted  string              `json:"highlighted,omitempty"`   // phrase suggester
+	CollateMatch *bool               `json:"collate_match,omitempty"` // phrase suggester
+	Index        string              `json:"_index,omitempty"`        // completion suggester
+	Id           string              `json:"_id,omitempty"`           // completion suggester
+	DocScore     float64             `json:"_score,omitempty"`        // completion suggester
+	Source       json.RawMessage     `json:"_source,omitempty"`       // completion suggester
+	Contexts     map[string][]string `json:"contexts,omitempty"`      // completion suggester
 }
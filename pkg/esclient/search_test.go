@@ -0,0 +1,124 @@
+package esclient_test
+
+import (
+	"context"
+	"github/shaolim/kakashi/pkg/esclient"
+	"github/shaolim/kakashi/pkg/esclient/esquery"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchUrlParams(t *testing.T) {
+	server, recorded := newRecordingServer(t, 200, `{"hits":{"total":{"value":0,"relation":"eq"},"hits":[]}}`)
+
+	query := esquery.NewSearchQueryBuilder().
+		SetQuery(esquery.MatchAll()).
+		SetPreference("_local").
+		SetRouting("shop1").
+		Build()
+
+	_, err := esclient.NewClient(server.URL).Search(context.Background(), "item_index_ja", *query)
+	assert.NoError(t, err)
+	assert.Equal(t, "/item_index_ja/_search?preference=_local&routing=shop1", recorded.uri)
+	assert.JSONEq(t, `{"query":{"match_all":{}}}`, recorded.body)
+}
+
+func TestSearchInnerHits(t *testing.T) {
+	server, _ := newRecordingServer(t, 200, `{
+		"hits": {
+			"total": {"value": 1, "relation": "eq"},
+			"hits": [{
+				"_index": "item_index_ja",
+				"_id": "sku-1",
+				"_source": {"sku": "sku-1"},
+				"inner_hits": {
+					"offers": {
+						"hits": {
+							"total": {"value": 2, "relation": "eq"},
+							"hits": [{
+								"_index": "item_index_ja",
+								"_id": "sku-1",
+								"_nested": {"field": "offers", "offset": 1},
+								"_source": {"price": {"priceMajor": 980, "currencyCode": "JPY"}}
+							}]
+						}
+					}
+				}
+			}]
+		}
+	}`)
+
+	query := esquery.NewSearchQueryBuilder().
+		SetQuery(esquery.Nested("offers", esquery.MatchAll()).SetInnerHits(esquery.NewInnerHits().SetSize(1))).
+		Build()
+
+	res, err := esclient.NewClient(server.URL).Search(context.Background(), "item_index_ja", *query)
+	assert.NoError(t, err)
+
+	offers := res.Result.Hits.Hits[0].InnerHits["offers"]
+	assert.Equal(t, int64(2), offers.TotalHits())
+	assert.Equal(t, "offers", offers.Hits.Hits[0].Nested.Field)
+	assert.Equal(t, 1, offers.Hits.Hits[0].Nested.Offset)
+	assert.JSONEq(t, `{"price": {"priceMajor": 980, "currencyCode": "JPY"}}`, string(offers.Hits.Hits[0].Source))
+}
+
+func TestSearchHitDetailsAndSuggest(t *testing.T) {
+	server, _ := newRecordingServer(t, 200, `{
+		"hits": {
+			"total": {"value": 1, "relation": "eq"},
+			"hits": [{
+				"_index": "item_index_ja",
+				"_id": "sku-1",
+				"_score": 1.5,
+				"_source": {"sku": "sku-1"},
+				"highlight": {"title": ["青い<em>シャツ</em>"]},
+				"fields": {"price.priceMajor": [1299]},
+				"matched_queries": ["title"],
+				"_explanation": {
+					"value": 1.5,
+					"description": "sum of:",
+					"details": [{"value": 1.5, "description": "weight(title:シャツ)"}]
+				},
+				"inner_hits": {
+					"variants": {"hits": {"total": {"value": 3, "relation": "eq"}, "hits": [{"_id": "sku-2"}]}}
+				}
+			}]
+		},
+		"suggest": {
+			"did_you_mean": [{
+				"text": "blu shirt",
+				"offset": 0,
+				"length": 9,
+				"options": [{"text": "blue shirt", "highlighted": "<em>blue</em> shirt", "score": 0.3}]
+			}],
+			"autocomplete": [{
+				"text": "シャ",
+				"offset": 0,
+				"length": 2,
+				"options": [{"text": "シャツ", "_index": "item_index_ja", "_id": "sku-1", "_score": 1, "_source": {"sku": "sku-1"}}]
+			}]
+		}
+	}`)
+
+	query := esquery.NewSearchQueryBuilder().SetQuery(esquery.Match("title", "シャツ")).Build()
+
+	res, err := esclient.NewClient(server.URL).Search(context.Background(), "item_index_ja", *query)
+	assert.NoError(t, err)
+
+	hit := res.Result.Hits.Hits[0]
+	assert.Equal(t, []string{"青い<em>シャツ</em>"}, hit.Highlight["title"])
+	assert.Equal(t, []interface{}{float64(1299)}, hit.Fields["price.priceMajor"])
+	assert.Equal(t, []string{"title"}, hit.MatchedQueries)
+	assert.Equal(t, 1.5, hit.Explanation.Value)
+	assert.Equal(t, "weight(title:シャツ)", hit.Explanation.Details[0].Description)
+	assert.Equal(t, "sku-2", hit.InnerHits["variants"].Hits.Hits[0].Id)
+
+	didYouMean := res.Result.Suggest["did_you_mean"][0]
+	assert.Equal(t, "blue shirt", didYouMean.Options[0].Text)
+	assert.Equal(t, "<em>blue</em> shirt", didYouMean.Options[0].Highlighted)
+
+	autocomplete := res.Result.Suggest["autocomplete"][0].Options[0]
+	assert.Equal(t, "sku-1", autocomplete.Id)
+	assert.JSONEq(t, `{"sku": "sku-1"}`, string(autocomplete.Source))
+}
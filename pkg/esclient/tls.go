@@ -0,0 +1,209 @@
+package esclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type tlsSettings struct {
+	caCerts     [][]byte
+	certPEM     []byte
+	keyPEM      []byte
+	fingerprint string
+	pinned      []byte
+}
+
+// WithCACert trusts the certificates of a PEM encoded CA bundle.
+func WithCACert(pem []byte) ClientOption {
+	return func(c *client) {
+		c.tls.caCerts = append(c.tls.caCerts, pem)
+	}
+}
+
+// WithClientCertificate presents a PEM encoded client certificate to the cluster.
+func WithClientCertificate(certPEM, keyPEM []byte) ClientOption {
+	return func(c *client) {
+		c.tls.certPEM = certPEM
+		c.tls.keyPEM = keyPEM
+	}
+}
+
+// WithCertificateFingerprint pins the hex encoded SHA-256 fingerprint of the
+// CA or server certificate, as printed by elasticsearch on first start.
+// The server certificate must be valid for the host and chain up to the
+// pinned certificate, or to a WithCACert certificate when the chain also
+// holds the pinned one.
+func WithCertificateFingerprint(fingerprint string) ClientOption {
+	return func(c *client) {
+		c.tls.fingerprint = fingerprint
+	}
+}
+
+func (s *tlsSettings) isSet() bool {
+	return len(s.caCerts) > 0 || s.certPEM != nil || s.fingerprint != ""
+}
+
+func (s *tlsSettings) config() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(s.caCerts) > 0 {
+		pool := x509.NewCertPool()
+		for _, pem := range s.caCerts {
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.New("esclient: no certificate found in CA bundle")
+			}
+		}
+		cfg.RootCAs = pool
+	}
+
+	if s.certPEM != nil {
+		cert, err := tls.X509KeyPair(s.certPEM, s.keyPEM)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if s.fingerprint != "" {
+		want, err := hex.DecodeString(strings.ReplaceAll(s.fingerprint, ":", ""))
+		if err != nil || len(want) != sha256.Size {
+			return nil, errors.New("esclient: invalid certificate fingerprint")
+		}
+		s.pinned = want
+
+		// the chain is verified by verifyPinned with the dialed host, see
+		// dialPinned, or with the SNI name for connections through a proxy
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if cs.ServerName == "" {
+				return errors.New("esclient: cannot verify the pinned certificate without a host name")
+			}
+			return s.verifyPinned(cfg.RootCAs, cs.ServerName)(cs)
+		}
+	}
+
+	return cfg, nil
+}
+
+// verifyPinned verifies the server certificate of host against the roots, or
+// against the pinned certificate when there are none, and checks that the
+// verified chain holds the pinned certificate.
+func (s *tlsSettings) verifyPinned(roots *x509.CertPool, host string) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("esclient: server sent no certificate")
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range cs.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+		if roots == nil {
+			roots = x509.NewCertPool()
+			for _, cert := range cs.PeerCertificates {
+				if s.isPinned(cert) {
+					roots.AddCert(cert)
+				}
+			}
+		}
+
+		chains, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+			DNSName:       host,
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		if err != nil {
+			return errors.Join(errors.New("esclient: server certificate does not match the pinned fingerprint"), err)
+		}
+		for _, chain := range chains {
+			for _, cert := range chain {
+				if s.isPinned(cert) {
+					return nil
+				}
+			}
+		}
+		return errors.New("esclient: server certificate does not match the pinned fingerprint")
+	}
+}
+
+func (s *tlsSettings) isPinned(cert *x509.Certificate) bool {
+	sum := sha256.Sum256(cert.Raw)
+	return bytes.Equal(sum[:], s.pinned)
+}
+
+// dialPinned opens the TLS connections of a transport with a pinned
+// fingerprint, with the dialed host at hand to verify the server certificate.
+func (s *tlsSettings) dialPinned(cfg *tls.Config, dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		connCfg := cfg.Clone()
+		if connCfg.ServerName == "" {
+			connCfg.ServerName = host
+		}
+		connCfg.VerifyConnection = s.verifyPinned(cfg.RootCAs, connCfg.ServerName)
+
+		tlsConn := tls.Client(conn, connCfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
+
+// configureTLS installs the TLS settings on a copy of the http client transport.
+func (c *client) configureTLS() error {
+	if !c.tls.isSet() {
+		return nil
+	}
+
+	cfg, err := c.tls.config()
+	if err != nil {
+		return err
+	}
+
+	httpClient := &http.Client{}
+	if c.httpClient != nil {
+		*httpClient = *c.httpClient
+	}
+
+	rt := httpClient.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		return errors.New("esclient: TLS options require the http client to use an *http.Transport")
+	}
+
+	transport = transport.Clone()
+	transport.TLSClientConfig = cfg
+	if c.tls.pinned != nil {
+		dial := transport.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		transport.DialTLSContext = c.tls.dialPinned(cfg, dial)
+	}
+	httpClient.Transport = transport
+	c.httpClient = httpClient
+
+	return nil
+}